@@ -0,0 +1,196 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AffinityRule Generic rule definition for affinity group, applied to the virtual machines or hosts of the group.
+type AffinityRule struct {
+	// Specifies whether the affinity group uses this rule or not.
+	Enabled string `json:"enabled,omitempty" xml:"enabled,omitempty"`
+	// Specifies whether the affinity group applies strict enforcement of the rule.
+	Enforcing string `json:"enforcing,omitempty" xml:"enforcing,omitempty"`
+	// Specifies whether the affinity group applies positive affinity or negative affinity (anti-affinity).
+	Positive string `json:"positive,omitempty" xml:"positive,omitempty"`
+}
+
+// AffinityGroup An affinity group represents a group of virtual machines with a defined relationship.
+type AffinityGroup struct {
+	OvirtObject
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// Specifies whether the affinity group applies strict enforcement, superseded by VMsRule.
+	Enforcing string `json:"enforcing,omitempty" xml:"enforcing,omitempty"`
+	// Specifies the affinity rule applied between virtual machines and hosts that are members of this affinity group.
+	HostsRule *AffinityRule `json:"hosts_rule,omitempty" xml:"hosts_rule,omitempty"`
+	// Specifies whether the affinity group applies positive affinity or negative affinity, superseded by VMsRule.
+	Positive string `json:"positive,omitempty" xml:"positive,omitempty"`
+	// Priority of the affinity group, groups with a higher priority are preferred by the scheduler.
+	Priority float64 `json:"priority,omitempty,string" xml:"priority,omitempty"`
+	// Specifies the affinity rule applied to virtual machines that are members of this affinity group.
+	VMsRule *AffinityRule `json:"vms_rule,omitempty" xml:"vms_rule,omitempty"`
+	// A reference to the cluster to which the affinity group applies.
+	Cluster *Link `json:"cluster,omitempty" xml:"cluster,omitempty"`
+}
+
+// GetAffinityGroups Retrieve the affinity groups of the cluster
+func (cluster *Cluster) GetAffinityGroups() ([]*AffinityGroup, error) {
+	linkResp, err := cluster.getLinkResponse("affinitygroups", nil)
+	if err != nil {
+		return nil, err
+	}
+	groups := []*AffinityGroup{}
+	for i := range linkResp.AffinityGroup {
+		group := &linkResp.AffinityGroup[i]
+		group.Con = cluster.Con
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// GetAffinityGroup Retrieve an affinity group of the cluster from the server
+func (cluster *Cluster) GetAffinityGroup(id string) (*AffinityGroup, error) {
+	link, err := cluster.GetLink("affinitygroups")
+	if err != nil {
+		return nil, err
+	}
+	link.Path += "/" + id
+	body, err := cluster.Con.Request("GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	group := cluster.NewAffinityGroup()
+	err = cluster.Con.decode(body, group)
+	if err != nil {
+		return nil, err
+	}
+	return group, err
+}
+
+// NewAffinityGroup Create a new affinity group structure belonging to the cluster
+func (cluster *Cluster) NewAffinityGroup() *AffinityGroup {
+	return &AffinityGroup{
+		OvirtObject: OvirtObject{Con: cluster.Con},
+		Cluster:     &Link{Href: cluster.Href, ID: cluster.ID},
+	}
+}
+
+// Update Synchronize the local affinity group with a copy from the server
+func (group *AffinityGroup) Update() error {
+	if group.Href == "" {
+		return fmt.Errorf("affinity group has not been saved to the server")
+	}
+	body, err := group.Con.Request("GET", group.Con.ResolveLink(group.Href), nil)
+	if err != nil {
+		return err
+	}
+	tempGroup := AffinityGroup{OvirtObject: OvirtObject{Con: group.Con}}
+	err = group.Con.decode(body, &tempGroup)
+	if err != nil {
+		return err
+	}
+	*group = tempGroup
+	return nil
+}
+
+// Save Updates the server with the local copy of the affinity group, sending only the fields
+// modified since it was loaded
+func (group *AffinityGroup) Save(options ...SaveOption) error {
+	if group.HostsRule != nil || group.VMsRule != nil {
+		if err := group.Con.Require(AffinityRules); err != nil {
+			return err
+		}
+	}
+	body, err := group.Con.encodeChanges(group)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already saved affinity group, we need to update it
+	if group.Href != "" {
+		body, err = group.Con.put(group, group.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		if group.Cluster == nil || group.Cluster.Href == "" {
+			return errors.New("Affinity group is not associated with a cluster")
+		}
+		link := group.Con.ResolveLink(group.Cluster.Href + "/affinitygroups")
+		body, err = group.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempGroup := AffinityGroup{OvirtObject: OvirtObject{Con: group.Con}}
+	err = group.Con.decode(body, &tempGroup)
+	if err != nil {
+		return err
+	}
+	*group = tempGroup
+	return nil
+}
+
+// Patch Updates the server with the fields of the affinity group named by their JSON
+// path, such as "enforcing" or "vms_rule.enabled", whether they were modified or not
+func (group *AffinityGroup) Patch(fields ...string) error {
+	return group.Con.patch(group, fields)
+}
+
+// GetVMs Retrieve the virtual machines that are members of the affinity group
+func (group *AffinityGroup) GetVMs() ([]*VM, error) {
+	linkResp, err := group.getLinkResponse("vms", nil)
+	if err != nil {
+		return nil, err
+	}
+	vms := []*VM{}
+	for i := range linkResp.VM {
+		vm := &linkResp.VM[i]
+		vm.Con = group.Con
+		vms = append(vms, vm)
+	}
+	return vms, nil
+}
+
+// AddVM Adds the virtual machine to the affinity group
+func (group *AffinityGroup) AddVM(vm *VM) error {
+	_, err := group.AddLinkObject("vms", Link{ID: vm.ID}, nil)
+	return err
+}
+
+// RemoveVM Removes the virtual machine from the affinity group
+func (group *AffinityGroup) RemoveVM(vm *VM) error {
+	return group.RemoveLinkObject("vms", vm.ID, nil)
+}
+
+// GetHosts Retrieve the hosts that are members of the affinity group
+func (group *AffinityGroup) GetHosts() ([]*Host, error) {
+	linkResp, err := group.getLinkResponse("hosts", nil)
+	if err != nil {
+		return nil, err
+	}
+	hosts := []*Host{}
+	for i := range linkResp.Host {
+		host := &linkResp.Host[i]
+		host.Con = group.Con
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// AddHost Adds the host to the affinity group
+func (group *AffinityGroup) AddHost(host *Host) error {
+	_, err := group.AddLinkObject("hosts", Link{ID: host.ID}, nil)
+	return err
+}
+
+// RemoveHost Removes the host from the affinity group
+func (group *AffinityGroup) RemoveHost(host *Host) error {
+	return group.RemoveLinkObject("hosts", host.ID, nil)
+}
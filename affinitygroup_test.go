@@ -0,0 +1,69 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+func TestAffinityGroup(t *testing.T) {
+	t.Parallel()
+	con := newTestConnection(t)
+	allClusters, err := con.GetAllClusters()
+	if err != nil || len(allClusters) == 0 {
+		t.Fatal("Error finding a Cluster for the affinity group", err)
+	}
+	cluster := allClusters[0]
+	newGroup := cluster.NewAffinityGroup()
+	newGroup.Name = "test-affinity-group"
+	newGroup.VMsRule = &ovirtapi.AffinityRule{
+		Enabled:   "true",
+		Enforcing: "true",
+		Positive:  "false",
+	}
+	err = newGroup.Save()
+	if err != nil {
+		t.Fatal("Error creating new affinity group", err)
+	}
+	allHosts, err := con.GetAllHosts()
+	if err != nil || len(allHosts) == 0 {
+		t.Fatal("Error finding a host for the affinity group", err)
+	}
+	err = newGroup.AddHost(allHosts[0])
+	if err != nil {
+		t.Fatal("Error adding host to affinity group", err)
+	}
+	groupHosts, err := newGroup.GetHosts()
+	if err != nil {
+		t.Fatal("Error retrieving affinity group hosts", err)
+	}
+	if len(groupHosts) != 1 || groupHosts[0].ID != allHosts[0].ID {
+		t.Error("Affinity group does not list the added host", groupHosts)
+	}
+	err = newGroup.RemoveHost(allHosts[0])
+	if err != nil {
+		t.Error("Error removing host from affinity group", err)
+	}
+	retrievedGroup, err := cluster.GetAffinityGroup(newGroup.ID)
+	if err != nil {
+		t.Fatal("Error retrieving affinity group", err)
+	}
+	if retrievedGroup.VMsRule == nil || retrievedGroup.VMsRule.Positive != "false" {
+		t.Error("Affinity group did not keep its vms rule", retrievedGroup.VMsRule)
+	}
+	retrievedGroup.Description = "about to delete"
+	err = retrievedGroup.Save()
+	if err != nil {
+		t.Fatal("Error updating affinity group", err)
+	}
+	err = retrievedGroup.Delete()
+	if err != nil {
+		t.Fatal("Error Deleting affinity group", err)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"fmt"
+)
+
+// AffinityLabel The affinity label can influence virtual machine scheduling.
+// It is most frequently used to create a sub-cluster from the available hosts.
+type AffinityLabel struct {
+	OvirtObject
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// This property enables the legacy behavior for labels, virtual machines and hosts with the label are placed as if an affinity group existed.
+	HasImplicitAffinityGroup string `json:"has_implicit_affinity_group,omitempty" xml:"has_implicit_affinity_group,omitempty"`
+	// The read_only property marks a label that can not be modified.
+	ReadOnly string `json:"read_only,omitempty" xml:"read_only,omitempty"`
+}
+
+// GetVMs Retrieve the virtual machines carrying the label
+func (label *AffinityLabel) GetVMs() ([]*VM, error) {
+	linkResp, err := label.getLinkResponse("vms", nil)
+	if err != nil {
+		return nil, err
+	}
+	vms := []*VM{}
+	for i := range linkResp.VM {
+		vm := &linkResp.VM[i]
+		vm.Con = label.Con
+		vms = append(vms, vm)
+	}
+	return vms, nil
+}
+
+// AddVM Attaches the label to the virtual machine
+func (label *AffinityLabel) AddVM(vm *VM) error {
+	_, err := label.AddLinkObject("vms", Link{ID: vm.ID}, nil)
+	return err
+}
+
+// RemoveVM Detaches the label from the virtual machine
+func (label *AffinityLabel) RemoveVM(vm *VM) error {
+	return label.RemoveLinkObject("vms", vm.ID, nil)
+}
+
+// GetHosts Retrieve the hosts carrying the label
+func (label *AffinityLabel) GetHosts() ([]*Host, error) {
+	linkResp, err := label.getLinkResponse("hosts", nil)
+	if err != nil {
+		return nil, err
+	}
+	hosts := []*Host{}
+	for i := range linkResp.Host {
+		host := &linkResp.Host[i]
+		host.Con = label.Con
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// AddHost Attaches the label to the host
+func (label *AffinityLabel) AddHost(host *Host) error {
+	_, err := label.AddLinkObject("hosts", Link{ID: host.ID}, nil)
+	return err
+}
+
+// RemoveHost Detaches the label from the host
+func (label *AffinityLabel) RemoveHost(host *Host) error {
+	return label.RemoveLinkObject("hosts", host.ID, nil)
+}
+
+// GetAffinityLabels Retrieve the affinity labels attached to a virtual machine or host
+func (ovirtObject *OvirtObject) GetAffinityLabels() ([]*AffinityLabel, error) {
+	linkResp, err := ovirtObject.getLinkResponse("affinitylabels", nil)
+	if err != nil {
+		return nil, err
+	}
+	labels := []*AffinityLabel{}
+	for i := range linkResp.AffinityLabel {
+		label := &linkResp.AffinityLabel[i]
+		label.Con = ovirtObject.Con
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// GetAffinityLabel retrieve an affinity label from the server
+func (con *Connection) GetAffinityLabel(id string) (*AffinityLabel, error) {
+	body, err := con.GetLinkBody("affinitylabels", id)
+	if err != nil {
+		return nil, err
+	}
+	label := con.NewAffinityLabel()
+	err = con.decode(body, label)
+	if err != nil {
+		return nil, err
+	}
+	return label, err
+}
+
+// Update Synchronize the local affinity label with a copy from the server
+func (label *AffinityLabel) Update() error {
+	if label.Href == "" {
+		return fmt.Errorf("affinity label has not been saved to the server")
+	}
+	body, err := label.Con.Request("GET", label.Con.ResolveLink(label.Href), nil)
+	if err != nil {
+		return err
+	}
+	tempLabel := AffinityLabel{OvirtObject: OvirtObject{Con: label.Con}}
+	err = label.Con.decode(body, &tempLabel)
+	if err != nil {
+		return err
+	}
+	*label = tempLabel
+	return nil
+}
+
+// GetAllAffinityLabels Retrieve all the affinity labels from the server
+func (con *Connection) GetAllAffinityLabels() ([]*AffinityLabel, error) {
+	body, err := con.GetLinkBody("affinitylabels", "")
+	if err != nil {
+		return nil, err
+	}
+	labels := []*AffinityLabel{}
+	err = con.decodeList(body, &labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, label := range labels {
+		label.Con = con
+	}
+	return labels, err
+}
+
+// NewAffinityLabel Create a new affinity label structure
+func (con *Connection) NewAffinityLabel() *AffinityLabel {
+	return &AffinityLabel{OvirtObject: OvirtObject{Con: con}}
+}
+
+// Save Updates the server with the local copy of the affinity label, sending only the fields
+// modified since it was loaded
+func (label *AffinityLabel) Save(options ...SaveOption) error {
+	if err := label.Con.Require(AffinityLabels); err != nil {
+		return err
+	}
+	body, err := label.Con.encodeChanges(label)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already saved affinity label, we need to update it
+	if label.Href != "" {
+		body, err = label.Con.put(label, label.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		link, err := label.Con.GetLink("affinitylabels")
+		if err != nil {
+			return err
+		}
+		body, err = label.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempLabel := AffinityLabel{OvirtObject: OvirtObject{Con: label.Con}}
+	err = label.Con.decode(body, &tempLabel)
+	if err != nil {
+		return err
+	}
+	*label = tempLabel
+	return nil
+}
+
+// Patch Updates the server with the fields of the affinity label named by their JSON
+// path, such as "name" or "read_only", whether they were modified or not
+func (label *AffinityLabel) Patch(fields ...string) error {
+	return label.Con.patch(label, fields)
+}
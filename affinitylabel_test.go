@@ -0,0 +1,54 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"testing"
+)
+
+func TestAffinityLabel(t *testing.T) {
+	t.Parallel()
+	con := newTestConnection(t)
+	newLabel := con.NewAffinityLabel()
+	newLabel.Name = "test-affinity-label"
+	err := newLabel.Save()
+	if err != nil {
+		t.Fatal("Error creating new affinity label", err)
+	}
+	allHosts, err := con.GetAllHosts()
+	if err != nil || len(allHosts) == 0 {
+		t.Fatal("Error finding a host to label", err)
+	}
+	err = newLabel.AddHost(allHosts[0])
+	if err != nil {
+		t.Fatal("Error adding host to affinity label", err)
+	}
+	labelHosts, err := newLabel.GetHosts()
+	if err != nil {
+		t.Fatal("Error retrieving affinity label hosts", err)
+	}
+	if len(labelHosts) != 1 || labelHosts[0].ID != allHosts[0].ID {
+		t.Error("Affinity label does not list the added host", labelHosts)
+	}
+	err = newLabel.RemoveHost(allHosts[0])
+	if err != nil {
+		t.Error("Error removing host from affinity label", err)
+	}
+	retrievedLabel, err := con.GetAffinityLabel(newLabel.ID)
+	if err != nil {
+		t.Fatal("Error retrieving affinity label", err)
+	}
+	retrievedLabel.Description = "about to delete"
+	err = retrievedLabel.Save()
+	if err != nil {
+		t.Fatal("Error updating affinity label", err)
+	}
+	err = retrievedLabel.Delete()
+	if err != nil {
+		t.Fatal("Error Deleting affinity label", err)
+	}
+}
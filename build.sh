@@ -1,3 +1,4 @@
 #!/bin/bash
 
-genny -in=ovirtObjectMethods.template -out=ovirtObjectMethods.go gen "OvirtObjectType=VM,Cluster,DataCenter,Template" -pkg ovirtapi
+genny -in=ovirtObjectMethods.template -out=ovirtObjectMethods.go gen "OvirtObjectType=VM,Cluster,DataCenter,Template,Tag" -pkg ovirtapi
+go run xmltags.go
@@ -0,0 +1,242 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// SaveOption Changes how Save updates an object on the server
+type SaveOption func(*saveOptions)
+
+// saveOptions The settings of a call to Save
+type saveOptions struct {
+	// The query parameters of the request
+	query url.Values
+	// Whether to fail when the object was modified on the server since it was loaded
+	detectConflicts bool
+}
+
+// newSaveOptions The settings of a call to Save given its options
+func newSaveOptions(options []SaveOption) *saveOptions {
+	settings := &saveOptions{query: url.Values{}}
+	for _, option := range options {
+		option(settings)
+	}
+	return settings
+}
+
+// withQuery The URL with the query parameters of the call
+func (settings *saveOptions) withQuery(requestURL *url.URL) *url.URL {
+	if len(settings.query) > 0 {
+		requestURL.RawQuery = settings.query.Encode()
+	}
+	return requestURL
+}
+
+// tracked An object of the API remembering how it was when it was loaded from the engine
+type tracked interface {
+	ovirtObject() *OvirtObject
+}
+
+func (ovirtObject *OvirtObject) ovirtObject() *OvirtObject {
+	return ovirtObject
+}
+
+// track Remembers the representation the objects were loaded from, so saving
+// them later sends only the fields modified since. body is the representation
+// of object, or nil to encode it.
+func (con *Connection) track(object interface{}, body []byte) {
+	value := reflect.ValueOf(object)
+	if value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Slice {
+		list := value.Elem()
+		for i := 0; i < list.Len(); i++ {
+			if list.Index(i).CanAddr() {
+				con.track(list.Index(i).Addr().Interface(), nil)
+			}
+		}
+		return
+	}
+	if value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Ptr {
+		if !value.Elem().IsNil() {
+			con.track(value.Elem().Interface(), body)
+		}
+		return
+	}
+	trackedObject, ok := object.(tracked)
+	if !ok {
+		return
+	}
+	if body == nil {
+		var err error
+		if body, err = con.encode(object); err != nil {
+			return
+		}
+	}
+	trackedObject.ovirtObject().snapshot = body
+}
+
+// encodeChanges The body of a request saving the object. Objects loaded from
+// the engine are sent with the fields modified since they were loaded only,
+// new objects are sent whole.
+func (con *Connection) encodeChanges(object interface{}) ([]byte, error) {
+	changed, err := con.changes(object)
+	if err != nil {
+		return nil, err
+	}
+	return con.encode(changed)
+}
+
+// changes A new object of the type of object holding the fields modified since
+// it was loaded, object itself when it was not loaded from the engine
+func (con *Connection) changes(object interface{}) (interface{}, error) {
+	trackedObject, ok := object.(tracked)
+	if !ok || trackedObject.ovirtObject().Href == "" || trackedObject.ovirtObject().snapshot == nil {
+		return object, nil
+	}
+	current := reflect.ValueOf(object).Elem()
+	original := reflect.New(current.Type())
+	err := con.decode(trackedObject.ovirtObject().snapshot, original.Interface())
+	if err != nil {
+		return nil, err
+	}
+	changed := reflect.New(current.Type())
+	copyChanges(changed.Elem(), current, original.Elem())
+	return changed.Interface(), nil
+}
+
+// copyChanges Copies the encoded fields of current differing from original to changed
+func copyChanges(changed reflect.Value, current reflect.Value, original reflect.Value) {
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		if embedded(field) && field.Type.Kind() == reflect.Struct {
+			copyChanges(changed.Field(i), current.Field(i), original.Field(i))
+			continue
+		}
+		if field.PkgPath != "" || jsonName(field) == "" {
+			continue
+		}
+		if !reflect.DeepEqual(current.Field(i).Interface(), original.Field(i).Interface()) {
+			changed.Field(i).Set(current.Field(i))
+		}
+	}
+}
+
+// patch Sends the fields of the saved object named by the paths to the engine,
+// with the options of Save, and replaces object with the representation the
+// engine answers with
+func (con *Connection) patch(object interface{}, fields []string, options ...SaveOption) error {
+	trackedObject, ok := object.(tracked)
+	if !ok || trackedObject.ovirtObject().Href == "" {
+		return errors.New("Object has not been saved to the server")
+	}
+	current := reflect.ValueOf(object).Elem()
+	paths := [][]string{}
+	for _, field := range fields {
+		paths = append(paths, strings.Split(field, "."))
+	}
+	masked, err := maskPaths(current, paths, "")
+	if err != nil {
+		return err
+	}
+	body, err := con.encodeElement(elementName(current.Type()), masked.Interface())
+	if err != nil {
+		return err
+	}
+	body, err = con.put(object, trackedObject.ovirtObject().Href, body, options)
+	if err != nil {
+		return err
+	}
+	updated := reflect.New(current.Type())
+	updated.Interface().(tracked).ovirtObject().Con = trackedObject.ovirtObject().Con
+	err = con.decode(body, updated.Interface())
+	if err != nil {
+		return err
+	}
+	current.Set(updated.Elem())
+	return nil
+}
+
+// maskPaths A value of a new struct type holding only the fields of the
+// struct src named by the paths of JSON names, with the objects on the paths.
+// The named fields are encoded even when they hold their zero value, so they
+// can be cleared. A path through, or to, an unset object is an error. prefix
+// is the path of src, for the errors.
+func maskPaths(src reflect.Value, paths [][]string, prefix string) (reflect.Value, error) {
+	names := []string{}
+	children := map[string][][]string{}
+	for _, path := range paths {
+		if _, ok := children[path[0]]; !ok {
+			names = append(names, path[0])
+		}
+		children[path[0]] = append(children[path[0]], path[1:])
+	}
+	fields := []reflect.StructField{}
+	values := []reflect.Value{}
+	for _, name := range names {
+		index := fieldIndex(src.Type(), name)
+		if index == nil {
+			return reflect.Value{}, fmt.Errorf("Invalid field %q: %s has no attribute %s", prefix+name, src.Type().Name(), name)
+		}
+		field := src.Type().FieldByIndex(index)
+		value := src.FieldByIndex(index)
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}, fmt.Errorf("Invalid field %q: %s is not set", prefix+name, prefix+name)
+			}
+			value = value.Elem()
+		}
+		whole := false
+		for _, child := range children[name] {
+			whole = whole || len(child) == 0
+		}
+		if !whole {
+			if value.Kind() != reflect.Struct {
+				return reflect.Value{}, fmt.Errorf("Invalid field %q: %s is not an object", prefix+name, prefix+name)
+			}
+			var err error
+			if value, err = maskPaths(value, children[name], prefix+name+"."); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("%s%d", field.Name, len(fields)),
+			Type: value.Type(),
+			Tag:  reflect.StructTag(strings.Replace(string(field.Tag), ",omitempty", "", -1)),
+		})
+		values = append(values, value)
+	}
+	masked := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, value := range values {
+		masked.Field(i).Set(value)
+	}
+	return masked, nil
+}
+
+// fieldIndex The index of the field encoded with the JSON name, nil when there
+// is none. Fields of embedded objects are shadowed by the fields of the object.
+func fieldIndex(structType reflect.Type, name string) []int {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !embedded(field) && field.PkgPath == "" && jsonName(field) == name {
+			return []int{i}
+		}
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if embedded(field) && field.Type.Kind() == reflect.Struct {
+			if index := fieldIndex(field.Type, name); index != nil {
+				return append([]int{i}, index...)
+			}
+		}
+	}
+	return nil
+}
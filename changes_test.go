@@ -0,0 +1,250 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+// putRecorder Keeps the bodies of the PUT requests sent through it
+type putRecorder struct {
+	lock   sync.Mutex
+	bodies []string
+}
+
+func (recorder *putRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == "PUT" {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+		recorder.lock.Lock()
+		recorder.bodies = append(recorder.bodies, string(body))
+		recorder.lock.Unlock()
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// last The attributes of the last PUT request, sorted
+func (recorder *putRecorder) last(t *testing.T) []string {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	if len(recorder.bodies) == 0 {
+		t.Fatal("No PUT request was sent")
+	}
+	object := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(recorder.bodies[len(recorder.bodies)-1]), &object); err != nil {
+		t.Fatal("Error parsing the PUT request", err)
+	}
+	keys := []string{}
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newChangesVM Creates a VM on a fake engine through a connection recording the PUT requests
+func newChangesVM(t *testing.T, options ...ovirtapi.ConnectionOption) (*ovirtapitest.Engine, *ovirtapi.Connection, *putRecorder, *ovirtapi.VM) {
+	engine := ovirtapitest.NewEngine()
+	t.Cleanup(engine.Close)
+	recorder := &putRecorder{}
+	options = append(options, ovirtapi.WithTransport(recorder), ovirtapi.WithFilter(false))
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, options...)
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	clusters, err := con.GetAllClusters()
+	if err != nil || len(clusters) == 0 {
+		t.Fatal("Error getting a cluster", err)
+	}
+	template, err := con.GetTemplate(ovirtapitest.BlankTemplateID)
+	if err != nil {
+		t.Fatal("Error getting the blank template", err)
+	}
+	vm := con.NewVM()
+	vm.Name = "changes"
+	vm.Description = "created"
+	vm.Memory = 1073741824
+	vm.Cluster = clusters[0]
+	vm.Template = template
+	if err = vm.Save(); err != nil {
+		t.Fatal("Error creating the VM", err)
+	}
+	return engine, con, recorder, vm
+}
+
+func TestSaveChanges(t *testing.T) {
+	t.Parallel()
+	engine, con, recorder, vm := newChangesVM(t)
+
+	vm.Description = "modified"
+	if err := vm.Save(); err != nil {
+		t.Fatal("Error saving the VM", err)
+	}
+	if sent := recorder.last(t); !reflect.DeepEqual(sent, []string{"description"}) {
+		t.Error("Did not send only the modified field", sent)
+	}
+	if vm.Description != "modified" || engine.Get("vms", vm.ID)["description"] != "modified" {
+		t.Error("Did not save the modified field", vm.Description)
+	}
+
+	if err := vm.Save(); err != nil {
+		t.Fatal("Error saving the unmodified VM", err)
+	}
+	if sent := recorder.last(t); len(sent) != 0 {
+		t.Error("Sent fields of an unmodified VM", sent)
+	}
+
+	// Two copies of the VM modifying different fields keep both changes
+	first, err := con.GetVM(vm.ID)
+	if err != nil {
+		t.Fatal("Error getting the VM", err)
+	}
+	second, err := con.GetVM(vm.ID)
+	if err != nil {
+		t.Fatal("Error getting the VM", err)
+	}
+	first.Comment = "first"
+	second.Memory = 2147483648
+	if err = first.Save(); err != nil {
+		t.Fatal("Error saving the first copy", err)
+	}
+	if err = second.Save(); err != nil {
+		t.Fatal("Error saving the second copy", err)
+	}
+	if second.Comment != "first" || second.Memory != 2147483648 {
+		t.Error("Clobbered the change of the first copy", second.Comment, second.Memory)
+	}
+
+	// Objects not loaded from the engine are sent whole
+	detached := &ovirtapi.VM{OvirtObject: ovirtapi.OvirtObject{Con: con, Link: ovirtapi.Link{Href: vm.Href}}}
+	detached.Name = "detached"
+	if err = detached.Save(); err != nil {
+		t.Fatal("Error saving the detached VM", err)
+	}
+	if sent := recorder.last(t); !reflect.DeepEqual(sent, []string{"href", "name"}) {
+		t.Error("Did not send the whole detached VM", sent)
+	}
+}
+
+func TestSaveChangesXML(t *testing.T) {
+	t.Parallel()
+	_, _, recorder, vm := newChangesVM(t, ovirtapi.WithFormat(ovirtapi.FormatXML))
+	vm.Description = "modified"
+	if err := vm.Save(); err != nil {
+		t.Fatal("Error saving the VM", err)
+	}
+	recorder.lock.Lock()
+	sent := recorder.bodies[len(recorder.bodies)-1]
+	recorder.lock.Unlock()
+	if !strings.Contains(sent, "<description>modified</description>") || strings.Contains(sent, "<name>") {
+		t.Error("Did not send only the modified field", sent)
+	}
+}
+
+func TestSaveSubCollectionChanges(t *testing.T) {
+	t.Parallel()
+	_, con, recorder, _ := newChangesVM(t)
+	dataCenters, err := con.GetAllDataCenters()
+	if err != nil || len(dataCenters) == 0 {
+		t.Fatal("Error getting a data center", err)
+	}
+	newQoS := dataCenters[0].NewQoS()
+	newQoS.Name = "changes"
+	newQoS.Type = ovirtapi.QoSTypeStorage
+	newQoS.MaxIOPS = 500
+	if err = newQoS.Save(); err != nil {
+		t.Fatal("Error creating the QoS", err)
+	}
+	qoss, err := dataCenters[0].GetQoSs()
+	if err != nil || len(qoss) != 1 {
+		t.Fatal("Error listing the QoS of the data center", qoss, err)
+	}
+	qoss[0].MaxIOPS = 1000
+	if err = qoss[0].Save(); err != nil {
+		t.Fatal("Error saving the QoS", err)
+	}
+	if sent := recorder.last(t); !reflect.DeepEqual(sent, []string{"max_iops"}) {
+		t.Error("Did not send only the modified field of a listed object", sent)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	t.Parallel()
+	engine, _, recorder, vm := newChangesVM(t)
+
+	vm.Description = "not sent"
+	vm.Memory = 2147483648
+	vm.CPU = &ovirtapi.CPU{Topology: &ovirtapi.CPUTopology{Cores: 4, Sockets: 2}}
+	if err := vm.Patch("memory", "cpu.topology.cores"); err != nil {
+		t.Fatal("Error patching the VM", err)
+	}
+	if sent := recorder.last(t); !reflect.DeepEqual(sent, []string{"cpu", "memory"}) {
+		t.Error("Did not send only the masked fields", sent)
+	}
+	stored := engine.Get("vms", vm.ID)
+	topology := stored["cpu"].(map[string]interface{})["topology"].(map[string]interface{})
+	if _, ok := topology["sockets"]; ok || topology["cores"] != "4" {
+		t.Error("Did not send only the masked nested field", topology)
+	}
+	if vm.Memory != 2147483648 || vm.Description != "created" {
+		t.Error("Did not update the VM with the response", vm.Memory, vm.Description)
+	}
+
+	// Fields named by Patch are sent even when they are cleared
+	vm.Description = ""
+	if err := vm.Patch("description"); err != nil {
+		t.Fatal("Error clearing the description", err)
+	}
+	recorder.lock.Lock()
+	sent := recorder.bodies[len(recorder.bodies)-1]
+	recorder.lock.Unlock()
+	if !strings.Contains(sent, `"description": ""`) || strings.Contains(sent, "memory") {
+		t.Error("Did not send the cleared field", sent)
+	}
+	if description, ok := engine.Get("vms", vm.ID)["description"]; ok && description != "" {
+		t.Error("Did not clear the description", description)
+	}
+
+	vm.CPU = nil
+	for _, invalid := range []string{"unknown", "memory.size", "cpu.topology.cores", "cpu"} {
+		if err := vm.Patch(invalid); err == nil {
+			t.Error("Did not reject the invalid field", invalid)
+		}
+	}
+	if err := vm.Con.NewVM().Patch("name"); err == nil {
+		t.Error("Patched a VM that was not saved")
+	}
+}
+
+func TestPatchXML(t *testing.T) {
+	t.Parallel()
+	_, _, recorder, vm := newChangesVM(t, ovirtapi.WithFormat(ovirtapi.FormatXML))
+	vm.Description = ""
+	vm.Memory = 2147483648
+	if err := vm.Patch("description", "memory"); err != nil {
+		t.Fatal("Error patching the VM", err)
+	}
+	recorder.lock.Lock()
+	sent := recorder.bodies[len(recorder.bodies)-1]
+	recorder.lock.Unlock()
+	if !strings.Contains(sent, "<description></description>") || !strings.Contains(sent, "<memory>2147483648</memory>") || strings.Contains(sent, "<name>") {
+		t.Error("Did not send only the masked fields", sent)
+	}
+}
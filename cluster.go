@@ -7,162 +7,162 @@
 package ovirtapi
 
 type GlusterClient struct {
-	BytesRead    int    `json:"bytes_read,omitempty,string"`
-	BytesWritten int    `json:"bytes_written,omitempty,string"`
-	ClientPort   int    `json:"client_port,omitempty,string"`
-	HostName     string `json:"host_name,omitempty"`
+	BytesRead    int    `json:"bytes_read,omitempty,string" xml:"bytes_read,omitempty"`
+	BytesWritten int    `json:"bytes_written,omitempty,string" xml:"bytes_written,omitempty"`
+	ClientPort   int    `json:"client_port,omitempty,string" xml:"client_port,omitempty"`
+	HostName     string `json:"host_name,omitempty" xml:"host_name,omitempty"`
 }
 
 type GlusterMemoryPool struct {
-	Alloc_count int `json:"alloc_count,omitempty,string"`
-	Cold_count  int `json:"cold_count,omitempty,string"`
+	Alloc_count int `json:"alloc_count,omitempty,string" xml:"alloc_count,omitempty"`
+	Cold_count  int `json:"cold_count,omitempty,string" xml:"cold_count,omitempty"`
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
-	Hot_count   int    `json:"hot_count,omitempty,string"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
+	Hot_count   int    `json:"hot_count,omitempty,string" xml:"hot_count,omitempty"`
 	// A unique identifier.
-	Id           string `json:"id,omitempty"`
-	Max_alloc    int    `json:"max_alloc,omitempty,string"`
-	Max_stdalloc int    `json:"max_stdalloc,omitempty,string"`
+	Id           string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Max_alloc    int    `json:"max_alloc,omitempty,string" xml:"max_alloc,omitempty"`
+	Max_stdalloc int    `json:"max_stdalloc,omitempty,string" xml:"max_stdalloc,omitempty"`
 	// A human-readable name in plain text.
-	Name        string `json:"name,omitempty"`
-	Padded_size int    `json:"padded_size,omitempty,string"`
-	Pool_misses int    `json:"pool_misses,omitempty,string"`
-	Type        string `json:"type,omitempty"`
+	Name        string `json:"name,omitempty" xml:"name,omitempty"`
+	Padded_size int    `json:"padded_size,omitempty,string" xml:"padded_size,omitempty"`
+	Pool_misses int    `json:"pool_misses,omitempty,string" xml:"pool_misses,omitempty"`
+	Type        string `json:"type,omitempty" xml:"type,omitempty"`
 }
 
 type GlusterBrick struct {
-	BrickDir string `json:"brick_dir,omitempty"`
+	BrickDir string `json:"brick_dir,omitempty" xml:"brick_dir,omitempty"`
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// A human-readable description in plain text.
-	Description    string          `json:"description,omitempty"`
-	Device         string          `json:"device,omitempty"`
-	FSName         string          `json:"fs_name,omitempty"`
-	GlusterClients []GlusterClient `json:"gluster_clients,omitempty"`
+	Description    string          `json:"description,omitempty" xml:"description,omitempty"`
+	Device         string          `json:"device,omitempty" xml:"device,omitempty"`
+	FSName         string          `json:"fs_name,omitempty" xml:"fs_name,omitempty"`
+	GlusterClients []GlusterClient `json:"gluster_clients>gluster_client,omitempty" xml:"gluster_clients>gluster_client,omitempty"`
 	// A unique identifier.
-	Id          string              `json:"id,omitempty"`
-	MemoryPools []GlusterMemoryPool `json:"memory_pools,omitempty"`
-	MntOptions  string              `json:"mnt_options,omitempty"`
+	Id          string              `json:"id,omitempty" xml:"id,attr,omitempty"`
+	MemoryPools []GlusterMemoryPool `json:"memory_pools>memory_pool,omitempty" xml:"memory_pools>memory_pool,omitempty"`
+	MntOptions  string              `json:"mnt_options,omitempty" xml:"mnt_options,omitempty"`
 	// A human-readable name in plain text.
-	Name     string `json:"name,omitempty"`
-	Pid      int    `json:"pid,omitempty"`
-	Port     int    `json:"port,omitempty"`
-	ServerId string `json:"server_id,omitempty"`
-	Status   string `json:"status,omitempty"`
+	Name     string `json:"name,omitempty" xml:"name,omitempty"`
+	Pid      int    `json:"pid,omitempty" xml:"pid,omitempty"`
+	Port     int    `json:"port,omitempty" xml:"port,omitempty"`
+	ServerId string `json:"server_id,omitempty" xml:"server_id,omitempty"`
+	Status   string `json:"status,omitempty" xml:"status,omitempty"`
 }
 
 type GlusterVolume struct {
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// A human-readable description in plain text.
-	Description   string `json:"description,omitempty"`
-	DisperseCount int    `json:"disperse_count,omitempty,string"`
+	Description   string `json:"description,omitempty" xml:"description,omitempty"`
+	DisperseCount int    `json:"disperse_count,omitempty,string" xml:"disperse_count,omitempty"`
 	// A unique identifier.
-	ID string `json:"id,omitempty"`
+	ID string `json:"id,omitempty" xml:"id,attr,omitempty"`
 	// A human-readable name in plain text.
-	Name            string         `json:"name,omitempty"`
-	Options         []Option       `json:"options,omitempty"`
-	RedundancyCount int            `json:"redundancy_count,omitempty,string"`
-	ReplicaCount    int            `json:"replica_count,omitempty,string"`
-	Status          string         `json:"status,omitempty"`
-	StripeCount     int            `json:"stripe_count,omitempty,string"`
-	TransportTypes  []string       `json:"transport_types,omitempty"`
-	VolumeType      string         `json:"volume_type,omitempty"`
-	Bricks          []GlusterBrick `json:"bricks,omitempty"`
-	Cluster         Cluster        `json:"cluster,omitempty"`
+	Name            string         `json:"name,omitempty" xml:"name,omitempty"`
+	Options         []Option       `json:"options>option,omitempty" xml:"options>option,omitempty"`
+	RedundancyCount int            `json:"redundancy_count,omitempty,string" xml:"redundancy_count,omitempty"`
+	ReplicaCount    int            `json:"replica_count,omitempty,string" xml:"replica_count,omitempty"`
+	Status          string         `json:"status,omitempty" xml:"status,omitempty"`
+	StripeCount     int            `json:"stripe_count,omitempty,string" xml:"stripe_count,omitempty"`
+	TransportTypes  []string       `json:"transport_types>transport_type,omitempty" xml:"transport_types>transport_type,omitempty"`
+	VolumeType      string         `json:"volume_type,omitempty" xml:"volume_type,omitempty"`
+	Bricks          []GlusterBrick `json:"bricks>brick,omitempty" xml:"bricks>brick,omitempty"`
+	Cluster         Cluster        `json:"cluster,omitempty" xml:"cluster,omitempty"`
 	// statistics      []Statistic    `json:"statistics,omitempty"`
 }
 
 type Property struct {
-	Name  string `json:"name,omitempty"`
-	Value string `json:"value,omitempty"`
+	Name  string `json:"name,omitempty" xml:"name,omitempty"`
+	Value string `json:"value,omitempty" xml:"value,omitempty"`
 }
 type ErrorHandling struct {
-	OnError string `json:"on_error,omitempty"`
+	OnError string `json:"on_error,omitempty" xml:"on_error,omitempty"`
 }
 
 type SkipIfConnectivityBroken struct {
 	// If enabled, we will not fence a host in case more than a configurable percentage of hosts in the cluster lost connectivity as well.
-	Enabled string `json:"enabled,omitempty"`
+	Enabled string `json:"enabled,omitempty" xml:"enabled,omitempty"`
 	// Threshold for connectivity testing.
-	Threshold int `json:"threshold,omitempty,string"`
+	Threshold int `json:"threshold,omitempty,string" xml:"threshold,omitempty"`
 }
 
 type SkipIfSDActive struct {
 	// If enabled, we will skip fencing in case the host maintains its lease in the storage.
-	Enabled string `json:"enabled,omitempty"`
+	Enabled string `json:"enabled,omitempty" xml:"enabled,omitempty"`
 }
 
 // FencingPolicy Type representing a cluster fencing policy.
 type FencingPolicy struct {
 	// Enable or disable fencing on this cluster.
-	Enabled string `json:"enabled,omitempty"`
+	Enabled string `json:"enabled,omitempty" xml:"enabled,omitempty"`
 	// If enabled, we will not fence a host in case more than a configurable percentage of hosts in the cluster lost connectivity as well.
-	SkipIfConnectivityBroken *SkipIfConnectivityBroken `json:"skip_if_connectivity_broken,omitempty"`
+	SkipIfConnectivityBroken *SkipIfConnectivityBroken `json:"skip_if_connectivity_broken,omitempty" xml:"skip_if_connectivity_broken,omitempty"`
 	// A flag indicating if fencing should be skipped if Gluster bricks are up and running in the host being fenced.
-	SkipIfGlusterBricksUp string `json:"skip_if_gluster_bricks_up,omitempty"`
+	SkipIfGlusterBricksUp string `json:"skip_if_gluster_bricks_up,omitempty" xml:"skip_if_gluster_bricks_up,omitempty"`
 	// A flag indicating if fencing should be skipped if Gluster bricks are up and running and Gluster quorum will not be met without those bricks.
-	SkipIfGlusterQuorumNotMet string `json:"skip_if_gluster_quorum_not_met,omitempty"`
+	SkipIfGlusterQuorumNotMet string `json:"skip_if_gluster_quorum_not_met,omitempty" xml:"skip_if_gluster_quorum_not_met,omitempty"`
 	// If enabled, we will skip fencing in case the host maintains its lease in the storage.
-	SkipIfSdActive *SkipIfSDActive `json:"skip_if_sd_active,omitempty"`
+	SkipIfSdActive *SkipIfSDActive `json:"skip_if_sd_active,omitempty" xml:"skip_if_sd_active,omitempty"`
 }
 
 type SerialNumber struct {
-	Policy string `json:"policy,omitempty"`
-	Value  string `json:"value,omitempty"`
+	Policy string `json:"policy,omitempty" xml:"policy,omitempty"`
+	Value  string `json:"value,omitempty" xml:"value,omitempty"`
 }
 
 type Properties struct {
-	Property []Property `json:"property,omitempty"`
+	Property []Property `json:"property,omitempty" xml:"property,omitempty"`
 }
 
 type RequiredRNGSources struct {
-	RequiredRNGSource []string `json:"required_rng_source,omitempty"`
+	RequiredRNGSource []string `json:"required_rng_source,omitempty" xml:"required_rng_source,omitempty"`
 }
 
 // Cluster Type representation of a cluster.
 type Cluster struct {
 	OvirtObject
-	BallooningEnabled string `json:"ballooning_enabled,omitempty"`
-	Comment           string `json:"comment,omitempty"`
-	CPU               *CPU   `json:"cpu,omitempty"`
+	BallooningEnabled string `json:"ballooning_enabled,omitempty" xml:"ballooning_enabled,omitempty"`
+	Comment           string `json:"comment,omitempty" xml:"comment,omitempty"`
+	CPU               *CPU   `json:"cpu,omitempty" xml:"cpu,omitempty"`
 	// Custom scheduling policy properties of the cluster.
-	CustomSchedulingPolicyProperties *Properties    `json:"custom_scheduling_policy_properties,omitempty"`
-	ErrorHandling                    *ErrorHandling `json:"ErrorHandling,omitempty"`
+	CustomSchedulingPolicyProperties *Properties    `json:"custom_scheduling_policy_properties,omitempty" xml:"custom_scheduling_policy_properties,omitempty"`
+	ErrorHandling                    *ErrorHandling `json:"ErrorHandling,omitempty" xml:"ErrorHandling,omitempty"`
 	// Custom fencing policy can be defined for a cluster.
-	FencingPolicy  *FencingPolicy `json:"fencing_policy,omitempty"`
-	GlusterService string         `json:"gluster_service,omitempty"`
+	FencingPolicy  *FencingPolicy `json:"fencing_policy,omitempty" xml:"fencing_policy,omitempty"`
+	GlusterService string         `json:"gluster_service,omitempty" xml:"gluster_service,omitempty"`
 	// The name of the https://fedorahosted.
-	GlusterTunedProfile       string            `json:"gluster_tuned_profile,omitempty"`
-	HAReservation             string            `json:"ha_reservation,omitempty"`
-	KSM                       *KSM              `json:"ksm,omitempty"`
-	MaintenanceReasonRequired string            `json:"maintenance_reason_required,omitempty"`
-	MemoryPolicy              *MemoryPolicy     `json:"memory_policy,omitempty"`
-	Migration                 *MigrationOptions `json:"migration,omitempty"`
-	OptionalReason            string            `json:"optional_reason,omitempty"`
+	GlusterTunedProfile       string            `json:"gluster_tuned_profile,omitempty" xml:"gluster_tuned_profile,omitempty"`
+	HAReservation             string            `json:"ha_reservation,omitempty" xml:"ha_reservation,omitempty"`
+	KSM                       *KSM              `json:"ksm,omitempty" xml:"ksm,omitempty"`
+	MaintenanceReasonRequired string            `json:"maintenance_reason_required,omitempty" xml:"maintenance_reason_required,omitempty"`
+	MemoryPolicy              *MemoryPolicy     `json:"memory_policy,omitempty" xml:"memory_policy,omitempty"`
+	Migration                 *MigrationOptions `json:"migration,omitempty" xml:"migration,omitempty"`
+	OptionalReason            string            `json:"optional_reason,omitempty" xml:"optional_reason,omitempty"`
 	// Set of random number generator (RNG) sources required from each host in the cluster.
-	RequiredRNGSources *RequiredRNGSources `json:"required_rng_sources,omitempty"`
-	SerialNumber       *SerialNumber       `json:"serial_number,omitempty"`
-	SupportedVersions  []Version           `json:"supported_versions,omitempty"`
+	RequiredRNGSources *RequiredRNGSources `json:"required_rng_sources,omitempty" xml:"required_rng_sources,omitempty"`
+	SerialNumber       *SerialNumber       `json:"serial_number,omitempty" xml:"serial_number,omitempty"`
+	SupportedVersions  []Version           `json:"supported_versions>version,omitempty" xml:"supported_versions>version,omitempty"`
 	// Type of switch to be used by all networks in given cluster.
-	SwitchType      string `json:"switch_type,omitempty"`
-	ThreadsAsCores  string `json:"threads_as_cores,omitempty"`
-	TrustedService  string `json:"trusted_service,omitempty"`
-	TunnelMigration string `json:"tunnel_migration,omitempty"`
+	SwitchType      string `json:"switch_type,omitempty" xml:"switch_type,omitempty"`
+	ThreadsAsCores  string `json:"threads_as_cores,omitempty" xml:"threads_as_cores,omitempty"`
+	TrustedService  string `json:"trusted_service,omitempty" xml:"trusted_service,omitempty"`
+	TunnelMigration string `json:"tunnel_migration,omitempty" xml:"tunnel_migration,omitempty"`
 	// The compatibility version of the cluster.
-	Version           *Version        `json:"version,omitempty"`
-	VirtService       string          `json:"virt_service,omitempty"`
-	AffinityGroups    []Link          `json:"affinity_groups,omitempty"`
-	CPUProfiles       []Link          `json:"cpu_profiles,omitempty"`
-	DataCenter        *DataCenter     `json:"data_center,omitempty"`
-	GlusterHooks      []Link          `json:"gluster_hooks,omitempty"`
-	GlusterVolumes    []GlusterVolume `json:"gluster_volume,omitempty"`
-	MacPool           *Link           `json:"mac_pool,omitempty"`
-	ManagementNetwork *Link           `json:"management_network,omitempty"`
-	NetworkFilters    *Link           `json:"network_filters,omitempty"`
-	Networks          []Link          `json:"networks,omitempty"`
-	Permissions       []Link          `json:"permissions,omitempty"`
-	SchedulingPolicy  *Link           `json:"scheduling_policy,omitempty"`
+	Version           *Version        `json:"version,omitempty" xml:"version,omitempty"`
+	VirtService       string          `json:"virt_service,omitempty" xml:"virt_service,omitempty"`
+	AffinityGroups    []AffinityGroup `json:"affinity_groups>affinity_group,omitempty" xml:"affinity_groups>affinity_group,omitempty"`
+	CPUProfiles       []CPUProfile    `json:"cpu_profiles>cpu_profile,omitempty" xml:"cpu_profiles>cpu_profile,omitempty"`
+	DataCenter        *DataCenter     `json:"data_center,omitempty" xml:"data_center,omitempty"`
+	GlusterHooks      []Link          `json:"gluster_hooks>gluster_hook,omitempty" xml:"gluster_hooks>gluster_hook,omitempty"`
+	GlusterVolumes    []GlusterVolume `json:"gluster_volumes>gluster_volume,omitempty" xml:"gluster_volumes>gluster_volume,omitempty"`
+	MacPool           *Link           `json:"mac_pool,omitempty" xml:"mac_pool,omitempty"`
+	ManagementNetwork *Link           `json:"management_network,omitempty" xml:"management_network,omitempty"`
+	NetworkFilters    *Link           `json:"network_filters,omitempty" xml:"network_filters,omitempty"`
+	Networks          []Link          `json:"networks>network,omitempty" xml:"networks>network,omitempty"`
+	Permissions       []Link          `json:"permissions>permission,omitempty" xml:"permissions>permission,omitempty"`
+	SchedulingPolicy  *Link           `json:"scheduling_policy,omitempty" xml:"scheduling_policy,omitempty"`
 }
@@ -0,0 +1,100 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+// TestConcurrentConnection Shares one connection between many workers, run with -race
+func TestConcurrentConnection(t *testing.T) {
+	t.Parallel()
+	url, username, password := testCredentials(t)
+	registry := ovirtapi.NewMetricsRegistry()
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	con, err := ovirtapi.NewConnection(url, username, password, false,
+		ovirtapi.WithMetrics(registry), ovirtapi.WithLogger(logger), ovirtapi.WithMaxInFlight(16), ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	allVMs, err := con.GetAllVMs()
+	if err != nil || len(allVMs) == 0 {
+		t.Fatal("Error retrieving vms", err)
+	}
+	vmID := allVMs[0].ID
+	const workers = 200
+	errs := make(chan error, workers)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			workerCon := con
+			if i%2 == 0 {
+				workerCon = con.WithContext(ovirtapi.ContextWithPriority(context.Background(), ovirtapi.PriorityLow))
+			}
+			vm, err := workerCon.GetVM(vmID)
+			if err != nil {
+				errs <- err
+				return
+			}
+			err = vm.Update()
+			if err != nil {
+				errs <- err
+				return
+			}
+			tag := workerCon.NewTag()
+			tag.Name = fmt.Sprintf("test-concurrent-%d", i)
+			err = tag.Save()
+			if err != nil {
+				errs <- err
+				return
+			}
+			err = tag.Delete()
+			if err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error("Error in worker", err)
+	}
+	if count := registry.Requests("GET", "vms")[200]; count != 2*workers+1 {
+		t.Error("Expected every vm request to be counted, got", count)
+	}
+}
+
+// TestConnectionConfig The configuration read through the getters cannot change the connection
+func TestConnectionConfig(t *testing.T) {
+	t.Parallel()
+	_, con, _, _ := newChangesVM(t)
+	if con.Filter() || con.Debug() || con.Format() != ovirtapi.FormatJSON || con.UserName() == "" {
+		t.Error("Unexpected configuration", con.Filter(), con.Debug(), con.Format(), con.UserName())
+	}
+	endPoint := con.EndPoint()
+	endPoint.Host = "changed.invalid"
+	links := con.Links()
+	if len(links) == 0 {
+		t.Fatal("The API root has no links")
+	}
+	links[0].Href = "/changed"
+	if con.EndPoint().Host == "changed.invalid" || con.Links()[0].Href == "/changed" {
+		t.Error("Modified the connection through its getters")
+	}
+	if _, err := con.GetAllVMs(); err != nil {
+		t.Error("Error retrieving vms", err)
+	}
+}
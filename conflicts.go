@@ -0,0 +1,187 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DetectConflicts Makes Save fail with a ConflictError instead of overwriting
+// the object when it was modified on the server since it was loaded. The
+// engine does not version its objects, the object is retrieved again and its
+// configuration compared with the representation it was loaded from. Changes
+// of state, such as the status of a VM or the actual size of a disk, are not
+// conflicts. Objects not loaded from the server, such as the ones built with
+// an href, fail to save with this option.
+func DetectConflicts() SaveOption {
+	return func(settings *saveOptions) {
+		settings.detectConflicts = true
+	}
+}
+
+// stateFields The JSON paths of the attributes, by element, the engine
+// updates as the state of the objects changes, such as the guest agent
+// reports of a VM or the allocation of a disk. Their changes on the server
+// are not conflicts, on top of the runtimeFields of every object.
+var stateFields = map[string]map[string]bool{
+	"disk": {
+		"actual_size":  true,
+		"image_id":     true,
+		"logical_name": true,
+		"status":       true,
+	},
+	"host": {
+		"auto_numa_status":        true,
+		"external_status":         true,
+		"hardware_information":    true,
+		"hosted_engine":           true,
+		"kdump_status":            true,
+		"libvirt_version":         true,
+		"max_scheduling_memory":   true,
+		"memory":                  true,
+		"power_management.status": true,
+		"status_detail":           true,
+		"summary":                 true,
+		"update_available":        true,
+		"version":                 true,
+	},
+	"vm": {
+		"fqdn":                   true,
+		"guest_operating_system": true,
+	},
+}
+
+// stateField Whether the attribute at the JSON path of the element is part of its state
+func stateField(element string, path string) bool {
+	fields := stateFields[element]
+	for {
+		if fields[path] {
+			return true
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// ConflictError The object saved was modified on the server since it was loaded
+type ConflictError struct {
+	// The object as it was loaded, of the type of the object saved
+	Loaded interface{}
+	// The object as it is on the server, of the type of the object saved
+	Server interface{}
+	// The JSON paths of the settings modified on the server, such as "cpu.topology.cores"
+	Fields []string
+}
+
+func (err *ConflictError) Error() string {
+	href := ""
+	if object, ok := err.Server.(tracked); ok {
+		href = object.ovirtObject().Href
+	}
+	return fmt.Sprintf("%s was modified on the server since it was loaded: %s", href, strings.Join(err.Fields, ", "))
+}
+
+// IsConflict Whether the error is a ConflictError
+func IsConflict(err error) bool {
+	var conflict *ConflictError
+	return errors.As(err, &conflict)
+}
+
+// RetryOnConflict Calls update until it does not fail with a ConflictError, at
+// most attempts times. update should retrieve the object again, modify it and
+// save it with DetectConflicts. The error of the last attempt is returned,
+// attempts must be at least 1.
+func (con *Connection) RetryOnConflict(attempts int, update func() error) error {
+	if attempts < 1 {
+		return fmt.Errorf("Invalid number of attempts %d, update was not called", attempts)
+	}
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if con.metrics != nil {
+				con.metrics.ObserveRetry("PUT", con.conflictResource(err))
+			}
+			if ctxErr := con.Context().Err(); ctxErr != nil {
+				return ctxErr
+			}
+		}
+		err = update()
+		if !IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// conflictResource The service of the object of the conflict
+func (con *Connection) conflictResource(err error) string {
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		return ""
+	}
+	object, ok := conflict.Server.(tracked)
+	if !ok {
+		return ""
+	}
+	return con.resourceName(con.ResolveLink(object.ovirtObject().Href))
+}
+
+// put Sends the body updating the saved object to the server, with the options of the call to Save
+func (con *Connection) put(object interface{}, href string, body []byte, options []SaveOption) ([]byte, error) {
+	settings := newSaveOptions(options)
+	if settings.detectConflicts {
+		if err := con.checkConflict(object); err != nil {
+			return nil, err
+		}
+	}
+	return con.Request("PUT", settings.withQuery(con.ResolveLink(href)), body)
+}
+
+// checkConflict Returns a ConflictError when the object was modified on the
+// server since it was loaded, and an error when it was not loaded from the
+// server, its changes cannot be told from the ones made on the server
+func (con *Connection) checkConflict(object interface{}) error {
+	trackedObject, ok := object.(tracked)
+	if !ok || trackedObject.ovirtObject().Href == "" || trackedObject.ovirtObject().snapshot == nil {
+		return errors.New("Conflicts cannot be detected for an object not loaded from the server")
+	}
+	objectType := reflect.TypeOf(object).Elem()
+	loaded := reflect.New(objectType)
+	loaded.Interface().(tracked).ovirtObject().Con = trackedObject.ovirtObject().Con
+	err := con.decode(trackedObject.ovirtObject().snapshot, loaded.Interface())
+	if err != nil {
+		return err
+	}
+	body, err := con.Request("GET", con.ResolveLink(trackedObject.ovirtObject().Href), nil)
+	if err != nil {
+		return err
+	}
+	server := reflect.New(objectType)
+	server.Interface().(tracked).ovirtObject().Con = trackedObject.ovirtObject().Con
+	err = con.decode(body, server.Interface())
+	if err != nil {
+		return err
+	}
+	changes := []ConfigChange{}
+	diffConfig("", loaded.Elem(), server.Elem(), &changes)
+	conflict := &ConflictError{Loaded: loaded.Interface(), Server: server.Interface()}
+	for _, change := range changes {
+		if !stateField(elementName(objectType), change.Field) {
+			conflict.Fields = append(conflict.Fields, change.Field)
+		}
+	}
+	if len(conflict.Fields) == 0 {
+		return nil
+	}
+	return conflict
+}
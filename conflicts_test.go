@@ -0,0 +1,173 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+func TestDetectConflicts(t *testing.T) {
+	t.Parallel()
+	engine, con, _, vm := newChangesVM(t)
+	first, err := con.GetVM(vm.ID)
+	if err != nil {
+		t.Fatal("Error getting the VM", err)
+	}
+	second, err := con.GetVM(vm.ID)
+	if err != nil {
+		t.Fatal("Error getting the VM", err)
+	}
+
+	first.Description = "first"
+	if err = first.Save(ovirtapi.DetectConflicts()); err != nil {
+		t.Fatal("Error saving the first copy", err)
+	}
+	second.Comment = "second"
+	err = second.Save(ovirtapi.DetectConflicts())
+	var conflict *ovirtapi.ConflictError
+	if !errors.As(err, &conflict) || !ovirtapi.IsConflict(err) {
+		t.Fatal("Did not detect the conflict", err)
+	}
+	if !reflect.DeepEqual(conflict.Fields, []string{"description"}) {
+		t.Error("Did not report the fields modified on the server", conflict.Fields)
+	}
+	if conflict.Loaded.(*ovirtapi.VM).Description != "created" || conflict.Server.(*ovirtapi.VM).Description != "first" {
+		t.Error("Did not report both versions of the VM", conflict.Loaded, conflict.Server)
+	}
+	if stored := engine.Get("vms", vm.ID); stored["comment"] != nil {
+		t.Error("Saved the conflicting change", stored["comment"])
+	}
+
+	// Changes of state are not conflicts
+	if err = first.Update(); err != nil {
+		t.Fatal("Error updating the VM", err)
+	}
+	engine.SetStatus("vms", vm.ID, "up")
+	first.Comment = "first"
+	if err = first.Save(ovirtapi.DetectConflicts()); err != nil {
+		t.Error("Detected a conflict on a change of status", err)
+	}
+
+	// Objects not loaded from the server cannot be compared with it
+	detached := &ovirtapi.VM{OvirtObject: ovirtapi.OvirtObject{Con: con, Link: ovirtapi.Link{Href: vm.Href}}}
+	detached.Comment = "detached"
+	if err = detached.Save(ovirtapi.DetectConflicts()); err == nil || ovirtapi.IsConflict(err) {
+		t.Error("Did not fail detecting conflicts on a detached VM", err)
+	}
+	if stored := engine.Get("vms", vm.ID); stored["comment"] == "detached" {
+		t.Error("Saved the detached VM", stored["comment"])
+	}
+
+	// Without the option the last save wins
+	if err = second.Save(); err != nil || second.Comment != "second" || second.Description != "first" {
+		t.Error("Did not save the change without detecting conflicts", err, second.Comment)
+	}
+}
+
+func TestStateIsNotConflict(t *testing.T) {
+	t.Parallel()
+	engine, con, _, vm := newChangesVM(t)
+
+	engine.Set("vms", vm.ID, map[string]interface{}{
+		"fqdn":                   "changes.example.com",
+		"guest_operating_system": map[string]interface{}{"family": "Linux"},
+	})
+	vm.Comment = "state"
+	if err := vm.Save(ovirtapi.DetectConflicts()); err != nil {
+		t.Error("Detected a conflict on the guest agent reports", err)
+	}
+
+	hosts, err := con.GetAllHosts()
+	if err != nil || len(hosts) == 0 {
+		t.Fatal("Error getting a host", err)
+	}
+	engine.Set("hosts", hosts[0].ID, map[string]interface{}{
+		"memory":          "137438953472",
+		"external_status": "error",
+		"summary":         map[string]interface{}{"active": "1", "total": "1"},
+	})
+	hosts[0].Comment = "state"
+	if err = hosts[0].Save(ovirtapi.DetectConflicts()); err != nil {
+		t.Error("Detected a conflict on the state of the host", err)
+	}
+
+	diskID := engine.Add("disks", map[string]interface{}{"name": "state", "provisioned_size": 1073741824, "actual_size": 0})
+	disk, err := con.GetDisk(diskID)
+	if err != nil {
+		t.Fatal("Error getting the disk", err)
+	}
+	engine.Set("disks", diskID, map[string]interface{}{"actual_size": 536870912})
+	engine.SetStatus("disks", diskID, "locked")
+	disk.Comment = "state"
+	if err = disk.Save(ovirtapi.DetectConflicts()); err != nil {
+		t.Error("Detected a conflict on the allocation of the disk", err)
+	}
+
+	// The configuration of the same objects still conflicts
+	engine.Set("disks", diskID, map[string]interface{}{"provisioned_size": 2147483648})
+	disk.Comment = "configuration"
+	if err = disk.Save(ovirtapi.DetectConflicts()); !ovirtapi.IsConflict(err) {
+		t.Error("Did not detect the conflict on the configuration of the disk", err)
+	}
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	t.Parallel()
+	metrics := ovirtapi.NewMetricsRegistry()
+	_, con, _, vm := newChangesVM(t, ovirtapi.WithMetrics(metrics))
+
+	stale, err := con.GetVM(vm.ID)
+	if err != nil {
+		t.Fatal("Error getting the VM", err)
+	}
+	vm.Description = "concurrent"
+	if err = vm.Save(); err != nil {
+		t.Fatal("Error saving the VM", err)
+	}
+	attempts := 0
+	err = con.RetryOnConflict(3, func() error {
+		attempts++
+		if attempts > 1 {
+			if err := stale.Update(); err != nil {
+				return err
+			}
+		}
+		stale.Comment = "retried"
+		return stale.Save(ovirtapi.DetectConflicts())
+	})
+	if err != nil || attempts != 2 {
+		t.Fatal("Did not retry the conflicting save", attempts, err)
+	}
+	if stale.Comment != "retried" || stale.Description != "concurrent" {
+		t.Error("Did not keep both changes", stale.Comment, stale.Description)
+	}
+	if retries := metrics.Retries("PUT", "vms"); retries != 1 {
+		t.Error("Did not count the retry", retries)
+	}
+
+	attempts = 0
+	err = con.RetryOnConflict(2, func() error {
+		attempts++
+		return &ovirtapi.ConflictError{}
+	})
+	if !ovirtapi.IsConflict(err) || attempts != 2 {
+		t.Error("Did not give up after the attempts", attempts, err)
+	}
+
+	attempts = 0
+	err = con.RetryOnConflict(0, func() error {
+		attempts++
+		return nil
+	})
+	if err == nil || attempts != 0 {
+		t.Error("Did not reject a number of attempts lower than 1", attempts, err)
+	}
+}
@@ -8,62 +8,82 @@ package ovirtapi
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"log/slog"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"strings"
+	"time"
 )
 
+// Connection A connection to the engine API. A connection is safe for concurrent
+// use by multiple goroutines once NewConnection returns: its configuration is
+// set by NewConnection and its options and can only be read afterwards, through
+// its getters. WithContext derives a connection for the calls needing a
+// different context.
 type Connection struct {
-	EndPoint       *url.URL
-	UserName       string
-	Password       string
-	Debug          bool
-	Filter         bool
-	Links          []Link `json:"link"`
+	endPoint    *url.URL
+	userName    string
+	password    string
+	debug       bool
+	filter      bool
+	format      Format
+	transport   http.RoundTripper
+	logger      *slog.Logger
+	metrics     Metrics
+	tracer      Tracer
+	root        apiRoot
+	ctx         context.Context
+	limiter     *limiter
+	client      *http.Client
+	debugLogger *slog.Logger
+}
+
+// apiRoot The API root, retrieved by NewConnection
+type apiRoot struct {
+	Links          []Link `json:"link" xml:"link"`
 	SpecialObjects struct {
-		Links []Link `json:"link"`
-	} `json:"special_objects"`
-	ProductInfo struct {
-		Name    string `json:"name"`
-		Vendor  string `json:"vendor"`
-		Version struct {
-			Major       string `json:"major"`
-			Minor       string `json:"minor"`
-			Revision    string `json:"revision"`
-			Build       string `json:"build"`
-			FullVersion string `json:"full_version"`
-		} `json:"version"`
-	} `json:"product_info"`
-	Summary struct {
-		Vms struct {
-			Active int `json:"active,string"`
-			Total  int `json:"total,string"`
-		} `json:"vms"`
-		Hosts struct {
-			Active int `json:"active,string"`
-			Total  int `json:"total,string"`
-		} `json:"hosts"`
-		Users struct {
-			Active int `json:"active,string"`
-			Total  int `json:"total,string"`
-		} `json:"users"`
-		StorageDomains struct {
-			Active int `json:"active,string"`
-			Total  int `json:"total,string"`
-		} `json:"storage_domains"`
-	} `json:"summary"`
+		Links []Link `json:"link" xml:"link"`
+	} `json:"special_objects" xml:"special_objects"`
+	ProductInfo ProductInfo `json:"product_info" xml:"product_info"`
+	Summary     Summary     `json:"summary" xml:"summary"`
+}
+
+// ProductInfo The name, vendor and version of the engine
+type ProductInfo struct {
+	Name    string `json:"name" xml:"name"`
+	Vendor  string `json:"vendor" xml:"vendor"`
+	Version struct {
+		Major       string `json:"major" xml:"major"`
+		Minor       string `json:"minor" xml:"minor"`
+		Revision    string `json:"revision" xml:"revision"`
+		Build       string `json:"build" xml:"build"`
+		FullVersion string `json:"full_version" xml:"full_version"`
+	} `json:"version" xml:"version"`
+}
+
+// SummaryCount The number of active objects and the total number of objects of a kind
+type SummaryCount struct {
+	Active int `json:"active,string" xml:"active"`
+	Total  int `json:"total,string" xml:"total"`
+}
+
+// Summary The number of objects of the engine when the connection was made
+type Summary struct {
+	Vms            SummaryCount `json:"vms" xml:"vms"`
+	Hosts          SummaryCount `json:"hosts" xml:"hosts"`
+	Users          SummaryCount `json:"users" xml:"users"`
+	StorageDomains SummaryCount `json:"storage_domains" xml:"storage_domains"`
 }
 
 type Fault struct {
 	StatusCode int
-	Detail     string `json:"detail"`
-	Reason     string `json:"reason"`
+	Detail     string `json:"detail" xml:"detail"`
+	Reason     string `json:"reason" xml:"reason"`
 }
 
 func (f Fault) Error() string {
@@ -73,69 +93,211 @@ func (f Fault) Error() string {
 	return fmt.Sprintf("Error getting response from server (Response code %d )", f.StatusCode)
 }
 
-func NewConnection(endpoint string, username string, password string, debug bool) (*Connection, error) {
+// ConnectionOption Configures a connection before the first request is made
+type ConnectionOption func(con *Connection)
+
+// WithTransport Sends the requests of the connection through the transport
+func WithTransport(transport http.RoundTripper) ConnectionOption {
+	return func(con *Connection) {
+		con.transport = transport
+	}
+}
+
+// WithFilter Chooses the mode of the connection. In the filtered mode, the
+// default of NewConnection when this option is not given, the engine returns
+// only the objects the user has been given permissions on. In the admin mode,
+// when filter is false, the engine returns every object and requires the user
+// to have an administrative role.
+func WithFilter(filter bool) ConnectionOption {
+	return func(con *Connection) {
+		con.filter = filter
+	}
+}
+
+type filterKey struct{}
+
+// ContextWithFilter Returns a context overriding the mode of the connection the requests are made with
+func ContextWithFilter(ctx context.Context, filter bool) context.Context {
+	return context.WithValue(ctx, filterKey{}, filter)
+}
+
+// contextFilter The mode carried by ctx, ok is false when ctx does not override the mode
+func contextFilter(ctx context.Context) (filter bool, ok bool) {
+	filter, ok = ctx.Value(filterKey{}).(bool)
+	return
+}
+
+// NewConnection Connects to the API root at endpoint with the credentials of
+// the user. Without a WithFilter option the connection is in the filtered
+// mode, the mode every user is allowed to use: administrators must pass
+// WithFilter(false) to see the objects they have no explicit permission on.
+// ContextWithFilter overrides the mode of the requests made within a context.
+func NewConnection(endpoint string, username string, password string, debug bool, options ...ConnectionOption) (*Connection, error) {
 	endpointURL, err := url.Parse(endpoint)
 	if err != nil {
 		return nil, errors.New("Error parsing endpoint URL")
 	}
 	con := &Connection{
-		EndPoint: endpointURL,
-		UserName: username,
-		Password: password,
-		Debug:    debug,
-		Filter:   true,
+		endPoint: endpointURL,
+		userName: username,
+		password: password,
+		debug:    debug,
+		filter:   true,
+	}
+	for _, option := range options {
+		option(con)
+	}
+	// The client and its transport are shared by the requests of every goroutine
+	con.client = &http.Client{Transport: con.transport}
+	if con.debug {
+		con.debugLogger = slog.New(slog.NewTextHandler(log.Writer(), &slog.HandlerOptions{Level: slog.LevelDebug}))
 	}
 	body, err := con.Request("GET", endpointURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return con, json.Unmarshal(body, &con)
+	return con, con.decode(body, &con.root)
+}
+
+// EndPoint The URL of the API root
+func (con *Connection) EndPoint() *url.URL {
+	endPoint := *con.endPoint
+	return &endPoint
+}
+
+// UserName The user the connection is logged in with
+func (con *Connection) UserName() string {
+	return con.userName
+}
+
+// Debug Whether the requests are logged to the standard logger
+func (con *Connection) Debug() bool {
+	return con.debug
+}
+
+// Filter Whether the requests are made in the filtered mode, see WithFilter
+func (con *Connection) Filter() bool {
+	return con.filter
+}
+
+// Format The format of the bodies of the requests, see WithFormat
+func (con *Connection) Format() Format {
+	return con.format
+}
+
+// Transport The transport the requests are sent through, nil for the default transport
+func (con *Connection) Transport() http.RoundTripper {
+	return con.transport
+}
+
+// Logger The logger set with WithLogger, nil when there is none
+func (con *Connection) Logger() *slog.Logger {
+	return con.logger
+}
+
+// Metrics The metrics set with WithMetrics, nil when there are none
+func (con *Connection) Metrics() Metrics {
+	return con.metrics
+}
+
+// Tracer The tracer set with WithTracer, nil when there is none
+func (con *Connection) Tracer() Tracer {
+	return con.tracer
+}
+
+// Links The links of the API root to the collections of the engine
+func (con *Connection) Links() []Link {
+	return append([]Link{}, con.root.Links...)
+}
+
+// SpecialObjects The links of the API root to the special objects of the engine, such as the blank template
+func (con *Connection) SpecialObjects() []Link {
+	return append([]Link{}, con.root.SpecialObjects.Links...)
+}
+
+// ProductInfo The name, vendor and version of the engine
+func (con *Connection) ProductInfo() ProductInfo {
+	return con.root.ProductInfo
+}
+
+// Summary The number of objects of the engine when the connection was made
+func (con *Connection) Summary() Summary {
+	return con.root.Summary
 }
 
 func (con *Connection) ResolveLink(link string) *url.URL {
-	return con.EndPoint.ResolveReference(&url.URL{Path: link})
+	return con.endPoint.ResolveReference(&url.URL{Path: link})
 }
 
 func (con *Connection) Request(verb string, requestURL *url.URL, reqBody []byte) ([]byte, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest(verb, requestURL.String(), bytes.NewBuffer(reqBody))
+	con, span := con.startSpan("ovirt.request", con.requestAttributes(verb, requestURL)...)
+	respBody, err := con.send(verb, requestURL, reqBody, span)
+	span.End(err)
+	return respBody, err
+}
+
+// send Sends a request within the context of the connection
+func (con *Connection) send(verb string, requestURL *url.URL, reqBody []byte, span Span) ([]byte, error) {
+	client := con.client
+	if client == nil {
+		client = &http.Client{Transport: con.transport}
+	}
+	requestURL = correlate(con.Context(), requestURL)
+	req, err := http.NewRequestWithContext(con.Context(), verb, requestURL.String(), bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, err
 	}
 	if reqBody != nil {
-		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Content-Type", con.format.contentType())
 	}
-	if con.Filter {
+	if filter, ok := contextFilter(con.Context()); ok && filter || !ok && con.filter {
 		req.Header.Add("Filter", "true")
 	}
-	req.Header.Add("Accept", "application/json")
-	req.SetBasicAuth(con.UserName, con.Password)
-	if con.Debug {
-		dump, _ := httputil.DumpRequestOut(req, true)
-		log.Println(">", strings.Replace(strings.Replace(string(dump), "\r\n", "\n", -1), "\n", "\n> ", -1))
+	req.Header.Add("Accept", con.format.contentType())
+	req.Header.Add("Version", APIVersion)
+	req.SetBasicAuth(con.userName, con.password)
+	release, err := con.limiter.acquire(con.Context(), RequestPriority(con.Context()))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	logger := con.requestLogger()
+	requestID := newRequestID()
+	if logger != nil {
+		logRequest(logger, requestID, req, reqBody)
 	}
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
+		if logger != nil {
+			logFailure(logger, requestID, req, err, slog.Duration("latency", time.Since(start)))
+		}
+		if con.metrics != nil {
+			con.metrics.ObserveRequest(verb, con.resourceName(requestURL), 0, time.Since(start))
+		}
 		return nil, err
 	}
-	if con.Debug {
-		dump, _ := httputil.DumpResponse(resp, true)
-		log.Println("<", strings.Replace(strings.Replace(string(dump), "\r\n", "\n", -1), "\n", "\n< ", -1))
-	}
 	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	latency := time.Since(start)
+	span.SetAttributes(statusAttribute(resp.StatusCode))
+	if logger != nil {
+		logResponse(logger, requestID, resp, respBody, slog.Duration("latency", latency))
+	}
+	if con.metrics != nil {
+		con.metrics.ObserveRequest(verb, con.resourceName(requestURL), resp.StatusCode, latency)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		fault := Fault{resp.StatusCode, "", ""}
-		respBody, err := ioutil.ReadAll(resp.Body)
 		if err == nil {
-			json.Unmarshal(respBody, &fault)
-			if fault.Reason == "" {
-				json.Unmarshal(respBody, &Action{Fault: &fault})
-			}
+			decodeFault(resp.Header.Get("Content-Type"), respBody, &fault)
+		}
+		if con.metrics != nil {
+			con.metrics.ObserveFault(verb, con.resourceName(requestURL), fault)
 		}
 		return nil, fault
 	}
-	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +305,7 @@ func (con *Connection) Request(verb string, requestURL *url.URL, reqBody []byte)
 }
 
 func (con *Connection) GetLink(rel string) (*url.URL, error) {
-	for _, link := range con.Links {
+	for _, link := range con.root.Links {
 		if strings.ToLower(rel) == link.Rel {
 			return con.ResolveLink(link.Href), nil
 		}
@@ -166,3 +328,19 @@ func (con *Connection) GetLinkBody(link string, id string) ([]byte, error) {
 	}
 	return body, nil
 }
+
+// SearchValue Quotes a value of an oVirt search query, such as a name with
+// spaces or search operators, escaping its quotes and backslashes
+func SearchValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// SearchLinkBody Retrieve the collection behind the link, filtered with the oVirt search query
+func (con *Connection) SearchLinkBody(link string, query string) ([]byte, error) {
+	href, err := con.GetLink(link)
+	if err != nil {
+		return nil, err
+	}
+	href.RawQuery = url.Values{"search": {query}}.Encode()
+	return con.Request("GET", href, nil)
+}
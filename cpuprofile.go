@@ -0,0 +1,155 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CPUProfile Represents a CPU profile, which applies a cpu QoS to the virtual machines of a cluster.
+type CPUProfile struct {
+	OvirtObject
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// The cluster the profile belongs to.
+	Cluster *Link `json:"cluster,omitempty" xml:"cluster,omitempty"`
+	// The cpu QoS applied by the profile.
+	QoS *QoS `json:"qos,omitempty" xml:"qos,omitempty"`
+}
+
+// GetCPUProfiles Retrieve the CPU profiles of the cluster
+func (cluster *Cluster) GetCPUProfiles() ([]*CPUProfile, error) {
+	linkResp, err := cluster.getLinkResponse("cpuprofiles", nil)
+	if err != nil {
+		return nil, err
+	}
+	profiles := []*CPUProfile{}
+	for i := range linkResp.CPUProfile {
+		profile := &linkResp.CPUProfile[i]
+		profile.Con = cluster.Con
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// NewCPUProfile Create a new CPU profile structure belonging to the cluster
+func (cluster *Cluster) NewCPUProfile() *CPUProfile {
+	profile := cluster.Con.NewCPUProfile()
+	profile.Cluster = &Link{ID: cluster.ID}
+	return profile
+}
+
+// SetCPUProfile Assigns the CPU profile with the given name, from the cluster of the VM, the change is sent to the server on Save
+func (vm *VM) SetCPUProfile(name string) error {
+	if vm.Cluster == nil || vm.Cluster.ID == "" {
+		return errors.New("VM is not associated with a cluster")
+	}
+	profiles, err := vm.Con.GetAllCPUProfiles()
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		if profile.Name == name && profile.Cluster != nil && profile.Cluster.ID == vm.Cluster.ID {
+			vm.CPUProfile = &CPUProfile{OvirtObject: OvirtObject{Link: Link{ID: profile.ID}}}
+			return nil
+		}
+	}
+	return fmt.Errorf("CPU profile %s not found in the cluster of the VM", name)
+}
+
+// GetCPUProfile retrieve a CPU profile from the server
+func (con *Connection) GetCPUProfile(id string) (*CPUProfile, error) {
+	body, err := con.GetLinkBody("cpuprofiles", id)
+	if err != nil {
+		return nil, err
+	}
+	profile := con.NewCPUProfile()
+	err = con.decode(body, profile)
+	if err != nil {
+		return nil, err
+	}
+	return profile, err
+}
+
+// Update Synchronize the local CPU profile with a copy from the server
+func (profile *CPUProfile) Update() error {
+	if profile.Href == "" {
+		return fmt.Errorf("CPU profile has not been saved to the server")
+	}
+	body, err := profile.Con.Request("GET", profile.Con.ResolveLink(profile.Href), nil)
+	if err != nil {
+		return err
+	}
+	tempProfile := CPUProfile{OvirtObject: OvirtObject{Con: profile.Con}}
+	err = profile.Con.decode(body, &tempProfile)
+	if err != nil {
+		return err
+	}
+	*profile = tempProfile
+	return nil
+}
+
+// GetAllCPUProfiles Retrieve all the CPU profiles from the server
+func (con *Connection) GetAllCPUProfiles() ([]*CPUProfile, error) {
+	body, err := con.GetLinkBody("cpuprofiles", "")
+	if err != nil {
+		return nil, err
+	}
+	profiles := []*CPUProfile{}
+	err = con.decodeList(body, &profiles)
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		profile.Con = con
+	}
+	return profiles, err
+}
+
+// NewCPUProfile Create a new CPU profile structure
+func (con *Connection) NewCPUProfile() *CPUProfile {
+	return &CPUProfile{OvirtObject: OvirtObject{Con: con}}
+}
+
+// Save Updates the server with the local copy of the CPU profile, sending only the fields
+// modified since it was loaded
+func (profile *CPUProfile) Save(options ...SaveOption) error {
+	body, err := profile.Con.encodeChanges(profile)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already saved CPU profile, we need to update it
+	if profile.Href != "" {
+		body, err = profile.Con.put(profile, profile.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		link, err := profile.Con.GetLink("cpuprofiles")
+		if err != nil {
+			return err
+		}
+		body, err = profile.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempProfile := CPUProfile{OvirtObject: OvirtObject{Con: profile.Con}}
+	err = profile.Con.decode(body, &tempProfile)
+	if err != nil {
+		return err
+	}
+	*profile = tempProfile
+	return nil
+}
+
+// Patch Updates the server with the fields of the CPU profile named by their JSON
+// path, such as "description" or "qos.id", whether they were modified or not
+func (profile *CPUProfile) Patch(fields ...string) error {
+	return profile.Con.patch(profile, fields)
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"testing"
+)
+
+func TestCPUProfile(t *testing.T) {
+	t.Parallel()
+	con := newTestConnection(t)
+	allClusters, err := con.GetAllClusters()
+	if err != nil || len(allClusters) == 0 {
+		t.Fatal("Error finding a Cluster for the CPU profile", err)
+	}
+	newProfile := allClusters[0].NewCPUProfile()
+	newProfile.Name = "test-cpu-profile"
+	err = newProfile.Save()
+	if err != nil {
+		t.Fatal("Error creating new CPU profile", err)
+	}
+	clusterProfiles, err := allClusters[0].GetCPUProfiles()
+	if err != nil {
+		t.Fatal("Error retrieving cluster CPU profiles", err)
+	}
+	found := false
+	for _, profile := range clusterProfiles {
+		found = found || profile.ID == newProfile.ID
+	}
+	if !found {
+		t.Error("Cluster does not list the new CPU profile")
+	}
+	newVM := con.NewVM()
+	newVM.Cluster = allClusters[0]
+	err = newVM.SetCPUProfile(newProfile.Name)
+	if err != nil || newVM.CPUProfile.ID != newProfile.ID {
+		t.Error("Error assigning CPU profile by name", err)
+	}
+	retrievedProfile, err := con.GetCPUProfile(newProfile.ID)
+	if err != nil {
+		t.Fatal("Error retrieving CPU profile", err)
+	}
+	retrievedProfile.Description = "about to delete"
+	err = retrievedProfile.Save()
+	if err != nil {
+		t.Fatal("Error updating CPU profile", err)
+	}
+	err = retrievedProfile.Delete()
+	if err != nil {
+		t.Fatal("Error Deleting CPU profile", err)
+	}
+}
@@ -8,18 +8,18 @@ package ovirtapi
 
 type DataCenter struct {
 	OvirtObject
-	Local             string `json:"local,omitempty"`
-	QuotaMode         string `json:"quota_mode,omitempty"`
-	Status            string `json:"status,omitempty"`
-	StorageFormat     string `json:"storage_format,omitempty"`
+	Local             string `json:"local,omitempty" xml:"local,omitempty"`
+	QuotaMode         string `json:"quota_mode,omitempty" xml:"quota_mode,omitempty"`
+	Status            string `json:"status,omitempty" xml:"status,omitempty"`
+	StorageFormat     string `json:"storage_format,omitempty" xml:"storage_format,omitempty"`
 	SupportedVersions *struct {
 		Version []struct {
-			Major string `json:"major,omitempty"`
-			Minor string `json:"minor,omitempty"`
-		} `json:"version,omitempty"`
-	} `json:"supported_versions,omitempty"`
+			Major string `json:"major,omitempty" xml:"major,omitempty"`
+			Minor string `json:"minor,omitempty" xml:"minor,omitempty"`
+		} `json:"version,omitempty" xml:"version,omitempty"`
+	} `json:"supported_versions,omitempty" xml:"supported_versions,omitempty"`
 	Version *struct {
-		Major string `json:"major,omitempty"`
-		Minor string `json:"minor,omitempty"`
-	} `json:"version,omitempty"`
+		Major string `json:"major,omitempty" xml:"major,omitempty"`
+		Minor string `json:"minor,omitempty" xml:"minor,omitempty"`
+	} `json:"version,omitempty" xml:"version,omitempty"`
 }
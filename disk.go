@@ -7,138 +7,137 @@
 package ovirtapi
 
 import (
-	"encoding/json"
 	"fmt"
 )
 
 type VolumeGroup struct {
-	id            string        `json:"id,omitempty"`
-	logical_units []LogicalUnit `json:"logical_units,omitempty"`
-	name          string        `json:"name,omitempty"`
+	id            string        `json:"id,omitempty" xml:"id,attr,omitempty"`
+	logical_units []LogicalUnit `json:"logical_units,omitempty" xml:"logical_units,omitempty"`
+	name          string        `json:"name,omitempty" xml:"name,omitempty"`
 }
 
 type LogicalUnit struct {
-	Address        string `json:"address,omitempty"`
-	DiscardMaxSize int    `json:"discard_max_size,omitempty"`
+	Address        string `json:"address,omitempty" xml:"address,omitempty"`
+	DiscardMaxSize int    `json:"discard_max_size,omitempty" xml:"discard_max_size,omitempty"`
 	// The maximum number of bytes that can be discarded by the logical unit's underlying storage in a single operation.
-	DiscardZeroesData string `json:"discard_zeroes_data,omitempty"`
+	DiscardZeroesData string `json:"discard_zeroes_data,omitempty" xml:"discard_zeroes_data,omitempty"`
 	// True, if previously discarded blocks in the logical unit's underlying storage are read back as zeros.
-	DiskID          string `json:"disk_id,omitempty"`
-	ID              string `json:"id,omitempty"`
-	LUNMapping      int    `json:"lun_mapping,omitempty,string"`
-	Password        string `json:"password,omitempty"`
-	Paths           int    `json:"paths,omitempty,string"`
-	Port            int    `json:"port,omitempty,string"`
-	Portal          string `json:"portal,omitempty"`
-	ProductID       string `json:"product_id,omitempty"`
-	Serial          string `json:"serial,omitempty"`
-	Size            int    `json:"size,omitempty"`
-	Status          string `json:"status,omitempty"`
-	StorageDomainID string `json:"storage_domain_id,omitempty"`
-	Target          string `json:"target,omitempty"`
-	Username        string `json:"username,omitempty"`
-	VendorID        string `json:"vendor_id,omitempty"`
-	VolumeGroupID   string `json:"volume_group_id,omitempty"`
+	DiskID          string `json:"disk_id,omitempty" xml:"disk_id,omitempty"`
+	ID              string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	LUNMapping      int    `json:"lun_mapping,omitempty,string" xml:"lun_mapping,omitempty"`
+	Password        string `json:"password,omitempty" xml:"password,omitempty"`
+	Paths           int    `json:"paths,omitempty,string" xml:"paths,omitempty"`
+	Port            int    `json:"port,omitempty,string" xml:"port,omitempty"`
+	Portal          string `json:"portal,omitempty" xml:"portal,omitempty"`
+	ProductID       string `json:"product_id,omitempty" xml:"product_id,omitempty"`
+	Serial          string `json:"serial,omitempty" xml:"serial,omitempty"`
+	Size            int    `json:"size,omitempty" xml:"size,omitempty"`
+	Status          string `json:"status,omitempty" xml:"status,omitempty"`
+	StorageDomainID string `json:"storage_domain_id,omitempty" xml:"storage_domain_id,omitempty"`
+	Target          string `json:"target,omitempty" xml:"target,omitempty"`
+	Username        string `json:"username,omitempty" xml:"username,omitempty"`
+	VendorID        string `json:"vendor_id,omitempty" xml:"vendor_id,omitempty"`
+	VolumeGroupID   string `json:"volume_group_id,omitempty" xml:"volume_group_id,omitempty"`
 }
 
 type HostStorage struct {
-	Address string `json:"address,omitempty"`
+	Address string `json:"address,omitempty" xml:"address,omitempty"`
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
 	// A unique identifier.
-	ID           string        `json:"id,omitempty"`
-	LogicalUnits []LogicalUnit `json:"logical_units,omitempty"`
-	MountOptions string        `json:"mount_options,omitempty"`
+	ID           string        `json:"id,omitempty" xml:"id,attr,omitempty"`
+	LogicalUnits []LogicalUnit `json:"logical_units>logical_unit,omitempty" xml:"logical_units>logical_unit,omitempty"`
+	MountOptions string        `json:"mount_options,omitempty" xml:"mount_options,omitempty"`
 	// A human-readable name in plain text.
-	Name string `json:"name,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
 	// The number of times to retry a request before attempting further recovery actions.
-	NfsRetrans int `json:"nfs_retrans,omitempty,string"`
+	NfsRetrans int `json:"nfs_retrans,omitempty,string" xml:"nfs_retrans,omitempty"`
 	// The time in tenths of a second to wait for a response before retrying NFS requests.
-	NfsTimeo     int          `json:"nfs_timeo,omitempty,string"`
-	NfsVersion   string       `json:"nfs_version,omitempty"`
-	OverrideLUNS string       `json:"override_luns,omitempty"`
-	Password     string       `json:"password,omitempty"`
-	Path         string       `json:"path,omitempty"`
-	Port         int          `json:"port,omitempty,string"`
-	Portal       string       `json:"portal,omitempty"`
-	Target       string       `json:"target,omitempty"`
-	Type         string       `json:"type,omitempty"`
-	Username     string       `json:"username,omitempty"`
-	VfsType      string       `json:"vfs_type,omitempty"`
-	VolumeGroup  *VolumeGroup `json:"volume_group,omitempty"`
+	NfsTimeo     int          `json:"nfs_timeo,omitempty,string" xml:"nfs_timeo,omitempty"`
+	NfsVersion   string       `json:"nfs_version,omitempty" xml:"nfs_version,omitempty"`
+	OverrideLUNS string       `json:"override_luns,omitempty" xml:"override_luns,omitempty"`
+	Password     string       `json:"password,omitempty" xml:"password,omitempty"`
+	Path         string       `json:"path,omitempty" xml:"path,omitempty"`
+	Port         int          `json:"port,omitempty,string" xml:"port,omitempty"`
+	Portal       string       `json:"portal,omitempty" xml:"portal,omitempty"`
+	Target       string       `json:"target,omitempty" xml:"target,omitempty"`
+	Type         string       `json:"type,omitempty" xml:"type,omitempty"`
+	Username     string       `json:"username,omitempty" xml:"username,omitempty"`
+	VfsType      string       `json:"vfs_type,omitempty" xml:"vfs_type,omitempty"`
+	VolumeGroup  *VolumeGroup `json:"volume_group,omitempty" xml:"volume_group,omitempty"`
 }
 
 type StorageDomains struct {
 	//TODO make StorageDomain
-	StorageDomain []Link `json:"storage_domain,omitempty"`
+	StorageDomain []Link `json:"storage_domain,omitempty" xml:"storage_domain,omitempty"`
 }
 
 type Disk struct {
 	OvirtObject
 	//  Indicates if the disk is visible to the virtual machine.
-	Active string `json:"active,omitempty"`
+	Active string `json:"active,omitempty" xml:"active,omitempty"`
 	//  The actual size of the disk, in bytes.
-	ActualSize int    `json:"actual_size,omitempty"`
-	Alias      string `json:"alias,omitempty"`
+	ActualSize int    `json:"actual_size,omitempty" xml:"actual_size,omitempty"`
+	Alias      string `json:"alias,omitempty" xml:"alias,omitempty"`
 	//  Indicates if the disk is marked as bootable.
-	Bootable string `json:"bootable,omitempty"`
+	Bootable string `json:"bootable,omitempty" xml:"bootable,omitempty"`
 	//  Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	//  The underlying storage format.
-	Format  string `json:"format,omitempty"`
-	ImageID string `json:"image_id,omitempty"`
+	Format  string `json:"format,omitempty" xml:"format,omitempty"`
+	ImageID string `json:"image_id,omitempty" xml:"image_id,omitempty"`
 	//  The initial size of a sparse image disk created on block storage, in bytes.
-	InitialSize int `json:"initial_size,omitempty"`
+	InitialSize int `json:"initial_size,omitempty" xml:"initial_size,omitempty"`
 	//  The type of interface driver used to connect the disk device to the virtual machine.
-	Interface   string       `json:"interface,omitempty"`
-	LogicalName string       `json:"logical_name,omitempty"`
-	LunStorage  *HostStorage `json:"lun_storage,omitempty"`
+	Interface   string       `json:"interface,omitempty" xml:"interface,omitempty"`
+	LogicalName string       `json:"logical_name,omitempty" xml:"logical_name,omitempty"`
+	LunStorage  *HostStorage `json:"lun_storage,omitempty" xml:"lun_storage,omitempty"`
 	//  Indicates if disk errors should cause virtual machine to be paused or if disk errors should be propagated to the the guest operating system instead.
-	PropagateErrors string `json:"propagate_errors,omitempty"`
+	PropagateErrors string `json:"propagate_errors,omitempty" xml:"propagate_errors,omitempty"`
 	//  The virtual size of the disk, in bytes.
-	ProvisionedSize int `json:"provisioned_size,omitempty"`
+	ProvisionedSize int `json:"provisioned_size,omitempty" xml:"provisioned_size,omitempty"`
 	//  The underlying QCOW version of a QCOW volume.
-	QcowVersion string `json:"qcow_version,omitempty"`
+	QcowVersion string `json:"qcow_version,omitempty" xml:"qcow_version,omitempty"`
 	//  Indicates if the disk is in read-only mode.
-	ReadOnly string `json:"read_only,omitempty"`
-	SGIO     string `json:"sgio,omitempty"`
+	ReadOnly string `json:"read_only,omitempty" xml:"read_only,omitempty"`
+	SGIO     string `json:"sgio,omitempty" xml:"sgio,omitempty"`
 	//  Indicates if the disk can be attached to multiple virtual machines.
-	Shareable string `json:"shareable,omitempty"`
+	Shareable string `json:"shareable,omitempty" xml:"shareable,omitempty"`
 	//  Indicates if the physical storage for the disk should not be preallocated.
-	Sparse string `json:"sparse,omitempty"`
+	Sparse string `json:"sparse,omitempty" xml:"sparse,omitempty"`
 	//  The status of the disk device.
-	Status              string `json:"status,omitempty"`
-	StorageType         string `json:"storage_type,omitempty"`
-	UsesSCSIReservation string `json:"uses_scsi_reservation,omitempty"`
+	Status              string `json:"status,omitempty" xml:"status,omitempty"`
+	StorageType         string `json:"storage_type,omitempty" xml:"storage_type,omitempty"`
+	UsesSCSIReservation string `json:"uses_scsi_reservation,omitempty" xml:"uses_scsi_reservation,omitempty"`
 	// Indicates if the disk's blocks will be read back as zeros after it is deleted:
 	//
 	// - On block storage, the disk will be zeroed and only then deleted.
-	WipeAfterDelete string `json:"wipe_after_delete,omitempty"`
-	// TODO Make DiskProfile
-	DiskProfile *Link `json:"disk_profile,omitempty"`
+	WipeAfterDelete string `json:"wipe_after_delete,omitempty" xml:"wipe_after_delete,omitempty"`
+	// The disk profile applied to the disk.
+	DiskProfile *DiskProfile `json:"disk_profile,omitempty" xml:"disk_profile,omitempty"`
 	// Optionally references to an instance type the device is used by.
 	// TODO Make InstanceType
-	InstanceType *Link `json:"instance_type,omitempty"`
+	InstanceType *Link `json:"instance_type,omitempty" xml:"instance_type,omitempty"`
 	// TODO Make OpenStackVolumeType
-	OpenstackVolumeType *Link `json:"openstack_volume_type,omitempty"`
+	OpenstackVolumeType *Link `json:"openstack_volume_type,omitempty" xml:"openstack_volume_type,omitempty"`
 	// TODO Make Permission
-	Permissions []Link `json:"permissions,omitempty"`
+	Permissions []Link `json:"permissions>permission,omitempty" xml:"permissions>permission,omitempty"`
 	// TODO Make Quota
-	Quota *Link `json:"quota,omitempty"`
+	Quota *Link `json:"quota,omitempty" xml:"quota,omitempty"`
 	// TODO Make Snapshot
-	Snapshot *Link `json:"snapshot,omitempty"`
+	Snapshot *Link `json:"snapshot,omitempty" xml:"snapshot,omitempty"`
 	// Statistics exposed by the disk.
 	// TODO Make Statistic
-	Statistics []Link `json:"statistics,omitempty"`
+	Statistics []Link `json:"statistics>statistic,omitempty" xml:"statistics>statistic,omitempty"`
 	// The storage domains associated with this disk.
 	// TODO Make StorageDomain
-	StorageDomains *StorageDomains `json:"storage_domains,omitempty"`
+	StorageDomains *StorageDomains `json:"storage_domains,omitempty" xml:"storage_domains,omitempty"`
 	// Optionally references to a template the device is used by.
-	Template *Template `json:"template,omitempty"`
+	Template *Template `json:"template,omitempty" xml:"template,omitempty"`
 	// References to the virtual machines that are using this device.
-	VMs []VM `json:"vms,omitempty"`
+	VMs []VM `json:"vms>vm,omitempty" xml:"vms>vm,omitempty"`
 }
 
 func (con *Connection) GetDisk(id string) (*Disk, error) {
@@ -147,7 +146,7 @@ func (con *Connection) GetDisk(id string) (*Disk, error) {
 		return nil, err
 	}
 	disk := con.NewDisk()
-	err = json.Unmarshal(body, disk)
+	err = con.decode(body, disk)
 	if err != nil {
 		return nil, err
 	}
@@ -173,9 +172,7 @@ func (con *Connection) GetAllDisks() ([]*Disk, error) {
 		return nil, err
 	}
 	disks := []*Disk{}
-	err = json.Unmarshal(body, &struct {
-		Disk *[]*Disk
-	}{&disks})
+	err = con.decodeList(body, &disks)
 	if err != nil {
 		return nil, err
 	}
@@ -189,14 +186,14 @@ func (con *Connection) NewDisk() *Disk {
 	return &Disk{OvirtObject: OvirtObject{Con: con}}
 }
 
-func (disk *Disk) Save() error {
-	body, err := json.MarshalIndent(disk, "", "    ")
+func (disk *Disk) Save(options ...SaveOption) error {
+	body, err := disk.Con.encodeChanges(disk)
 	if err != nil {
 		return err
 	}
 	// If there is a link, it is an already saved disk, we need to update it
 	if disk.OvirtObject.Href != "" {
-		body, err = disk.Con.Request("PUT", disk.Con.ResolveLink(disk.Href), body)
+		body, err = disk.Con.put(disk, disk.Href, body, options)
 		if err != nil {
 			return err
 		}
@@ -211,7 +208,7 @@ func (disk *Disk) Save() error {
 		}
 	}
 	tempDisk := Disk{OvirtObject: OvirtObject{Con: disk.Con}}
-	err = json.Unmarshal(body, &tempDisk)
+	err = disk.Con.decode(body, &tempDisk)
 	if err != nil {
 		return err
 	}
@@ -219,6 +216,12 @@ func (disk *Disk) Save() error {
 	return nil
 }
 
+// Patch Updates the server with the fields of the disk named by their JSON
+// path, such as "alias" or "provisioned_size", whether they were modified or not
+func (disk *Disk) Patch(fields ...string) error {
+	return disk.Con.patch(disk, fields)
+}
+
 // // Copy This operation copies a disk to the specified storage domain.
 // func (vm *VM) Copy(async string, disk *Disk, filter string, storageDomain *StorageDomain) error {
 // 	return vm.DoAction("copy", Action{
@@ -7,8 +7,6 @@
 package ovirtapi_test
 
 import (
-	"os"
-	"strconv"
 	"testing"
 	"time"
 
@@ -17,27 +15,7 @@ import (
 
 func TestDisk(t *testing.T) {
 	t.Parallel()
-	username := os.Getenv("OVIRT_USERNAME")
-	if username == "" {
-		t.Error("OVIRT_USERNAME is not set")
-		return
-	}
-	password := os.Getenv("OVIRT_PASSWORD")
-	if password == "" {
-		t.Error("OVIRT_PASSWORD is not set")
-		return
-	}
-	url := os.Getenv("OVIRT_URL")
-	if url == "" {
-		t.Error("OVIRT_URL is not set")
-		return
-	}
-	debug, _ := strconv.ParseBool(os.Getenv("DEBUG_TRANSPORT"))
-	con, err := ovirtapi.NewConnection(url, username, password, debug)
-	if err != nil {
-		t.Error("error creating connection", err)
-		return
-	}
+	con := newTestConnection(t)
 	newDisk := con.NewDisk()
 	newDisk.ProvisionedSize = 1024
 	newDisk.Format = "cow"
@@ -47,7 +25,7 @@ func TestDisk(t *testing.T) {
 		ID: "dfe8e7be-e495-49a7-be2d-71aba891ceb4",
 	})
 	newDisk.StorageDomains = &storageDomains
-	err = newDisk.Save()
+	err := newDisk.Save()
 	if err != nil {
 		t.Error("Error creating new disk", err)
 		return
@@ -0,0 +1,178 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// DiskProfile Represents a disk profile, which applies a storage QoS to the disks of a storage domain.
+type DiskProfile struct {
+	OvirtObject
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// The storage QoS applied by the profile.
+	QoS *QoS `json:"qos,omitempty" xml:"qos,omitempty"`
+	// The storage domain the profile belongs to.
+	StorageDomain *Link `json:"storage_domain,omitempty" xml:"storage_domain,omitempty"`
+}
+
+// storageDomainDiskProfiles The link to the disk profiles of the storage domain with the given id
+func (con *Connection) storageDomainDiskProfiles(storageDomainID string) (*url.URL, error) {
+	if storageDomainID == "" {
+		return nil, errors.New("Disk profile is not associated with a storage domain")
+	}
+	link, err := con.GetLink("storagedomains")
+	if err != nil {
+		return nil, err
+	}
+	link.Path += "/" + storageDomainID + "/diskprofiles"
+	return link, nil
+}
+
+// GetStorageDomainDiskProfiles Retrieve the disk profiles of the storage domain with the given id
+func (con *Connection) GetStorageDomainDiskProfiles(storageDomainID string) ([]*DiskProfile, error) {
+	link, err := con.storageDomainDiskProfiles(storageDomainID)
+	if err != nil {
+		return nil, err
+	}
+	body, err := con.Request("GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	profiles := []*DiskProfile{}
+	err = con.decodeList(body, &profiles)
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		profile.Con = con
+	}
+	return profiles, nil
+}
+
+// NewStorageDomainDiskProfile Create a new disk profile structure belonging to the storage domain with the given id
+func (con *Connection) NewStorageDomainDiskProfile(storageDomainID string) *DiskProfile {
+	profile := con.NewDiskProfile()
+	profile.StorageDomain = &Link{ID: storageDomainID}
+	return profile
+}
+
+// SetDiskProfile Assigns the disk profile with the given name, from the storage domain of the disk, the change is sent to the server on Save
+func (disk *Disk) SetDiskProfile(name string) error {
+	if disk.StorageDomains == nil || len(disk.StorageDomains.StorageDomain) == 0 {
+		return errors.New("Disk is not associated with a storage domain")
+	}
+	profiles, err := disk.Con.GetStorageDomainDiskProfiles(disk.StorageDomains.StorageDomain[0].ID)
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		if profile.Name == name {
+			disk.DiskProfile = &DiskProfile{OvirtObject: OvirtObject{Link: Link{ID: profile.ID}}}
+			return nil
+		}
+	}
+	return fmt.Errorf("Disk profile %s not found in the storage domain of the disk", name)
+}
+
+// GetDiskProfile retrieve a disk profile from the server
+func (con *Connection) GetDiskProfile(id string) (*DiskProfile, error) {
+	body, err := con.GetLinkBody("diskprofiles", id)
+	if err != nil {
+		return nil, err
+	}
+	profile := con.NewDiskProfile()
+	err = con.decode(body, profile)
+	if err != nil {
+		return nil, err
+	}
+	return profile, err
+}
+
+// Update Synchronize the local disk profile with a copy from the server
+func (profile *DiskProfile) Update() error {
+	if profile.Href == "" {
+		return fmt.Errorf("disk profile has not been saved to the server")
+	}
+	body, err := profile.Con.Request("GET", profile.Con.ResolveLink(profile.Href), nil)
+	if err != nil {
+		return err
+	}
+	tempProfile := DiskProfile{OvirtObject: OvirtObject{Con: profile.Con}}
+	err = profile.Con.decode(body, &tempProfile)
+	if err != nil {
+		return err
+	}
+	*profile = tempProfile
+	return nil
+}
+
+// GetAllDiskProfiles Retrieve all the disk profiles from the server
+func (con *Connection) GetAllDiskProfiles() ([]*DiskProfile, error) {
+	body, err := con.GetLinkBody("diskprofiles", "")
+	if err != nil {
+		return nil, err
+	}
+	profiles := []*DiskProfile{}
+	err = con.decodeList(body, &profiles)
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		profile.Con = con
+	}
+	return profiles, err
+}
+
+// NewDiskProfile Create a new disk profile structure
+func (con *Connection) NewDiskProfile() *DiskProfile {
+	return &DiskProfile{OvirtObject: OvirtObject{Con: con}}
+}
+
+// Save Updates the server with the local copy of the disk profile, sending only the fields
+// modified since it was loaded
+func (profile *DiskProfile) Save(options ...SaveOption) error {
+	body, err := profile.Con.encodeChanges(profile)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already saved disk profile, we need to update it
+	if profile.Href != "" {
+		body, err = profile.Con.put(profile, profile.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		if profile.StorageDomain == nil {
+			return errors.New("Disk profile is not associated with a storage domain")
+		}
+		link, err := profile.Con.storageDomainDiskProfiles(profile.StorageDomain.ID)
+		if err != nil {
+			return err
+		}
+		body, err = profile.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempProfile := DiskProfile{OvirtObject: OvirtObject{Con: profile.Con}}
+	err = profile.Con.decode(body, &tempProfile)
+	if err != nil {
+		return err
+	}
+	*profile = tempProfile
+	return nil
+}
+
+// Patch Updates the server with the fields of the disk profile named by their JSON
+// path, such as "description" or "qos.id", whether they were modified or not
+func (profile *DiskProfile) Patch(fields ...string) error {
+	return profile.Con.patch(profile, fields)
+}
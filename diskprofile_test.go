@@ -0,0 +1,69 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+func TestDiskProfile(t *testing.T) {
+	t.Parallel()
+	con := newTestConnection(t)
+	allProfiles, err := con.GetAllDiskProfiles()
+	if err != nil || len(allProfiles) == 0 {
+		t.Fatal("Error finding a disk profile to find a storage domain", err)
+	}
+	storageDomain := allProfiles[0].StorageDomain
+	newProfile := con.NewStorageDomainDiskProfile(storageDomain.ID)
+	newProfile.Name = "test-disk-profile"
+	err = newProfile.Save()
+	if err != nil {
+		t.Fatal("Error creating new disk profile", err)
+	}
+	domainProfiles, err := con.GetStorageDomainDiskProfiles(storageDomain.ID)
+	if err != nil {
+		t.Fatal("Error retrieving the disk profiles of the storage domain", err)
+	}
+	found := false
+	for _, profile := range domainProfiles {
+		found = found || profile.ID == newProfile.ID
+		if profile.StorageDomain == nil || profile.StorageDomain.ID != storageDomain.ID {
+			t.Error("Listed a disk profile of another storage domain", profile.StorageDomain)
+		}
+	}
+	if !found {
+		t.Error("New disk profile is not listed in its storage domain", domainProfiles)
+	}
+	orphan := con.NewDiskProfile()
+	orphan.Name = "orphan-disk-profile"
+	if err = orphan.Save(); err == nil {
+		t.Error("Created a disk profile without a storage domain")
+	}
+	newDisk := con.NewDisk()
+	newDisk.StorageDomains = &ovirtapi.StorageDomains{
+		StorageDomain: []ovirtapi.Link{{ID: storageDomain.ID}},
+	}
+	err = newDisk.SetDiskProfile(newProfile.Name)
+	if err != nil || newDisk.DiskProfile.ID != newProfile.ID {
+		t.Error("Error assigning disk profile by name", err)
+	}
+	retrievedProfile, err := con.GetDiskProfile(newProfile.ID)
+	if err != nil {
+		t.Fatal("Error retrieving disk profile", err)
+	}
+	retrievedProfile.Description = "about to delete"
+	err = retrievedProfile.Save()
+	if err != nil {
+		t.Fatal("Error updating disk profile", err)
+	}
+	err = retrievedProfile.Delete()
+	if err != nil {
+		t.Fatal("Error Deleting disk profile", err)
+	}
+}
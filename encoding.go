@@ -0,0 +1,355 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format The representation of the objects in the bodies of requests and responses
+type Format int
+
+const (
+	// FormatJSON Objects are sent and received as JSON, the default
+	FormatJSON Format = iota
+	// FormatXML Objects are sent and received as XML, the reference format of the engine
+	FormatXML
+)
+
+// WithFormat Chooses the format the connection sends and accepts objects in
+func WithFormat(format Format) ConnectionOption {
+	return func(con *Connection) {
+		con.format = format
+	}
+}
+
+// contentType The media type of the format, sent in the Content-Type and Accept headers
+func (format Format) contentType() string {
+	if format == FormatXML {
+		return "application/xml"
+	}
+	return "application/json"
+}
+
+// xmlDateFormat The format of the dates of the XML representation
+const xmlDateFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// Timestamp A time sent as the number of milliseconds since the epoch in JSON
+// and as a date in XML
+type Timestamp int
+
+// Time The timestamp as a time
+func (timestamp Timestamp) Time() time.Time {
+	return time.UnixMilli(int64(timestamp))
+}
+
+// MarshalXML Encodes the timestamp as a date in UTC
+func (timestamp Timestamp) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	return encoder.EncodeElement(timestamp.Time().UTC().Format(xmlDateFormat), start)
+}
+
+// UnmarshalXML Decodes a date, or a number of milliseconds
+func (timestamp *Timestamp) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	text := ""
+	if err := decoder.DecodeElement(&text, &start); err != nil {
+		return err
+	}
+	text = strings.TrimSpace(text)
+	if milliseconds, err := strconv.Atoi(text); err == nil {
+		*timestamp = Timestamp(milliseconds)
+		return nil
+	}
+	date, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return err
+	}
+	*timestamp = Timestamp(date.UnixMilli())
+	return nil
+}
+
+// elements The name of the element representing an object of each type, in
+// the body of a request and in the responses listing a collection
+var elements = map[reflect.Type]string{
+	reflect.TypeOf(Action{}):            "action",
+	reflect.TypeOf(AffinityGroup{}):     "affinity_group",
+	reflect.TypeOf(AffinityLabel{}):     "affinity_label",
+	reflect.TypeOf(Cluster{}):           "cluster",
+	reflect.TypeOf(CPUProfile{}):        "cpu_profile",
+	reflect.TypeOf(DataCenter{}):        "data_center",
+	reflect.TypeOf(Disk{}):              "disk",
+	reflect.TypeOf(DiskAttachment{}):    "disk_attachment",
+	reflect.TypeOf(DiskProfile{}):       "disk_profile",
+	reflect.TypeOf(GraphicsConsole{}):   "graphics_console",
+	reflect.TypeOf(Host{}):              "host",
+	reflect.TypeOf(ImageTransfer{}):     "image_transfer",
+	reflect.TypeOf(NIC{}):               "nic",
+	reflect.TypeOf(QoS{}):               "qos",
+	reflect.TypeOf(Quota{}):             "quota",
+	reflect.TypeOf(QuotaClusterLimit{}): "quota_cluster_limit",
+	reflect.TypeOf(QuotaStorageLimit{}): "quota_storage_limit",
+	reflect.TypeOf(SSHPublicKey{}):      "ssh_public_key",
+	reflect.TypeOf(Tag{}):               "tag",
+	reflect.TypeOf(Template{}):          "template",
+	reflect.TypeOf(VM{}):                "vm",
+	reflect.TypeOf(VMPool{}):            "vm_pool",
+}
+
+// elementName The name of the element representing an object of the type, "" when it has none
+func elementName(objectType reflect.Type) string {
+	for objectType.Kind() == reflect.Ptr || objectType.Kind() == reflect.Slice {
+		objectType = objectType.Elem()
+	}
+	return elements[objectType]
+}
+
+// encode The body of a request sending the object
+func (con *Connection) encode(object interface{}) ([]byte, error) {
+	return con.encodeElement(elementName(reflect.TypeOf(object)), object)
+}
+
+// encodeElement The body of a request sending the object as the given element
+func (con *Connection) encodeElement(element string, object interface{}) ([]byte, error) {
+	if con.format != FormatXML {
+		return marshalJSON(object)
+	}
+	if element == "" {
+		return nil, fmt.Errorf("No XML element for %T", object)
+	}
+	buffer := &bytes.Buffer{}
+	encoder := xml.NewEncoder(buffer)
+	encoder.Indent("", "    ")
+	err := encoder.EncodeElement(object, xml.StartElement{Name: xml.Name{Local: element}})
+	return buffer.Bytes(), err
+}
+
+// decode Decodes the body of a response into object
+func (con *Connection) decode(body []byte, object interface{}) error {
+	var err error
+	if con.format == FormatXML {
+		err = xml.Unmarshal(body, object)
+	} else {
+		err = unmarshalJSON(body, object)
+	}
+	if err == nil {
+		con.track(object, body)
+	}
+	return err
+}
+
+// decodeList Appends the objects of a response listing a collection to the
+// slice list points to
+func (con *Connection) decodeList(body []byte, list interface{}) error {
+	slice := reflect.ValueOf(list).Elem()
+	element := elementName(slice.Type())
+	if element == "" {
+		return fmt.Errorf("No element for %s", slice.Type())
+	}
+	response := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name: "Objects",
+		Type: slice.Type(),
+		Tag:  reflect.StructTag(fmt.Sprintf(`json:"%s" xml:"%s"`, element, element)),
+	}}))
+	err := con.decode(body, response.Interface())
+	if err != nil {
+		return err
+	}
+	con.track(response.Elem().Field(0).Addr().Interface(), nil)
+	slice.Set(reflect.AppendSlice(slice, response.Elem().Field(0)))
+	return nil
+}
+
+// decodeFault Decodes the fault of an error response in the format of its
+// content type, the engine may answer errors in JSON regardless of the format
+// requested
+func decodeFault(contentType string, body []byte, fault *Fault) {
+	unmarshal := json.Unmarshal
+	if strings.Contains(contentType, "xml") {
+		unmarshal = xml.Unmarshal
+	}
+	unmarshal(body, fault)
+	if fault.Reason == "" {
+		unmarshal(body, &Action{Fault: fault})
+	}
+}
+
+// listPaths Whether the values of each type contain a list tagged with the path
+// of the element wrapping it, such as `json:"devices>device"`. The engine wraps
+// lists in both formats, encoding/xml follows these paths and marshalJSON and
+// unmarshalJSON follow them in JSON: {"devices": {"device": ["hd"]}}.
+var listPaths sync.Map
+
+func hasListPaths(objectType reflect.Type) bool {
+	if found, ok := listPaths.Load(objectType); ok {
+		return found.(bool)
+	}
+	found := findListPaths(objectType, map[reflect.Type]bool{})
+	listPaths.Store(objectType, found)
+	return found
+}
+
+func findListPaths(objectType reflect.Type, seen map[reflect.Type]bool) bool {
+	for objectType.Kind() == reflect.Ptr || objectType.Kind() == reflect.Slice || objectType.Kind() == reflect.Array {
+		objectType = objectType.Elem()
+	}
+	if objectType.Kind() != reflect.Struct || seen[objectType] {
+		return false
+	}
+	seen[objectType] = true
+	for i := 0; i < objectType.NumField(); i++ {
+		field := objectType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		if strings.Contains(jsonName(field), ">") || findListPaths(field.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonName The key of the field in JSON, "" when it is not encoded
+func jsonName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// embedded Whether the fields of the field are promoted to the object containing it
+func embedded(field reflect.StructField) bool {
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return field.Anonymous && fieldType.Kind() == reflect.Struct && strings.Split(field.Tag.Get("json"), ",")[0] == ""
+}
+
+// marshalJSON Encodes the object, wrapping the lists tagged with a path
+func marshalJSON(object interface{}) ([]byte, error) {
+	body, err := json.MarshalIndent(object, "", "    ")
+	if err != nil || !hasListPaths(reflect.TypeOf(object)) {
+		return body, err
+	}
+	var value interface{}
+	if err = decodeJSONValue(body, &value); err != nil {
+		return nil, err
+	}
+	wrapLists(value, reflect.TypeOf(object))
+	return json.MarshalIndent(value, "", "    ")
+}
+
+// unmarshalJSON Decodes the body into object, unwrapping the lists tagged with a path
+func unmarshalJSON(body []byte, object interface{}) error {
+	if !hasListPaths(reflect.TypeOf(object)) {
+		return json.Unmarshal(body, object)
+	}
+	var value interface{}
+	if err := decodeJSONValue(body, &value); err != nil {
+		return err
+	}
+	unwrapLists(value, reflect.TypeOf(object))
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, object)
+}
+
+// decodeJSONValue Decodes a JSON document keeping numbers as they were sent
+func decodeJSONValue(body []byte, value *interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+// wrapLists Moves the lists of the decoded JSON value of the type from the key
+// of their path into the elements the path names
+func wrapLists(value interface{}, valueType reflect.Type) {
+	walkFields(value, valueType, func(object map[string]interface{}, name string, fieldType reflect.Type) {
+		list, ok := object[name]
+		if !ok {
+			return
+		}
+		wrapLists(list, fieldType)
+		path := strings.Split(name, ">")
+		if len(path) == 1 {
+			return
+		}
+		delete(object, name)
+		for _, key := range path[:len(path)-1] {
+			wrapper, ok := object[key].(map[string]interface{})
+			if !ok {
+				wrapper = map[string]interface{}{}
+				object[key] = wrapper
+			}
+			object = wrapper
+		}
+		object[path[len(path)-1]] = list
+	})
+}
+
+// unwrapLists Moves the lists of the decoded JSON value of the type out of the
+// elements wrapping them to the key of their path
+func unwrapLists(value interface{}, valueType reflect.Type) {
+	walkFields(value, valueType, func(object map[string]interface{}, name string, fieldType reflect.Type) {
+		if path := strings.Split(name, ">"); len(path) > 1 {
+			var list interface{} = object
+			for _, key := range path {
+				wrapper, _ := list.(map[string]interface{})
+				list = wrapper[key]
+			}
+			if list != nil {
+				if _, ok := list.([]interface{}); !ok {
+					list = []interface{}{list}
+				}
+				delete(object, path[0])
+				object[name] = list
+			}
+		}
+		unwrapLists(object[name], fieldType)
+	})
+}
+
+// walkFields Calls visit with the JSON object and key of every field of the
+// decoded JSON value of the type, and of the objects of lists of the type
+func walkFields(value interface{}, valueType reflect.Type, visit func(object map[string]interface{}, name string, fieldType reflect.Type)) {
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	switch valueType.Kind() {
+	case reflect.Slice, reflect.Array:
+		list, _ := value.([]interface{})
+		for _, item := range list {
+			walkFields(item, valueType.Elem(), visit)
+		}
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			if embedded(field) {
+				walkFields(object, field.Type, visit)
+			} else if name := jsonName(field); name != "" && field.PkgPath == "" {
+				visit(object, name, field.Type)
+			}
+		}
+	}
+}
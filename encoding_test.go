@@ -0,0 +1,422 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of TestGoldenFiles")
+
+// fill Sets every exported field the XML representation carries, allocating
+// nested objects down to depth pointers or slices
+func fill(value reflect.Value, depth int) {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(fmt.Sprintf("%s-%d", strings.ToLower(value.Type().Name()), depth))
+	case reflect.Bool:
+		value.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(int64(depth + 2))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(uint64(depth + 2))
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(float64(depth) + 0.5)
+	case reflect.Ptr:
+		if depth > 0 {
+			value.Set(reflect.New(value.Type().Elem()))
+			fill(value.Elem(), depth-1)
+		}
+	case reflect.Slice:
+		if depth == 0 || depth == 1 && value.Type().Elem().Kind() == reflect.Ptr {
+			return
+		}
+		value.Set(reflect.MakeSlice(value.Type(), 2, 2))
+		for i := 0; i < value.Len(); i++ {
+			fill(value.Index(i), depth-1)
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.PkgPath != "" || field.Tag.Get("json") == "-" {
+				continue
+			}
+			fill(value.Field(i), depth)
+		}
+	}
+}
+
+// difference The path of the first field of a that differs from b, "" when they are equal
+func difference(a reflect.Value, b reflect.Value, path string) string {
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return path
+			}
+			return ""
+		}
+		return difference(a.Elem(), b.Elem(), path)
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return fmt.Sprintf("%s (%d elements, %d elements)", path, a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len(); i++ {
+			if diff := difference(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i)); diff != "" {
+				return diff
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if diff := difference(a.Field(i), b.Field(i), path+"."+a.Type().Field(i).Name); diff != "" {
+				return diff
+			}
+		}
+	default:
+		if a.Interface() != b.Interface() {
+			return fmt.Sprintf("%s (%v, %v)", path, a.Interface(), b.Interface())
+		}
+	}
+	return ""
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	t.Parallel()
+	resources := []interface{}{
+		ovirtapi.Action{},
+		ovirtapi.AffinityGroup{},
+		ovirtapi.AffinityLabel{},
+		ovirtapi.CPUProfile{},
+		ovirtapi.Cluster{},
+		ovirtapi.DataCenter{},
+		ovirtapi.Disk{},
+		ovirtapi.DiskAttachment{},
+		ovirtapi.DiskProfile{},
+		ovirtapi.Host{},
+		ovirtapi.NIC{},
+		ovirtapi.QoS{},
+		ovirtapi.Quota{},
+		ovirtapi.QuotaClusterLimit{},
+		ovirtapi.QuotaStorageLimit{},
+		ovirtapi.Tag{},
+		ovirtapi.Template{},
+		ovirtapi.VM{},
+		ovirtapi.VMPool{},
+	}
+	for _, resource := range resources {
+		resourceType := reflect.TypeOf(resource)
+		t.Run(resourceType.Name(), func(t *testing.T) {
+			want := reflect.New(resourceType)
+			fill(want.Elem(), 3)
+
+			// Fields shadowed by a field of the same name are not encoded, the
+			// value decoded from JSON is the reference both encodings must keep
+			jsonBody, err := json.Marshal(want.Interface())
+			if err != nil {
+				t.Fatal("Error encoding JSON", err)
+			}
+			fromJSON := reflect.New(resourceType)
+			if err = json.Unmarshal(jsonBody, fromJSON.Interface()); err != nil {
+				t.Fatal("Error decoding JSON", err)
+			}
+			again, err := json.Marshal(fromJSON.Interface())
+			if err != nil || string(again) != string(jsonBody) {
+				t.Error("JSON did not round trip", difference(want, fromJSON, resourceType.Name()), err)
+			}
+
+			xmlBody, err := xml.Marshal(fromJSON.Interface())
+			if err != nil {
+				t.Fatal("Error encoding XML", err)
+			}
+			fromXML := reflect.New(resourceType)
+			if err = xml.Unmarshal(xmlBody, fromXML.Interface()); err != nil {
+				t.Fatal("Error decoding XML", err)
+			}
+			if !reflect.DeepEqual(fromJSON.Interface(), fromXML.Interface()) {
+				t.Error("XML did not round trip like JSON", difference(fromJSON, fromXML, resourceType.Name()))
+			}
+		})
+	}
+}
+
+func TestXMLFormat(t *testing.T) {
+	t.Parallel()
+	engine := ovirtapitest.NewEngine()
+	defer engine.Close()
+	var accept, contentType string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		accept = req.Header.Get("Accept")
+		if req.Body != nil && req.ContentLength > 0 {
+			contentType = req.Header.Get("Content-Type")
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false,
+		ovirtapi.WithTransport(transport), ovirtapi.WithFilter(false), ovirtapi.WithFormat(ovirtapi.FormatXML))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	if accept != "application/xml" {
+		t.Error("Did not accept XML", accept)
+	}
+	if con.ProductInfo().Name != "oVirt Engine" || con.Version().Major != 4 {
+		t.Error("Did not decode the API root", con.ProductInfo())
+	}
+
+	clusters, err := con.GetAllClusters()
+	if err != nil || len(clusters) != 1 || clusters[0].Name != "Default" || clusters[0].ID == "" {
+		t.Fatal("Did not list the clusters", clusters, err)
+	}
+	template, err := con.GetTemplate(ovirtapitest.BlankTemplateID)
+	if err != nil || template.Name != "Blank" || template.Href == "" {
+		t.Fatal("Did not get the template", template, err)
+	}
+
+	vm := con.NewVM()
+	vm.Name = "xml"
+	vm.Cluster = clusters[0]
+	vm.Template = template
+	vm.Os = &ovirtapi.OperatingSystem{Boot: &ovirtapi.Boot{Devices: []string{"network", "hd"}}}
+	vm.CreationTime = 1493625600123
+	if err = vm.Save(); err != nil {
+		t.Fatal("Error saving the VM", err)
+	}
+	if contentType != "application/xml" {
+		t.Error("Did not send XML", contentType)
+	}
+	if vm.ID == "" || vm.Name != "xml" || vm.Cluster == nil || vm.Cluster.ID != clusters[0].ID {
+		t.Error("Did not decode the saved VM", vm)
+	}
+	if vm.Os == nil || vm.Os.Boot == nil || !reflect.DeepEqual(vm.Os.Boot.Devices, []string{"network", "hd"}) {
+		t.Error("Did not round trip the boot devices", vm.Os)
+	}
+	if len(vm.Links) == 0 || vm.Actions == nil || len(vm.Actions.Links) == 0 {
+		t.Error("Did not decode the links of the VM", vm.Links, vm.Actions)
+	}
+	if vm.CreationTime != 1493625600123 {
+		t.Error("Did not round trip the creation time", vm.CreationTime)
+	}
+	jsonCon, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	if jsonVM, err := jsonCon.GetVM(vm.ID); err != nil || jsonVM.CreationTime != vm.CreationTime {
+		t.Error("Did not decode the same creation time from JSON", err)
+	}
+
+	engine.SetStatus("vms", vm.ID, "up")
+	err = vm.Start("", "", "", "", "", nil)
+	fault, ok := err.(ovirtapi.Fault)
+	if !ok || fault.StatusCode != http.StatusConflict || fault.Detail != "[Cannot run VM. VM is running.]" {
+		t.Error("Did not decode the fault of the action", err)
+	}
+	_, err = con.GetVM("missing")
+	if fault, ok := err.(ovirtapi.Fault); !ok || fault.StatusCode != http.StatusNotFound || fault.Reason == "" {
+		t.Error("Did not decode the fault", err)
+	}
+}
+
+func TestTimestampXML(t *testing.T) {
+	t.Parallel()
+	vm := ovirtapi.VM{}
+	body := `<vm><creation_time>1493625600123</creation_time><start_time>2017-05-01T10:00:00.123+02:00</start_time></vm>`
+	if err := xml.Unmarshal([]byte(body), &vm); err != nil {
+		t.Fatal("Error decoding the VM", err)
+	}
+	if vm.CreationTime != 1493625600123 {
+		t.Error("Did not decode the milliseconds", vm.CreationTime)
+	}
+	if vm.Starttime.Time().UnixMilli() != 1493625600123 {
+		t.Error("Did not decode the date", vm.Starttime, vm.Starttime.Time())
+	}
+	encoded, err := xml.Marshal(vm)
+	if err != nil || !strings.Contains(string(encoded), "<start_time>2017-05-01T08:00:00.123Z</start_time>") {
+		t.Error("Did not encode the date", string(encoded), err)
+	}
+	if err = xml.Unmarshal([]byte(`<vm><stop_time>yesterday</stop_time></vm>`), &vm); err == nil {
+		t.Error("Decoded an invalid date")
+	}
+}
+
+func TestListWrappers(t *testing.T) {
+	t.Parallel()
+	engine := ovirtapitest.NewEngine()
+	defer engine.Close()
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	clusters, err := con.GetAllClusters()
+	if err != nil || len(clusters) == 0 {
+		t.Fatal("Error getting a cluster", err)
+	}
+	template, err := con.GetTemplate(ovirtapitest.BlankTemplateID)
+	if err != nil {
+		t.Fatal("Error getting the blank template", err)
+	}
+	vm := con.NewVM()
+	vm.Name = "wrapped"
+	vm.Cluster = clusters[0]
+	vm.Template = template
+	vm.Os = &ovirtapi.OperatingSystem{Boot: &ovirtapi.Boot{Devices: []string{"network", "hd"}}}
+	vm.CustomProperties = []ovirtapi.CustomProperty{{Name: "sap_agent", Value: "true"}}
+	if err = vm.Save(); err != nil {
+		t.Fatal("Error saving the VM", err)
+	}
+
+	stored := engine.Get("vms", vm.ID)
+	os, _ := stored["os"].(map[string]interface{})
+	boot, _ := os["boot"].(map[string]interface{})
+	devices, _ := boot["devices"].(map[string]interface{})
+	if list, ok := devices["device"].([]interface{}); !ok || len(list) != 2 {
+		t.Error("Did not wrap the boot devices", stored["os"])
+	}
+	properties, _ := stored["custom_properties"].(map[string]interface{})
+	if list, ok := properties["custom_property"].([]interface{}); !ok || len(list) != 1 {
+		t.Error("Did not wrap the custom properties", stored["custom_properties"])
+	}
+	if _, ok := stored["devices>device"]; ok {
+		t.Error("Sent the path of the list as a key", stored)
+	}
+
+	if vm.Os == nil || vm.Os.Boot == nil || !reflect.DeepEqual(vm.Os.Boot.Devices, []string{"network", "hd"}) {
+		t.Error("Did not unwrap the boot devices", vm.Os)
+	}
+	if len(vm.CustomProperties) != 1 || vm.CustomProperties[0].Value != "true" {
+		t.Error("Did not unwrap the custom properties", vm.CustomProperties)
+	}
+}
+
+// TestGoldenFiles Decodes the objects of the engine responses recorded in the
+// cassettes of the test and compares them with the golden files in
+// testdata/golden. Record the cassettes from an engine with OVIRT_URL and
+// OVIRT_RECORD set, then rewrite the golden files with -update.
+func TestGoldenFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		first func(con *ovirtapi.Connection) (interface{}, error)
+		check func(object interface{}) bool
+	}{
+		{"VM", func(con *ovirtapi.Connection) (interface{}, error) {
+			vms, err := con.GetAllVMs()
+			if err != nil || len(vms) == 0 {
+				return nil, err
+			}
+			return vms[0], nil
+		}, func(object interface{}) bool {
+			vm := object.(*ovirtapi.VM)
+			return vm.Os != nil && vm.Os.Boot != nil && len(vm.Os.Boot.Devices) > 0 &&
+				vm.PlacementPolicy != nil && len(vm.PlacementPolicy.Hosts) > 0
+		}},
+		{"Host", func(con *ovirtapi.Connection) (interface{}, error) {
+			hosts, err := con.GetAllHosts()
+			if err != nil || len(hosts) == 0 {
+				return nil, err
+			}
+			return hosts[0], nil
+		}, func(object interface{}) bool {
+			host := object.(*ovirtapi.Host)
+			return host.HardwareInformation != nil && len(host.HardwareInformation.SupportedRngSources) > 0 &&
+				host.PowerManagement != nil && len(host.PowerManagement.Agents) > 0
+		}},
+		{"Disk", func(con *ovirtapi.Connection) (interface{}, error) {
+			disks, err := con.GetAllDisks()
+			if err != nil || len(disks) == 0 {
+				return nil, err
+			}
+			return disks[0], nil
+		}, func(object interface{}) bool {
+			disk := object.(*ovirtapi.Disk)
+			return len(disk.VMs) > 0 && disk.VMs[0].ID != ""
+		}},
+		{"Cluster", func(con *ovirtapi.Connection) (interface{}, error) {
+			clusters, err := con.GetAllClusters()
+			if err != nil || len(clusters) == 0 {
+				return nil, err
+			}
+			return clusters[0], nil
+		}, func(object interface{}) bool {
+			return len(object.(*ovirtapi.Cluster).SupportedVersions) > 0
+		}},
+		{"Template", func(con *ovirtapi.Connection) (interface{}, error) {
+			templates, err := con.GetAllTemplates()
+			if err != nil || len(templates) == 0 {
+				return nil, err
+			}
+			return templates[0], nil
+		}, func(object interface{}) bool {
+			template := object.(*ovirtapi.Template)
+			return template.Os != nil && template.Os.Boot != nil && len(template.Os.Boot.Devices) > 0
+		}},
+		{"DataCenter", func(con *ovirtapi.Connection) (interface{}, error) {
+			dataCenters, err := con.GetAllDataCenters()
+			if err != nil || len(dataCenters) == 0 {
+				return nil, err
+			}
+			return dataCenters[0], nil
+		}, func(object interface{}) bool {
+			dataCenter := object.(*ovirtapi.DataCenter)
+			return dataCenter.SupportedVersions != nil && len(dataCenter.SupportedVersions.Version) > 0
+		}},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := os.Stat(cassettePath(t)); err != nil && os.Getenv("OVIRT_URL") == "" {
+				t.Skip("No cassette recorded", err)
+			}
+			con := newTestConnection(t)
+			object, err := test.first(con)
+			if err != nil || object == nil {
+				t.Fatal("Error getting the object", err)
+			}
+			if !test.check(object) {
+				t.Error("Did not decode the wrapped lists", object)
+			}
+			if os.Getenv("OVIRT_URL") != "" && !*update {
+				return
+			}
+			decoded := &bytes.Buffer{}
+			encoder := json.NewEncoder(decoded)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			if err = encoder.Encode(object); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", "golden", test.name+".json")
+			if *update {
+				if err = ioutil.WriteFile(golden, decoded.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(want) != decoded.String() {
+				t.Errorf("The decoded object differs from %s, rewrite it with -update if expected\n%s", golden, decoded)
+			}
+		})
+	}
+}
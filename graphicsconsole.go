@@ -0,0 +1,214 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ticket A one time password opening a console.
+type Ticket struct {
+	// The password of the console.
+	Value string `json:"value,omitempty" xml:"value,omitempty"`
+	// The number of seconds the password is valid for.
+	Expiry int `json:"expiry,omitempty,string" xml:"expiry,omitempty"`
+}
+
+// GraphicsConsole A SPICE or VNC console of a virtual machine.
+type GraphicsConsole struct {
+	OvirtObject
+	// The address of the host the console client connects to.
+	Address string `json:"address,omitempty" xml:"address,omitempty"`
+	// The protocol of the console, spice or vnc.
+	Protocol string `json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// The port the console client connects to.
+	Port int `json:"port,omitempty,string" xml:"port,omitempty"`
+	// The port the console client connects to with TLS, SPICE only.
+	TLSPort int `json:"tls_port,omitempty,string" xml:"tls_port,omitempty"`
+	// A reference to the virtual machine of the console.
+	VM *Link `json:"vm,omitempty" xml:"vm,omitempty"`
+}
+
+// GetGraphicsConsoles Retrieve the graphics consoles of the VM, with the
+// address and ports of the consoles of the running VM
+func (vm *VM) GetGraphicsConsoles() ([]*GraphicsConsole, error) {
+	linkResp, err := vm.getLinkResponse("graphicsconsoles", map[string]string{"current": "true"})
+	if err != nil {
+		return nil, err
+	}
+	consoles := []*GraphicsConsole{}
+	for i := range linkResp.GraphicsConsole {
+		console := &linkResp.GraphicsConsole[i]
+		console.Con = vm.Con
+		consoles = append(consoles, console)
+	}
+	return consoles, nil
+}
+
+// GetGraphicsConsole Retrieve the graphics console of the VM using the protocol, spice or vnc
+func (vm *VM) GetGraphicsConsole(protocol string) (*GraphicsConsole, error) {
+	consoles, err := vm.GetGraphicsConsoles()
+	if err != nil {
+		return nil, err
+	}
+	for _, console := range consoles {
+		if console.Protocol == protocol {
+			return console, nil
+		}
+	}
+	return nil, fmt.Errorf("VM %s does not have a %s console", vm.Name, protocol)
+}
+
+// Ticket Issues a one time password opening the console, valid for expiry,
+// the engine chooses the validity when expiry is 0
+func (console *GraphicsConsole) Ticket(expiry time.Duration) (*Ticket, error) {
+	action, err := console.doActionResponse("ticket", Action{
+		Ticket: &Ticket{Expiry: int(expiry / time.Second)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if action.Ticket == nil || action.Ticket.Value == "" {
+		return nil, errors.New("Server did not return the ticket")
+	}
+	return action.Ticket, nil
+}
+
+// RemoteViewerConnectionFile The content of the .vv file opening the console
+// in remote-viewer, generated by the engine with a new ticket
+func (console *GraphicsConsole) RemoteViewerConnectionFile() (string, error) {
+	action, err := console.doActionResponse("remoteviewerconnectionfile", Action{})
+	if err != nil {
+		return "", err
+	}
+	if action.RemoteViewerConnectionFile == "" {
+		return "", errors.New("Server did not return the connection file")
+	}
+	return action.RemoteViewerConnectionFile, nil
+}
+
+// RemoteViewerFile The settings of a .vv file opening a graphics console in
+// remote-viewer, String renders the content of the file
+type RemoteViewerFile struct {
+	// The protocol of the console, spice or vnc.
+	Type string
+	// The address and ports of the console.
+	Host    string
+	Port    int
+	TLSPort int
+	// The ticket opening the console and the time it is valid for.
+	Password string
+	Expiry   time.Duration
+	// The title of the window, %d is replaced with the number of the monitor.
+	Title string
+	// The subject of the certificate of the host and the certificate of the
+	// certificate authority of the engine in PEM format, for TLS connections.
+	HostSubject string
+	CA          string
+	// The proxy the client connects through.
+	Proxy string
+	// Whether the window opens in full screen.
+	FullScreen bool
+	// Whether smart cards are passed to the virtual machine.
+	SmartcardEnabled bool
+}
+
+// NewRemoteViewerFile Build the settings of a .vv file opening the console of
+// the VM with the ticket, from the console and the display of the VM. The CA
+// is taken from the certificate of the display, GetCACertificate retrieves it
+// when the display does not include it.
+func (vm *VM) NewRemoteViewerFile(console *GraphicsConsole, ticket *Ticket) *RemoteViewerFile {
+	file := &RemoteViewerFile{
+		Type:    console.Protocol,
+		Host:    console.Address,
+		Port:    console.Port,
+		TLSPort: console.TLSPort,
+		Title:   vm.Name + ":%d",
+	}
+	if ticket != nil {
+		file.Password = ticket.Value
+		file.Expiry = time.Duration(ticket.Expiry) * time.Second
+	}
+	if display := vm.Display; display != nil {
+		if file.Host == "" {
+			file.Host = display.Address
+		}
+		if file.Port == 0 {
+			file.Port = display.Port
+		}
+		if file.TLSPort == 0 && console.Protocol != "vnc" {
+			file.TLSPort = display.SecurePort
+		}
+		file.Proxy = display.Proxy
+		file.SmartcardEnabled = display.SmartcardEnabled == "true"
+		if display.Certificate != nil {
+			file.HostSubject = display.Certificate.Subject
+			file.CA = display.Certificate.Content
+		}
+	}
+	return file
+}
+
+// String The content of the .vv file
+func (file *RemoteViewerFile) String() string {
+	lines := []string{"[virt-viewer]", "type=" + file.Type, "host=" + file.Host}
+	if file.Port != 0 {
+		lines = append(lines, "port="+strconv.Itoa(file.Port))
+	}
+	if file.TLSPort != 0 {
+		lines = append(lines, "tls-port="+strconv.Itoa(file.TLSPort))
+	}
+	if file.Password != "" {
+		lines = append(lines, "password="+file.Password)
+	}
+	if file.Expiry != 0 {
+		lines = append(lines, fmt.Sprintf("# Password is valid for %d seconds.", int(file.Expiry/time.Second)))
+	}
+	lines = append(lines, "delete-this-file=1")
+	if file.Title != "" {
+		lines = append(lines, "title="+file.Title)
+	}
+	lines = append(lines, "fullscreen="+boolFlag(file.FullScreen),
+		"toggle-fullscreen=shift+f11", "release-cursor=shift+f12", "secure-attention=ctrl+alt+end")
+	if file.Type == "spice" {
+		lines = append(lines, "enable-smartcard="+boolFlag(file.SmartcardEnabled))
+	}
+	if file.HostSubject != "" {
+		lines = append(lines, "host-subject="+file.HostSubject)
+	}
+	if file.CA != "" {
+		lines = append(lines, "ca="+strings.Replace(strings.TrimSpace(file.CA), "\n", `\n`, -1))
+	}
+	if file.Proxy != "" {
+		lines = append(lines, "proxy="+file.Proxy)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func boolFlag(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// GetCACertificate Retrieve the certificate of the certificate authority of the
+// engine in PEM format, the CA of TLS console connections
+func (con *Connection) GetCACertificate() (string, error) {
+	pki := con.endPoint.ResolveReference(&url.URL{Path: "/ovirt-engine/services/pki-resource"})
+	pki.RawQuery = url.Values{"resource": {"ca-certificate"}, "format": {"X509-PEM-CA"}}.Encode()
+	body, err := con.Request("GET", pki, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
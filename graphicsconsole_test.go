@@ -0,0 +1,178 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+func TestGraphicsConsoles(t *testing.T) {
+	t.Parallel()
+	_, con, _, vm := newChangesVM(t)
+	consoles, err := vm.GetGraphicsConsoles()
+	if err != nil {
+		t.Fatal("Error getting the graphics consoles", err)
+	}
+	if len(consoles) != 1 || consoles[0].Protocol != "spice" || consoles[0].Port != 5900 || consoles[0].TLSPort != 5901 {
+		t.Fatal("Unexpected graphics consoles", consoles)
+	}
+	if _, err = vm.GetGraphicsConsole("vnc"); err == nil {
+		t.Error("Found a VNC console on a SPICE VM")
+	}
+	console, err := vm.GetGraphicsConsole("spice")
+	if err != nil {
+		t.Fatal("Error getting the SPICE console", err)
+	}
+
+	ticket, err := console.Ticket(5 * time.Minute)
+	if err != nil {
+		t.Fatal("Error issuing a ticket", err)
+	}
+	if ticket.Value == "" || ticket.Expiry != 300 {
+		t.Error("Unexpected ticket", ticket)
+	}
+	if ticket, err = console.Ticket(0); err != nil || ticket.Expiry != ovirtapitest.DefaultTicketExpiry {
+		t.Error("Did not issue a ticket with the default expiry", ticket, err)
+	}
+
+	file, err := console.RemoteViewerConnectionFile()
+	if err != nil {
+		t.Fatal("Error getting the connection file", err)
+	}
+	if !strings.HasPrefix(file, "[virt-viewer]\ntype=spice\n") || !strings.Contains(file, "\npassword=") {
+		t.Error("Unexpected connection file", file)
+	}
+
+	ca, err := con.GetCACertificate()
+	if err != nil || ca != ovirtapitest.CACertificate {
+		t.Error("Did not get the CA certificate", ca, err)
+	}
+}
+
+// newSecretsVM Creates a VM on a fake engine through a connection logging to
+// the returned buffer and recording the conversation to the returned cassette
+func newSecretsVM(t *testing.T) (*ovirtapi.VM, *bytes.Buffer, *ovirtapitest.Cassette) {
+	engine := ovirtapitest.NewEngine()
+	t.Cleanup(engine.Close)
+	output := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cassette := ovirtapitest.NewRecorder(filepath.Join(t.TempDir(), "cassette.json"), nil)
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false,
+		ovirtapi.WithLogger(logger), ovirtapi.WithTransport(cassette), ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	clusters, err := con.GetAllClusters()
+	if err != nil || len(clusters) == 0 {
+		t.Fatal("Error getting a cluster", err)
+	}
+	vm := con.NewVM()
+	vm.Name = "secrets"
+	vm.Cluster = clusters[0]
+	vm.Template = &ovirtapi.Template{OvirtObject: ovirtapi.OvirtObject{Link: ovirtapi.Link{ID: ovirtapitest.BlankTemplateID}}}
+	if err = vm.Save(); err != nil {
+		t.Fatal("Error creating the VM", err)
+	}
+	return vm, output, cassette
+}
+
+// checkSecrets Fails the test when the log or the cassette hold one of the secrets
+func checkSecrets(t *testing.T, output *bytes.Buffer, cassette *ovirtapitest.Cassette, secrets ...string) {
+	if err := cassette.Save(); err != nil {
+		t.Fatal("Error saving the cassette", err)
+	}
+	recorded, err := ioutil.ReadFile(cassette.Path)
+	if err != nil {
+		t.Fatal("Error reading the cassette", err)
+	}
+	for _, secret := range secrets {
+		if secret == "" {
+			t.Fatal("No secret to look for")
+		}
+		if strings.Contains(output.String(), secret) {
+			t.Error("Logged the secret", secret, output.String())
+		}
+		if strings.Contains(string(recorded), secret) {
+			t.Error("Recorded the secret", secret, string(recorded))
+		}
+	}
+	if !strings.Contains(output.String(), ovirtapi.Redacted) || !strings.Contains(string(recorded), ovirtapi.Redacted) {
+		t.Error("Did not redact the secrets")
+	}
+}
+
+func TestGraphicsConsoleSecrets(t *testing.T) {
+	t.Parallel()
+	vm, output, cassette := newSecretsVM(t)
+	console, err := vm.GetGraphicsConsole("spice")
+	if err != nil {
+		t.Fatal("Error getting the SPICE console", err)
+	}
+	ticket, err := console.Ticket(time.Minute)
+	if err != nil {
+		t.Fatal("Error issuing a ticket", err)
+	}
+	file, err := console.RemoteViewerConnectionFile()
+	if err != nil {
+		t.Fatal("Error getting the connection file", err)
+	}
+	password := ""
+	for _, line := range strings.Split(file, "\n") {
+		if strings.HasPrefix(line, "password=") {
+			password = strings.TrimPrefix(line, "password=")
+		}
+	}
+	checkSecrets(t, output, cassette, ticket.Value, password)
+}
+
+func TestRemoteViewerFile(t *testing.T) {
+	t.Parallel()
+	vm := &ovirtapi.VM{Display: &ovirtapi.Display{
+		Address:          "ignored.example.com",
+		SecurePort:       5910,
+		Proxy:            "http://proxy.example.com:3128",
+		SmartcardEnabled: "true",
+		Certificate:      &ovirtapi.Certificate{Subject: "O=example,CN=host1", Content: ovirtapitest.CACertificate},
+	}}
+	vm.Name = "vm1"
+	console := &ovirtapi.GraphicsConsole{Protocol: "spice", Address: "host1.example.com", Port: 5900}
+	file := vm.NewRemoteViewerFile(console, &ovirtapi.Ticket{Value: "secret", Expiry: 120})
+	expected := "[virt-viewer]\n" +
+		"type=spice\n" +
+		"host=host1.example.com\n" +
+		"port=5900\n" +
+		"tls-port=5910\n" +
+		"password=secret\n" +
+		"# Password is valid for 120 seconds.\n" +
+		"delete-this-file=1\n" +
+		"title=vm1:%d\n" +
+		"fullscreen=0\n" +
+		"toggle-fullscreen=shift+f11\n" +
+		"release-cursor=shift+f12\n" +
+		"secure-attention=ctrl+alt+end\n" +
+		"enable-smartcard=1\n" +
+		"host-subject=O=example,CN=host1\n" +
+		`ca=-----BEGIN CERTIFICATE-----\nZmFrZSBvVmlydCBlbmdpbmUgY2VydGlmaWNhdGUgYXV0aG9yaXR5\n-----END CERTIFICATE-----` + "\n" +
+		"proxy=http://proxy.example.com:3128\n"
+	if file.String() != expected {
+		t.Errorf("Unexpected connection file\n%s\nexpected\n%s", file, expected)
+	}
+
+	vnc := vm.NewRemoteViewerFile(&ovirtapi.GraphicsConsole{Protocol: "vnc", Port: 5901}, nil)
+	if vnc.Host != "ignored.example.com" || vnc.TLSPort != 0 || strings.Contains(vnc.String(), "password=") {
+		t.Error("Unexpected VNC connection file", vnc)
+	}
+}
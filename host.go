@@ -7,275 +7,274 @@
 package ovirtapi
 
 import (
-	"encoding/json"
 	"fmt"
 )
 
 // TransparentHugePages Type representing a transparent huge pages (THP) support
 type TransparentHugePages struct {
-	Enabled string `json:"enabled,omitempty"`
+	Enabled string `json:"enabled,omitempty" xml:"enabled,omitempty"`
 }
 
 // VMSummary Type containing information related to virtual machines on a particular host.
 type VMSummary struct {
 	// The number of virtual machines active on the host.
-	Active int `json:"active,omitempty,string"`
+	Active int `json:"active,omitempty,string" xml:"active,omitempty"`
 	// The number of virtual machines migrating to or from the host.
-	Migrating int `json:"migrating,omitempty,string"`
+	Migrating int `json:"migrating,omitempty,string" xml:"migrating,omitempty"`
 	// The number of virtual machines present on the host.
-	Total int `json:"total,omitempty,string"`
+	Total int `json:"total,omitempty,string" xml:"total,omitempty"`
 }
 
 // User Represents a user in the system.
 type User struct {
 	//Free text containing comments about this object.
-	Comment    string `json:"comment,omitempty"`
-	Department string `json:"department,omitempty"`
+	Comment    string `json:"comment,omitempty" xml:"comment,omitempty"`
+	Department string `json:"department,omitempty" xml:"department,omitempty"`
 	//A human-readable description in plain text.
-	Description   string `json:"description,omitempty"`
-	DomainEntryID string `json:"domain_entry_id,omitempty"`
-	Email         string `json:"email,omitempty"`
+	Description   string `json:"description,omitempty" xml:"description,omitempty"`
+	DomainEntryID string `json:"domain_entry_id,omitempty" xml:"domain_entry_id,omitempty"`
+	Email         string `json:"email,omitempty" xml:"email,omitempty"`
 	//A unique identifier.
-	ID       string `json:"id,omitempty"`
-	LastName string `json:"last_name,omitempty"`
-	LoggedIn string `json:"logged_in,omitempty"`
+	ID       string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	LastName string `json:"last_name,omitempty" xml:"last_name,omitempty"`
+	LoggedIn string `json:"logged_in,omitempty" xml:"logged_in,omitempty"`
 	//A human-readable name in plain text.
-	Name string `json:"name,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
 	//Namespace where the user resides.
-	Namespace string `json:"namespace,omitempty"`
-	Password  string `json:"password,omitempty"`
+	Namespace string `json:"namespace,omitempty" xml:"namespace,omitempty"`
+	Password  string `json:"password,omitempty" xml:"password,omitempty"`
 	//Similar to user_name.
-	Principal string `json:"principal,omitempty"`
+	Principal string `json:"principal,omitempty" xml:"principal,omitempty"`
 	//The user's username.
-	UserName string `json:"user_name,omitempty"`
+	UserName string `json:"user_name,omitempty" xml:"user_name,omitempty"`
 }
 
 type SSH struct {
-	AuthenticationMethod string `json:"authentication_method,omitempty"`
+	AuthenticationMethod string `json:"authentication_method,omitempty" xml:"authentication_method,omitempty"`
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
-	Fingerprint string `json:"fingerprint,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty" xml:"fingerprint,omitempty"`
 	// A unique identifier.
-	ID string `json:"id,omitempty"`
+	ID string `json:"id,omitempty" xml:"id,attr,omitempty"`
 	// A human-readable name in plain text.
-	Name string `json:"name,omitempty"`
-	Port int    `json:"port,omitempty,string"`
-	User User   `json:"user,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+	Port int    `json:"port,omitempty,string" xml:"port,omitempty"`
+	User User   `json:"user,omitempty" xml:"user,omitempty"`
 }
 
 type SPM struct {
-	Priority int    `json:"priority,omitempty,string"`
-	Status   string `json:"status,omitempty"`
+	Priority int    `json:"priority,omitempty,string" xml:"priority,omitempty"`
+	Status   string `json:"status,omitempty" xml:"status,omitempty"`
 }
 
 // SELinux Represents SELinux in the system.
 type SELinux struct {
-	Mode string `json:"mode,omitempty"`
+	Mode string `json:"mode,omitempty" xml:"mode,omitempty"`
 }
 
 type PMProxy struct {
-	Type string `json:"type,omitempty"`
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
 }
 
 // Option ...
 type Option struct {
-	Name  string `json:"name,omitempty"`
-	Type  string `json:"type,omitempty"`
-	Value string `json:"value,omitempty"`
+	Name  string `json:"name,omitempty" xml:"name,omitempty"`
+	Type  string `json:"type,omitempty" xml:"type,omitempty"`
+	Value string `json:"value,omitempty" xml:"value,omitempty"`
 }
 
 // Agent Type representing a fence agent.
 type Agent struct {
 	// Fence agent address.
-	Address string `json:"address,omitempty"`
+	Address string `json:"address,omitempty" xml:"address,omitempty"`
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// Specifies whether the agent should be used concurrently or sequentially.
-	Concurrent string `json:"concurrent,omitempty"`
+	Concurrent string `json:"concurrent,omitempty" xml:"concurrent,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
 	// Specifies whether the options should be encrypted.
-	EncryptOptions string `json:"encrypt_options,omitempty"`
+	EncryptOptions string `json:"encrypt_options,omitempty" xml:"encrypt_options,omitempty"`
 	// A unique identifier.
-	ID string `json:"id,omitempty"`
+	ID string `json:"id,omitempty" xml:"id,attr,omitempty"`
 	// A human-readable name in plain text.
-	Name string `json:"name,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
 	// Fence agent options (comma-delimited list of key-value pairs).
-	Options []Option `json:"options,omitempty"`
+	Options []Option `json:"options>option,omitempty" xml:"options>option,omitempty"`
 	// The order of this agent if used with other agents.
-	Order int `json:"order,omitempty,string"`
+	Order int `json:"order,omitempty,string" xml:"order,omitempty"`
 	// Fence agent password.
-	Password string `json:"password,omitempty"`
+	Password string `json:"password,omitempty" xml:"password,omitempty"`
 	// Fence agent port.
-	Port int `json:"port,omitempty,string"`
+	Port int `json:"port,omitempty,string" xml:"port,omitempty"`
 	// Fence agent type.
-	Type string `json:"type,omitempty"`
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
 	// Fence agent user name.
-	Username string `json:"username,omitempty"`
+	Username string `json:"username,omitempty" xml:"username,omitempty"`
 }
 
 // PowerManagement ...
 type PowerManagement struct {
 	// The host name or IP address of the host.
-	Address string `json:"address,omitempty"`
+	Address string `json:"address,omitempty" xml:"address,omitempty"`
 	// Specifies fence agent options when multiple fences are used.
-	Agents []Agent `json:"agents,omitempty"`
+	Agents []Agent `json:"agents>agent,omitempty" xml:"agents>agent,omitempty"`
 	// Toggles the automated power control of the host in order to save energy.
-	AutomaticPMEnabled string `json:"automatic_pm_enabled,omitempty"`
+	AutomaticPMEnabled string `json:"automatic_pm_enabled,omitempty" xml:"automatic_pm_enabled,omitempty"`
 	// Indicates whether power management configuration is enabled or disabled.
-	Enabled string `json:"enabled,omitempty"`
+	Enabled string `json:"enabled,omitempty" xml:"enabled,omitempty"`
 	// Toggles whether to determine if kdump is running on the host before it is shut down.
-	KdumpDetection string `json:"kdump_detection,omitempty"`
+	KdumpDetection string `json:"kdump_detection,omitempty" xml:"kdump_detection,omitempty"`
 	// Fencing options for the selected type= specified with the option name="" and value="" strings.
-	Options []Option `json:"options,omitempty"`
+	Options []Option `json:"options>option,omitempty" xml:"options>option,omitempty"`
 	// A valid, robust password for power management.
-	Password string `json:"password,omitempty"`
+	Password string `json:"password,omitempty" xml:"password,omitempty"`
 	// Determines the power management proxy.
-	PMProxies PMProxy `json:"pm_proxies,omitempty"`
+	PMProxies PMProxy `json:"pm_proxies,omitempty" xml:"pm_proxies,omitempty"`
 	// Determines the power status of the host.
-	Status string `json:"status,omitempty"`
+	Status string `json:"status,omitempty" xml:"status,omitempty"`
 	// Fencing device code.
-	Type string `json:"type,omitempty"`
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
 	// A valid user name for power management.
-	Username string `json:"username,omitempty"`
+	Username string `json:"username,omitempty" xml:"username,omitempty"`
 }
 
 // KSM ...
 type KSM struct {
-	Enabled          string `json:"enabled,omitempty"`
-	MergeAcrossNodes string `json:"merge_across_nodes,omitempty"`
+	Enabled          string `json:"enabled,omitempty" xml:"enabled,omitempty"`
+	MergeAcrossNodes string `json:"merge_across_nodes,omitempty" xml:"merge_across_nodes,omitempty"`
 }
 
 // HostDevicePassthrough ...
 type HostDevicePassthrough struct {
-	Enabled string `json:"enabled,omitempty"`
+	Enabled string `json:"enabled,omitempty" xml:"enabled,omitempty"`
 }
 
 // ISCSIDetails ...
 type ISCSIDetails struct {
-	Address         string `json:"address,omitempty"`
-	DiskID          string `json:"disk_id,omitempty"`
-	Initiator       string `json:"initiator,omitempty"`
-	LunMapping      int    `json:"lun_mapping,omitempty,string"`
-	Password        string `json:"password,omitempty"`
-	Paths           int    `json:"paths,omitempty,string"`
-	Port            int    `json:"port,omitempty,string"`
-	Portal          string `json:"portal,omitempty"`
-	ProductID       string `json:"product_id,omitempty"`
-	Serial          string `json:"serial,omitempty"`
-	Size            int    `json:"size,omitempty,string"`
-	Status          string `json:"status,omitempty"`
-	StorageDomainID string `json:"storage_domain_id,omitempty"`
-	Target          string `json:"target,omitempty"`
-	Username        string `json:"username,omitempty"`
-	VendorID        string `json:"vendor_id,omitempty"`
-	VolumeGroupID   string `json:"volume_group_id,omitempty"`
+	Address         string `json:"address,omitempty" xml:"address,omitempty"`
+	DiskID          string `json:"disk_id,omitempty" xml:"disk_id,omitempty"`
+	Initiator       string `json:"initiator,omitempty" xml:"initiator,omitempty"`
+	LunMapping      int    `json:"lun_mapping,omitempty,string" xml:"lun_mapping,omitempty"`
+	Password        string `json:"password,omitempty" xml:"password,omitempty"`
+	Paths           int    `json:"paths,omitempty,string" xml:"paths,omitempty"`
+	Port            int    `json:"port,omitempty,string" xml:"port,omitempty"`
+	Portal          string `json:"portal,omitempty" xml:"portal,omitempty"`
+	ProductID       string `json:"product_id,omitempty" xml:"product_id,omitempty"`
+	Serial          string `json:"serial,omitempty" xml:"serial,omitempty"`
+	Size            int    `json:"size,omitempty,string" xml:"size,omitempty"`
+	Status          string `json:"status,omitempty" xml:"status,omitempty"`
+	StorageDomainID string `json:"storage_domain_id,omitempty" xml:"storage_domain_id,omitempty"`
+	Target          string `json:"target,omitempty" xml:"target,omitempty"`
+	Username        string `json:"username,omitempty" xml:"username,omitempty"`
+	VendorID        string `json:"vendor_id,omitempty" xml:"vendor_id,omitempty"`
+	VolumeGroupID   string `json:"volume_group_id,omitempty" xml:"volume_group_id,omitempty"`
 }
 
 // HostedEngine ...
 type HostedEngine struct {
-	Active            string `json:"active,omitempty"`
-	Configured        string `json:"configured,omitempty"`
-	GlobalMaintenance string `json:"global_maintenance,omitempty"`
-	LocalMaintenance  string `json:"local_maintenance,omitempty"`
-	Score             int    `json:"score,omitempty,string"`
+	Active            string `json:"active,omitempty" xml:"active,omitempty"`
+	Configured        string `json:"configured,omitempty" xml:"configured,omitempty"`
+	GlobalMaintenance string `json:"global_maintenance,omitempty" xml:"global_maintenance,omitempty"`
+	LocalMaintenance  string `json:"local_maintenance,omitempty" xml:"local_maintenance,omitempty"`
+	Score             int    `json:"score,omitempty,string" xml:"score,omitempty"`
 }
 
 // HardwareInformation Represents hardware information of host.
 type HardwareInformation struct {
 	// Type of host's CPU.
-	Family string `json:"family,omitempty"`
+	Family string `json:"family,omitempty" xml:"family,omitempty"`
 	// Manufacturer of the host's machine and hardware vendor.
-	Manufacturer string `json:"manufacturer,omitempty"`
+	Manufacturer string `json:"manufacturer,omitempty" xml:"manufacturer,omitempty"`
 	// Host's product name (for example RHEV Hypervisor).
-	ProductName string `json:"product_name,omitempty"`
+	ProductName string `json:"product_name,omitempty" xml:"product_name,omitempty"`
 	// Unique ID for host's chassis.
-	SerialNumber string `json:"serial_number,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty" xml:"serial_number,omitempty"`
 	// Supported sources of random number generator.
-	SupportedRngSources []string `json:"supported_rng_sources>supported_rng_source,omitempty"`
+	SupportedRngSources []string `json:"supported_rng_sources>supported_rng_source,omitempty" xml:"supported_rng_sources>supported_rng_source,omitempty"`
 	// Unique ID for each host.
-	UUID string `json:"uuid,omitempty"`
+	UUID string `json:"uuid,omitempty" xml:"uuid,omitempty"`
 	// Unique name for each of the manufacturer.
-	Version string `json:"version,omitempty"`
+	Version string `json:"version,omitempty" xml:"version,omitempty"`
 }
 
 // Host Type representing a host.
 type Host struct {
 	OvirtObject
 	// The host address (FQDN/IP).
-	Address string `json:"address,omitempty"`
+	Address string `json:"address,omitempty" xml:"address,omitempty"`
 	// The host auto non uniform memory access (NUMA) status.
-	AutoNumaStatus string `json:"auto_numa_status,omitempty"`
+	AutoNumaStatus string `json:"auto_numa_status,omitempty" xml:"auto_numa_status,omitempty"`
 	// The host certificate.
-	Certificate *Certificate `json:"certificate,omitempty"`
+	Certificate *Certificate `json:"certificate,omitempty" xml:"certificate,omitempty"`
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// The CPU type of this host.
-	CPU *CPU `json:"cpu,omitempty"`
+	CPU *CPU `json:"cpu,omitempty" xml:"cpu,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
 	// Specifies whether host device passthrough is enabled on this host.
-	DevicePassthrough *HostDevicePassthrough `json:"device_passthrough,omitempty"`
+	DevicePassthrough *HostDevicePassthrough `json:"device_passthrough,omitempty" xml:"device_passthrough,omitempty"`
 	// Optionally specify the display address of this host explicitly.
-	Display *Display `json:"display,omitempty"`
+	Display *Display `json:"display,omitempty" xml:"display,omitempty"`
 	// The host external status.
-	ExternalStatus string `json:"external_status,omitempty"`
+	ExternalStatus string `json:"external_status,omitempty" xml:"external_status,omitempty"`
 	// The host hardware information.
-	HardwareInformation *HardwareInformation `json:"hardware_information,omitempty"`
+	HardwareInformation *HardwareInformation `json:"hardware_information,omitempty" xml:"hardware_information,omitempty"`
 	// The self-hosted engine status of this host.
-	HostedEngine *HostedEngine `json:"hosted_engine,omitempty"`
+	HostedEngine *HostedEngine `json:"hosted_engine,omitempty" xml:"hosted_engine,omitempty"`
 	// A unique identifier.
-	ID string `json:"id,omitempty"`
+	ID string `json:"id,omitempty" xml:"id,attr,omitempty"`
 	// The host iSCSI details.
-	ISCSI *ISCSIDetails `json:"iscsi,omitempty"`
+	ISCSI *ISCSIDetails `json:"iscsi,omitempty" xml:"iscsi,omitempty"`
 	// The host KDUMP status.
-	KdumpStatus string `json:"kdump_status,omitempty"`
+	KdumpStatus string `json:"kdump_status,omitempty" xml:"kdump_status,omitempty"`
 	// Kernel SamePage Merging (KSM) reduces references to memory pages from multiple identical pages to a single page reference.
-	KSM KSM `json:"ksm,omitempty"`
+	KSM KSM `json:"ksm,omitempty" xml:"ksm,omitempty"`
 	// The host libvirt version.
-	LibvirtVersion *Version `json:"libvirt_version,omitempty"`
+	LibvirtVersion *Version `json:"libvirt_version,omitempty" xml:"libvirt_version,omitempty"`
 	// The max scheduling memory on this host in bytes.
-	MaxSchedulingMemory int `json:"max_scheduling_memory,omitempty"`
+	MaxSchedulingMemory int `json:"max_scheduling_memory,omitempty,string" xml:"max_scheduling_memory,omitempty"`
 	// The amount of physical memory on this host in bytes.
-	Memory int `json:"memory,omitempty"`
+	Memory int `json:"memory,omitempty,string" xml:"memory,omitempty"`
 	// A human-readable name in plain text.
-	Name string `json:"name,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
 	// Specifies whether non uniform memory access (NUMA) is supported on this host.
-	NumaSupported string `json:"numa_supported,omitempty"`
+	NumaSupported string `json:"numa_supported,omitempty" xml:"numa_supported,omitempty"`
 	// The operating system on this host.
-	OS *OperatingSystem `json:"os,omitempty"`
+	OS *OperatingSystem `json:"os,omitempty" xml:"os,omitempty"`
 	// Specifies whether we should override firewall definitions.
-	OverrideIptables string `json:"override_iptables,omitempty"`
+	OverrideIptables string `json:"override_iptables,omitempty" xml:"override_iptables,omitempty"`
 	// The host port.
-	Port int `json:"port,omitempty,string"`
+	Port int `json:"port,omitempty,string" xml:"port,omitempty"`
 	// The host power management definitions.
-	PowerManagement *PowerManagement `json:"power_management,omitempty"`
+	PowerManagement *PowerManagement `json:"power_management,omitempty" xml:"power_management,omitempty"`
 	// The protocol that the engine uses to communicate with the host.
-	Protocol string `json:"protocol,omitempty"`
+	Protocol string `json:"protocol,omitempty" xml:"protocol,omitempty"`
 	// When creating a new host, a root password is required if the password authentication method is chosen, but this is not subsequently included in the representation.
-	RootPassword string `json:"root_password,omitempty"`
+	RootPassword string `json:"root_password,omitempty" xml:"root_password,omitempty"`
 	// The host SElinux status.
-	SELinux *SELinux `json:"se_linux,omitempty"`
+	SELinux *SELinux `json:"se_linux,omitempty" xml:"se_linux,omitempty"`
 	// The host storage pool manager (SPM) status and definition.
-	SPM *SPM `json:"spm,omitempty"`
+	SPM *SPM `json:"spm,omitempty" xml:"spm,omitempty"`
 	// The SSH definitions.
-	SSH *SSH `json:"ssh,omitempty"`
+	SSH *SSH `json:"ssh,omitempty" xml:"ssh,omitempty"`
 	// The host status.
-	Status string `json:"status,omitempty"`
+	Status string `json:"status,omitempty" xml:"status,omitempty"`
 	// The host status details.
-	StatusDetail string `json:"status_detail,omitempty"`
+	StatusDetail string `json:"status_detail,omitempty" xml:"status_detail,omitempty"`
 	// The virtual machine summary - how many are active, migrating and total.
-	Summary *VMSummary `json:"summary,omitempty"`
+	Summary *VMSummary `json:"summary,omitempty" xml:"summary,omitempty"`
 	// Transparent huge page support expands the size of memory pages beyond the standard 4 KiB limit.
-	TransparentHugePages *TransparentHugePages `json:"transparent_huge_pages,omitempty"`
+	TransparentHugePages *TransparentHugePages `json:"transparent_huge_pages,omitempty" xml:"transparent_huge_pages,omitempty"`
 	// Indicates if the host contains a full installation of the operating system or a scaled-down version intended only to host virtual machines.
-	Type string `json:"type,omitempty"`
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
 	// Specifies whether there is an oVirt-related update on this host.
-	UpdateAvailable string `json:"update_available,omitempty"`
+	UpdateAvailable string `json:"update_available,omitempty" xml:"update_available,omitempty"`
 	// The version of VDSM.
-	Version *Version `json:"version,omitempty"`
+	Version *Version `json:"version,omitempty" xml:"version,omitempty"`
 }
 
 // Activate the host for use, such as running virtual machines.
@@ -412,7 +411,7 @@ func (con *Connection) GetHost(id string) (*Host, error) {
 		return nil, err
 	}
 	host := con.NewHost()
-	err = json.Unmarshal(body, host)
+	err = con.decode(body, host)
 	if err != nil {
 		return nil, err
 	}
@@ -429,7 +428,7 @@ func (host *Host) Update() error {
 		return err
 	}
 	tempHost := Host{OvirtObject: OvirtObject{Con: host.Con}}
-	err = json.Unmarshal(body, &tempHost)
+	err = host.Con.decode(body, &tempHost)
 	if err != nil {
 		return err
 	}
@@ -444,9 +443,24 @@ func (con *Connection) GetAllHosts() ([]*Host, error) {
 		return nil, err
 	}
 	hosts := []*Host{}
-	err = json.Unmarshal(body, &struct {
-		Host *[]*Host
-	}{&hosts})
+	err = con.decodeList(body, &hosts)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range hosts {
+		host.Con = con
+	}
+	return hosts, err
+}
+
+// SearchHosts Retrieve the hosts matching the search query
+func (con *Connection) SearchHosts(query string) ([]*Host, error) {
+	body, err := con.SearchLinkBody("hosts", query)
+	if err != nil {
+		return nil, err
+	}
+	hosts := []*Host{}
+	err = con.decodeList(body, &hosts)
 	if err != nil {
 		return nil, err
 	}
@@ -461,15 +475,16 @@ func (con *Connection) NewHost() *Host {
 	return &Host{OvirtObject: OvirtObject{Con: con}}
 }
 
-// Save Updates the server with the local copy of the host
-func (host *Host) Save() error {
-	body, err := json.MarshalIndent(host, "", "    ")
+// Save Updates the server with the local copy of the host, sending only the fields
+// modified since it was loaded
+func (host *Host) Save(options ...SaveOption) error {
+	body, err := host.Con.encodeChanges(host)
 	if err != nil {
 		return err
 	}
 	// If there is a link, it is an already saved host, we need to update it
 	if host.Href != "" {
-		body, err = host.Con.Request("PUT", host.Con.ResolveLink(host.Href), body)
+		body, err = host.Con.put(host, host.Href, body, options)
 		if err != nil {
 			return err
 		}
@@ -484,10 +499,16 @@ func (host *Host) Save() error {
 		}
 	}
 	tempHost := Host{OvirtObject: OvirtObject{Con: host.Con}}
-	err = json.Unmarshal(body, &tempHost)
+	err = host.Con.decode(body, &tempHost)
 	if err != nil {
 		return err
 	}
 	*host = tempHost
 	return nil
 }
+
+// Patch Updates the server with the fields of the host named by their JSON
+// path, such as "comment" or "power_management.enabled", whether they were modified or not
+func (host *Host) Patch(fields ...string) error {
+	return host.Con.patch(host, fields)
+}
@@ -0,0 +1,321 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ImageioChunkSize The size of the requests Upload writes the image with
+const ImageioChunkSize = 4 * 1024 * 1024
+
+// ImageioWorkers The number of connections extents are transferred over by default
+const ImageioWorkers = 4
+
+// ImageioOptions The features of the imageio daemon serving a transfer
+type ImageioOptions struct {
+	// The optional requests supported, such as extents, zero and flush.
+	Features []string `json:"features"`
+	// The number of connections the daemon accepts for reading and writing the image.
+	MaxReaders int `json:"max_readers"`
+	MaxWriters int `json:"max_writers"`
+}
+
+// Supports Whether the daemon supports the feature
+func (options *ImageioOptions) Supports(feature string) bool {
+	for _, supported := range options.Features {
+		if supported == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageExtent A range of an image, either data or zeroes
+type ImageExtent struct {
+	Start  int64 `json:"start"`
+	Length int64 `json:"length"`
+	// Whether the range reads as zeroes.
+	Zero bool `json:"zero"`
+	// Whether the range is not allocated in the image.
+	Hole bool `json:"hole"`
+}
+
+// ImageioChecksumAlgorithm The algorithm sparse transfers verify images with,
+// the default blake2b of imageio is not in the standard library
+const ImageioChecksumAlgorithm = "sha256"
+
+// ImageioChecksum The checksum of an image computed by the imageio daemon, the
+// digests of the blocks of BlockSize bytes of the image are checksummed together
+type ImageioChecksum struct {
+	Algorithm string `json:"algorithm"`
+	BlockSize int64  `json:"block_size"`
+	Checksum  string `json:"checksum"`
+}
+
+// ImageioClient Reads and writes the image of a transfer through the HTTP API
+// of the imageio daemon, within the context of the connection of the transfer
+type ImageioClient struct {
+	// The URL of the image on the daemon.
+	URL *url.URL
+	// Called with the number of bytes transferred so far, after each request
+	// reading, writing or zeroing the image. Sparse transfers call it from
+	// their workers concurrently.
+	Progress func(transferred int64)
+	// The number of connections the extents of sparse transfers are
+	// transferred over, ImageioWorkers when 0. The daemon limits it to its
+	// max_readers or max_writers.
+	Workers int
+
+	con     *Connection
+	counter *imageioCounter
+}
+
+// imageioCounter The bytes transferred by a client and the copies making its requests within spans
+type imageioCounter struct {
+	lock        sync.Mutex
+	transferred int64
+}
+
+// ImageioClient Returns a client of the image of the transfer, through the
+// transfer URL of the host, or the proxy URL of the engine when the transfer
+// has no transfer URL
+func (transfer *ImageTransfer) ImageioClient() (*ImageioClient, error) {
+	link := transfer.TransferURL
+	if link == "" {
+		link = transfer.ProxyURL
+	}
+	if link == "" {
+		return nil, fmt.Errorf("Image transfer %s is %s and has no transfer URL", transfer.ID, transfer.Phase)
+	}
+	transferURL, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	return &ImageioClient{URL: transferURL, con: transfer.Con, counter: &imageioCounter{}}, nil
+}
+
+// Options Retrieve the features of the daemon
+func (client *ImageioClient) Options() (*ImageioOptions, error) {
+	body, err := client.request("OPTIONS", client.URL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	options := &ImageioOptions{}
+	return options, json.Unmarshal(body, options)
+}
+
+// Extents Retrieve the data and zero extents of the image
+func (client *ImageioClient) Extents() ([]ImageExtent, error) {
+	extentsURL := *client.URL
+	extentsURL.Path = strings.TrimSuffix(extentsURL.Path, "/") + "/extents"
+	body, err := client.request("GET", &extentsURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	extents := []ImageExtent{}
+	return extents, json.Unmarshal(body, &extents)
+}
+
+// Checksum Retrieve the checksum of the whole image computed by the daemon with
+// algorithm, the default algorithm of the daemon when empty
+func (client *ImageioClient) Checksum(algorithm string) (*ImageioChecksum, error) {
+	checksumURL := *client.URL
+	checksumURL.Path = strings.TrimSuffix(checksumURL.Path, "/") + "/checksum"
+	if algorithm != "" {
+		checksumURL.RawQuery = url.Values{"algorithm": {algorithm}}.Encode()
+	}
+	body, err := client.request("GET", &checksumURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	checksum := &ImageioChecksum{}
+	return checksum, json.Unmarshal(body, checksum)
+}
+
+// ReadAt Reads len(p) bytes of the image at offset with a ranged GET, the
+// daemon must answer with the partial content starting at offset
+func (client *ImageioClient) ReadAt(p []byte, offset int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", offset, offset+int64(len(p))-1)}}
+	resp, err := client.do("GET", client.URL, nil, header)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("Imageio daemon answered the range at %d with %s instead of partial content", offset, resp.Status)
+	}
+	if contentRange := resp.Header.Get("Content-Range"); !strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", offset)) {
+		return 0, fmt.Errorf("Imageio daemon answered the range at %d with the range %q", offset, contentRange)
+	}
+	n, err := io.ReadFull(resp.Body, p)
+	client.progress(int64(n))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// WriteAt Writes p to the image at offset with a PUT, the data is not flushed
+// to the storage until Flush is called
+func (client *ImageioClient) WriteAt(p []byte, offset int64) (int, error) {
+	writeURL := *client.URL
+	writeURL.RawQuery = url.Values{"flush": {"n"}}.Encode()
+	header := http.Header{"Content-Range": {fmt.Sprintf("bytes %d-%d/*", offset, offset+int64(len(p))-1)}}
+	_, err := client.request("PUT", &writeURL, p, header)
+	if err != nil {
+		return 0, err
+	}
+	client.progress(int64(len(p)))
+	return len(p), nil
+}
+
+// Zero Writes size bytes of zeroes to the image at offset without sending them
+func (client *ImageioClient) Zero(offset int64, size int64) error {
+	body, err := json.Marshal(map[string]interface{}{"op": "zero", "offset": offset, "size": size, "flush": false})
+	if err != nil {
+		return err
+	}
+	_, err = client.request("PATCH", client.URL, body, http.Header{"Content-Type": {"application/json"}})
+	if err != nil {
+		return err
+	}
+	client.progress(size)
+	return nil
+}
+
+// Flush Flushes the data written to the storage of the image
+func (client *ImageioClient) Flush() error {
+	_, err := client.request("PATCH", client.URL, []byte(`{"op":"flush"}`), http.Header{"Content-Type": {"application/json"}})
+	return err
+}
+
+// Upload Writes the size bytes read from r to the start of the image, in
+// requests of ImageioChunkSize bytes, and flushes them
+func (client *ImageioClient) Upload(r io.Reader, size int64) (err error) {
+	con, span := client.con.startSpan("ImageioClient.Upload", Attribute{"ovirt.size", strconv.FormatInt(size, 10)})
+	defer func() { span.End(err) }()
+	client = client.withConnection(con)
+	chunk := make([]byte, ImageioChunkSize)
+	for offset := int64(0); offset < size; {
+		n := int64(len(chunk))
+		if size-offset < n {
+			n = size - offset
+		}
+		if _, err = io.ReadFull(r, chunk[:n]); err != nil {
+			return err
+		}
+		if _, err = client.WriteAt(chunk[:n], offset); err != nil {
+			return err
+		}
+		offset += n
+	}
+	return client.Flush()
+}
+
+// Download Writes the whole image to w, streaming it with a single GET
+func (client *ImageioClient) Download(w io.Writer) (written int64, err error) {
+	con, span := client.con.startSpan("ImageioClient.Download")
+	defer func() { span.End(err) }()
+	client = client.withConnection(con)
+	resp, err := client.do("GET", client.URL, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	buffer := make([]byte, ImageioChunkSize)
+	for {
+		n, readErr := resp.Body.Read(buffer)
+		if n > 0 {
+			if _, err = w.Write(buffer[:n]); err != nil {
+				return written, err
+			}
+			written += int64(n)
+			client.progress(int64(n))
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
+
+// Transferred The number of bytes read, written or zeroed by the client
+func (client *ImageioClient) Transferred() int64 {
+	client.counter.lock.Lock()
+	defer client.counter.lock.Unlock()
+	return client.counter.transferred
+}
+
+// withConnection Returns a client sharing the URL and progress of client, making its requests through con
+func (client *ImageioClient) withConnection(con *Connection) *ImageioClient {
+	if con == client.con {
+		return client
+	}
+	return &ImageioClient{URL: client.URL, Progress: client.Progress, Workers: client.Workers, con: con, counter: client.counter}
+}
+
+// progress Adds n bytes to the bytes transferred and reports them, Progress
+// is called without holding the lock so it may call Transferred
+func (client *ImageioClient) progress(n int64) {
+	client.counter.lock.Lock()
+	client.counter.transferred += n
+	transferred := client.counter.transferred
+	client.counter.lock.Unlock()
+	if client.Progress != nil {
+		client.Progress(transferred)
+	}
+}
+
+// request Makes a request to the daemon and returns the body of the response
+func (client *ImageioClient) request(verb string, requestURL *url.URL, reqBody []byte, header http.Header) ([]byte, error) {
+	resp, err := client.do(verb, requestURL, reqBody, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// do Makes a request to the daemon, a response whose status is not 2xx is returned as a Fault
+func (client *ImageioClient) do(verb string, requestURL *url.URL, reqBody []byte, header http.Header) (*http.Response, error) {
+	httpClient := client.con.client
+	if httpClient == nil {
+		httpClient = &http.Client{Transport: client.con.transport}
+	}
+	req, err := http.NewRequestWithContext(client.con.Context(), verb, requestURL.String(), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		detail, _ := ioutil.ReadAll(resp.Body)
+		return nil, Fault{resp.StatusCode, strings.TrimSpace(string(detail)), http.StatusText(resp.StatusCode)}
+	}
+	return resp, nil
+}
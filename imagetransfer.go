@@ -0,0 +1,206 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// ImageTransferUpload The direction of a transfer writing an image to a disk
+	ImageTransferUpload = "upload"
+	// ImageTransferDownload The direction of a transfer reading the image of a disk
+	ImageTransferDownload = "download"
+)
+
+const (
+	// ImageTransferInitializing The engine is preparing the transfer
+	ImageTransferInitializing = "initializing"
+	// ImageTransferTransferring The image can be transferred through the transfer URL
+	ImageTransferTransferring = "transferring"
+	// ImageTransferPausedUser The transfer was paused, it can be resumed
+	ImageTransferPausedUser = "paused_user"
+	// ImageTransferFinalizingSuccess The transfer was finalized, the engine is verifying the image
+	ImageTransferFinalizingSuccess = "finalizing_success"
+	// ImageTransferFinishedSuccess The image was transferred and the disk unlocked
+	ImageTransferFinishedSuccess = "finished_success"
+	// ImageTransferFinishedFailure The transfer failed
+	ImageTransferFinishedFailure = "finished_failure"
+	// ImageTransferCancelled The transfer was cancelled
+	ImageTransferCancelled = "cancelled"
+)
+
+// ImageTransfer A transfer of the image of a disk between a client and the
+// imageio daemon of a host or of the engine.
+type ImageTransfer struct {
+	OvirtObject
+	// Whether the image is uploaded to the disk or downloaded from it.
+	Direction string `json:"direction,omitempty" xml:"direction,omitempty"`
+	// The format of the image transferred, raw or cow, the format of the disk by default.
+	Format string `json:"format,omitempty" xml:"format,omitempty"`
+	// The number of seconds the transfer may be idle before the engine cancels it.
+	InactivityTimeout int `json:"inactivity_timeout,omitempty,string" xml:"inactivity_timeout,omitempty"`
+	// The phase of the transfer.
+	Phase string `json:"phase,omitempty" xml:"phase,omitempty"`
+	// The URL of the image on the imageio proxy of the engine.
+	ProxyURL string `json:"proxy_url,omitempty" xml:"proxy_url,omitempty"`
+	// The URL of the image on the imageio daemon of the host, reachable from the hosts network only.
+	TransferURL string `json:"transfer_url,omitempty" xml:"transfer_url,omitempty"`
+	// The number of bytes transferred, as reported by the imageio daemon.
+	Transferred int64 `json:"transferred,omitempty,string" xml:"transferred,omitempty"`
+	// A reference to the disk of the transfer.
+	Disk *Link `json:"disk,omitempty" xml:"disk,omitempty"`
+	// A reference to the host the image is transferred through.
+	Host *Link `json:"host,omitempty" xml:"host,omitempty"`
+}
+
+// NewImageTransfer Create a new transfer structure of the image of the disk in the direction, upload or download
+func (disk *Disk) NewImageTransfer(direction string) *ImageTransfer {
+	return &ImageTransfer{
+		OvirtObject: OvirtObject{Con: disk.Con},
+		Direction:   direction,
+		Disk:        &Link{Href: disk.Href, ID: disk.ID},
+	}
+}
+
+// GetImageTransfer Retrieve an image transfer from the server
+func (con *Connection) GetImageTransfer(id string) (*ImageTransfer, error) {
+	body, err := con.GetLinkBody("imagetransfers", id)
+	if err != nil {
+		return nil, err
+	}
+	object := &ImageTransfer{OvirtObject: OvirtObject{Con: con}}
+	err = con.decode(body, object)
+	if err != nil {
+		return nil, err
+	}
+	return object, err
+}
+
+// GetAllImageTransfers Retrieve the image transfers in progress from the server
+func (con *Connection) GetAllImageTransfers() ([]*ImageTransfer, error) {
+	body, err := con.GetLinkBody("imagetransfers", "")
+	if err != nil {
+		return nil, err
+	}
+	objects := []*ImageTransfer{}
+	err = con.decodeList(body, &objects)
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		object.Con = con
+	}
+	return objects, err
+}
+
+// Save Starts the transfer on the server, or updates the server with the
+// local copy of a started transfer, sending only the fields modified since it
+// was loaded
+func (transfer *ImageTransfer) Save(options ...SaveOption) error {
+	body, err := transfer.Con.encodeChanges(transfer)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already started transfer, we need to update it
+	if transfer.Href != "" {
+		body, err = transfer.Con.put(transfer, transfer.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		if transfer.Disk == nil || transfer.Disk.ID == "" {
+			return errors.New("Image transfer is not associated with a disk")
+		}
+		link, err := transfer.Con.GetLink("imagetransfers")
+		if err != nil {
+			return err
+		}
+		body, err = transfer.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempTransfer := ImageTransfer{OvirtObject: OvirtObject{Con: transfer.Con}}
+	err = transfer.Con.decode(body, &tempTransfer)
+	if err != nil {
+		return err
+	}
+	*transfer = tempTransfer
+	return nil
+}
+
+// Update Synchronize the local image transfer with a copy from the server
+func (transfer *ImageTransfer) Update() error {
+	if transfer.Href == "" {
+		return fmt.Errorf("Image transfer has not been saved to the server")
+	}
+	newTransfer, err := transfer.Con.GetImageTransfer(transfer.ID)
+	if err != nil {
+		return err
+	}
+	*transfer = *newTransfer
+	return nil
+}
+
+// Finalize Ends a transfer whose image was fully transferred, the engine
+// verifies an uploaded image and unlocks the disk
+func (transfer *ImageTransfer) Finalize() error {
+	return transfer.DoAction("finalize", Action{})
+}
+
+// Cancel Aborts the transfer, the engine unlocks the disk
+func (transfer *ImageTransfer) Cancel() error {
+	return transfer.DoAction("cancel", Action{})
+}
+
+// Pause Suspends the transfer until it is resumed
+func (transfer *ImageTransfer) Pause() error {
+	return transfer.DoAction("pause", Action{})
+}
+
+// Resume Continues a paused transfer
+func (transfer *ImageTransfer) Resume() error {
+	return transfer.DoAction("resume", Action{})
+}
+
+// WaitForPhase Poll the server every interval until the transfer reaches the
+// phase, fails after timeout or when the transfer fails or is cancelled
+func (transfer *ImageTransfer) WaitForPhase(phase string, interval time.Duration, timeout time.Duration) (err error) {
+	con, span := transfer.Con.startSpan("ImageTransfer.WaitForPhase", append(transfer.Con.objectAttributes(transfer.Href, ""), Attribute{"ovirt.phase", phase})...)
+	defer func() { span.End(err) }()
+	if transfer.Href == "" {
+		return fmt.Errorf("Image transfer has not been saved to the server")
+	}
+	deadline := time.Now().Add(timeout)
+	for polls := 1; ; polls++ {
+		newTransfer, err := con.GetImageTransfer(transfer.ID)
+		if err != nil {
+			return err
+		}
+		newTransfer.Con = transfer.Con
+		*transfer = *newTransfer
+		span.SetAttributes(Attribute{"ovirt.polls", strconv.Itoa(polls)})
+		if transfer.Phase == phase {
+			return nil
+		}
+		if transfer.Phase == ImageTransferFinishedFailure || transfer.Phase == ImageTransferCancelled {
+			return fmt.Errorf("Image transfer %s is %s, expected %s", transfer.ID, transfer.Phase, phase)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Image transfer %s is %s after %s, expected %s", transfer.ID, transfer.Phase, timeout, phase)
+		}
+		select {
+		case <-time.After(interval):
+		case <-con.Context().Done():
+			return con.Context().Err()
+		}
+	}
+}
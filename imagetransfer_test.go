@@ -0,0 +1,214 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+// transferSize The size of the disks transferred, a few imageio blocks
+const transferSize = 4 * ovirtapitest.ImageioBlockSize
+
+// newTransferDisk Creates an unlocked disk of size bytes on a fake engine
+func newTransferDisk(t *testing.T, size int) (*ovirtapitest.Engine, *ovirtapi.Disk) {
+	engine, con, _, _ := newChangesVM(t)
+	disk := con.NewDisk()
+	disk.Name = "transfer"
+	disk.Format = "raw"
+	disk.ProvisionedSize = size
+	if err := disk.Save(); err != nil {
+		t.Fatal("Error creating the disk", err)
+	}
+	for disk.Status != "ok" {
+		if err := disk.Update(); err != nil {
+			t.Fatal("Error updating the disk", err)
+		}
+	}
+	return engine, disk
+}
+
+// startTransfer Starts a transfer of the disk and waits until the image can be transferred
+func startTransfer(t *testing.T, disk *ovirtapi.Disk, direction string) (*ovirtapi.ImageTransfer, *ovirtapi.ImageioClient) {
+	transfer := disk.NewImageTransfer(direction)
+	if err := transfer.Save(); err != nil {
+		t.Fatal("Error starting the transfer", err)
+	}
+	if err := transfer.WaitForPhase(ovirtapi.ImageTransferTransferring, time.Millisecond, time.Second); err != nil {
+		t.Fatal("Transfer did not start", err)
+	}
+	client, err := transfer.ImageioClient()
+	if err != nil {
+		t.Fatal("Error creating the imageio client", err)
+	}
+	return transfer, client
+}
+
+func TestImageTransferUpload(t *testing.T) {
+	t.Parallel()
+	engine, disk := newTransferDisk(t, transferSize)
+	transfer, client := startTransfer(t, disk, ovirtapi.ImageTransferUpload)
+	if transfer.Disk == nil || transfer.Disk.ID != disk.ID || transfer.Direction != ovirtapi.ImageTransferUpload {
+		t.Error("Unexpected transfer", transfer)
+	}
+	if err := disk.Update(); err != nil || disk.Status != "locked" {
+		t.Error("Transferred disk is not locked", disk.Status, err)
+	}
+	image := bytes.Repeat([]byte("ovirt"), transferSize/5)
+	image = append(image, make([]byte, transferSize-len(image))...)
+	progress := []int64{}
+	client.Progress = func(transferred int64) { progress = append(progress, transferred) }
+	if err := client.Upload(bytes.NewReader(image), int64(len(image))); err != nil {
+		t.Fatal("Error uploading the image", err)
+	}
+	if len(progress) == 0 || progress[len(progress)-1] != transferSize || client.Transferred() != transferSize {
+		t.Error("Unexpected progress", progress, client.Transferred())
+	}
+	if stats := engine.Imageio().Stats(); stats.BytesWritten != transferSize || stats.Flushes != 1 {
+		t.Error("Unexpected imageio requests", stats)
+	}
+	if err := transfer.Finalize(); err != nil {
+		t.Fatal("Error finalizing the transfer", err)
+	}
+	if err := transfer.WaitForPhase(ovirtapi.ImageTransferFinishedSuccess, time.Millisecond, time.Second); err != nil {
+		t.Fatal("Transfer did not succeed", err)
+	}
+	if uploaded := engine.Imageio().Image(disk.ID); !bytes.Equal(uploaded, image) {
+		t.Error("Uploaded image differs")
+	}
+	if err := disk.Update(); err != nil || disk.Status != "ok" {
+		t.Error("Disk was not unlocked", disk.Status, err)
+	}
+	if err := transfer.Finalize(); !isFault(err, 409) {
+		t.Error("Finalized a finished transfer", err)
+	}
+}
+
+func TestImageTransferDownload(t *testing.T) {
+	t.Parallel()
+	engine, disk := newTransferDisk(t, transferSize)
+	image := make([]byte, transferSize)
+	copy(image[ovirtapitest.ImageioBlockSize:], "data")
+	engine.Imageio().SetImage(disk.ID, image)
+	_, client := startTransfer(t, disk, ovirtapi.ImageTransferDownload)
+	options, err := client.Options()
+	if err != nil || !options.Supports("extents") {
+		t.Error("Unexpected options", options, err)
+	}
+	extents, err := client.Extents()
+	expected := []ovirtapi.ImageExtent{
+		{Start: 0, Length: ovirtapitest.ImageioBlockSize, Zero: true},
+		{Start: ovirtapitest.ImageioBlockSize, Length: ovirtapitest.ImageioBlockSize},
+		{Start: 2 * ovirtapitest.ImageioBlockSize, Length: 2 * ovirtapitest.ImageioBlockSize, Zero: true},
+	}
+	if err != nil || !reflect.DeepEqual(extents, expected) {
+		t.Error("Unexpected extents", extents, err)
+	}
+	checksum, err := client.Checksum(ovirtapi.ImageioChecksumAlgorithm)
+	if err != nil || checksum.BlockSize != ovirtapitest.ImageioChecksumBlockSize {
+		t.Fatal("Unexpected checksum", checksum, err)
+	}
+	if local, err := ovirtapi.ChecksumExtents(bytes.NewReader(image), extents, checksum.BlockSize); err != nil || local != checksum.Checksum {
+		t.Error("Checksum of the image differs", local, checksum.Checksum, err)
+	}
+	if _, err = client.Checksum(""); !isFault(err, 400) {
+		t.Error("Stand-in computed a checksum with the default algorithm of imageio", err)
+	}
+	data := make([]byte, 4)
+	if _, err = client.ReadAt(data, ovirtapitest.ImageioBlockSize); err != nil || string(data) != "data" {
+		t.Error("Unexpected range", string(data), err)
+	}
+	downloaded := &bytes.Buffer{}
+	if written, err := client.Download(downloaded); err != nil || written != transferSize || !bytes.Equal(downloaded.Bytes(), image) {
+		t.Error("Downloaded image differs", written, err)
+	}
+	if client.Transferred() != transferSize+4 {
+		t.Error("Unexpected progress", client.Transferred())
+	}
+	if _, err = client.WriteAt([]byte("data"), 0); !isFault(err, 403) {
+		t.Error("Wrote through a download transfer", err)
+	}
+}
+
+func TestImageTransferIgnoredRange(t *testing.T) {
+	t.Parallel()
+	engine, disk := newTransferDisk(t, transferSize)
+	image := make([]byte, transferSize)
+	copy(image[ovirtapitest.ImageioBlockSize:], "data")
+	engine.Imageio().SetImage(disk.ID, image)
+	_, client := startTransfer(t, disk, ovirtapi.ImageTransferDownload)
+	// A proxy answering ranged requests with the whole image
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(image)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal("Error parsing the proxy URL", err)
+	}
+	client.URL = proxyURL
+	data := make([]byte, 4)
+	if n, err := client.ReadAt(data, ovirtapitest.ImageioBlockSize); err == nil || n != 0 {
+		t.Error("Accepted the start of the image for a range", n, string(data))
+	}
+	if client.Transferred() != 0 {
+		t.Error("Unexpected progress", client.Transferred())
+	}
+}
+
+func TestImageTransferCancel(t *testing.T) {
+	t.Parallel()
+	engine, disk := newTransferDisk(t, transferSize)
+	transfer, client := startTransfer(t, disk, ovirtapi.ImageTransferUpload)
+	if _, err := client.WriteAt(bytes.Repeat([]byte{1}, 8), 8); err != nil {
+		t.Fatal("Error writing", err)
+	}
+	if err := client.Zero(12, 4); err != nil {
+		t.Fatal("Error zeroing", err)
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal("Error flushing", err)
+	}
+	if image := engine.Imageio().Image(disk.ID); !bytes.Equal(image[8:16], []byte{1, 1, 1, 1, 0, 0, 0, 0}) {
+		t.Error("Unexpected image", image[:16])
+	}
+	if stats := engine.Imageio().Stats(); stats.Writes != 1 || stats.Zeroes != 1 || stats.Flushes != 1 {
+		t.Error("Unexpected imageio requests", stats)
+	}
+	if err := transfer.Pause(); err != nil {
+		t.Fatal("Error pausing the transfer", err)
+	}
+	if err := transfer.Resume(); err != nil {
+		t.Fatal("Error resuming the transfer", err)
+	}
+	if err := transfer.Cancel(); err != nil {
+		t.Fatal("Error cancelling the transfer", err)
+	}
+	if err := transfer.WaitForPhase(ovirtapi.ImageTransferFinishedSuccess, time.Millisecond, time.Second); err == nil || transfer.Phase != ovirtapi.ImageTransferCancelled {
+		t.Error("Waited for a cancelled transfer", transfer.Phase, err)
+	}
+	if _, err := client.Options(); !isFault(err, 403) {
+		t.Error("Ticket of a cancelled transfer was not revoked", err)
+	}
+	if err := disk.Update(); err != nil || disk.Status != "ok" {
+		t.Error("Disk was not unlocked", disk.Status, err)
+	}
+}
+
+// isFault Whether err is a fault with the status code
+func isFault(err error, status int) bool {
+	fault, ok := err.(ovirtapi.Fault)
+	return ok && fault.StatusCode == status
+}
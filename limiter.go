@@ -0,0 +1,179 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Priority The order requests waiting for the limits of a connection are sent in
+type Priority int
+
+const (
+	// PriorityLow For bulk jobs, such as inventories, sent when no other request waits
+	PriorityLow Priority = iota
+	// PriorityNormal The priority of requests made without a priority
+	PriorityNormal
+	// PriorityHigh For interactive requests, sent before any other waiting request
+	PriorityHigh
+)
+
+type priorityKey struct{}
+
+// ContextWithPriority Returns a context sending the requests of the connection with the priority
+func ContextWithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// RequestPriority The priority carried by ctx, PriorityNormal when there is none
+func RequestPriority(ctx context.Context) Priority {
+	priority, ok := ctx.Value(priorityKey{}).(Priority)
+	if !ok || priority < PriorityLow || priority > PriorityHigh {
+		return PriorityNormal
+	}
+	return priority
+}
+
+// WithRateLimit Limits the connection to rate requests per second on average,
+// with bursts of up to burst requests
+func WithRateLimit(rate float64, burst int) ConnectionOption {
+	return func(con *Connection) {
+		if con.limiter == nil {
+			con.limiter = &limiter{}
+		}
+		con.limiter.setRate(rate, burst)
+	}
+}
+
+// WithMaxInFlight Limits the connection to max requests waiting for a response at once
+func WithMaxInFlight(max int) ConnectionOption {
+	return func(con *Connection) {
+		if con.limiter == nil {
+			con.limiter = &limiter{}
+		}
+		con.limiter.setMaxInFlight(max)
+	}
+}
+
+// limiter A token bucket and a cap of the requests in flight, shared by the
+// copies of a connection. Waiting requests are granted by priority, then in
+// the order they arrived.
+type limiter struct {
+	lock        sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	maxInFlight int
+	inFlight    int
+	waiters     [PriorityHigh + 1][]*waiter
+	timer       *time.Timer
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func (limiter *limiter) setRate(rate float64, burst int) {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	limiter.rate = rate
+	limiter.burst = math.Max(float64(burst), 1)
+	limiter.tokens = limiter.burst
+	limiter.last = time.Now()
+}
+
+func (limiter *limiter) setMaxInFlight(max int) {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	limiter.maxInFlight = max
+}
+
+// acquire Waits until a request with the priority may be sent, the returned
+// function must be called once the response has been read
+func (limiter *limiter) acquire(ctx context.Context, priority Priority) (func(), error) {
+	if limiter == nil {
+		return func() {}, nil
+	}
+	limiter.lock.Lock()
+	w := &waiter{ready: make(chan struct{})}
+	limiter.waiters[priority] = append(limiter.waiters[priority], w)
+	limiter.dispatch()
+	limiter.lock.Unlock()
+	select {
+	case <-w.ready:
+		return limiter.release, nil
+	case <-ctx.Done():
+		limiter.lock.Lock()
+		defer limiter.lock.Unlock()
+		if w.granted {
+			limiter.inFlight--
+		} else {
+			limiter.remove(priority, w)
+		}
+		limiter.dispatch()
+		return nil, ctx.Err()
+	}
+}
+
+func (limiter *limiter) release() {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	limiter.inFlight--
+	limiter.dispatch()
+}
+
+// dispatch Grants the waiting requests the limits allow, in order, and wakes
+// up when the next token is available, must be called with the lock held
+func (limiter *limiter) dispatch() {
+	for priority := PriorityHigh; priority >= PriorityLow; priority-- {
+		for len(limiter.waiters[priority]) > 0 {
+			if limiter.maxInFlight > 0 && limiter.inFlight >= limiter.maxInFlight {
+				return
+			}
+			if limiter.rate > 0 {
+				now := time.Now()
+				limiter.tokens = math.Min(limiter.burst, limiter.tokens+now.Sub(limiter.last).Seconds()*limiter.rate)
+				limiter.last = now
+				if limiter.tokens < 1 {
+					wait := time.Duration((1 - limiter.tokens) / limiter.rate * float64(time.Second))
+					if limiter.timer == nil {
+						limiter.timer = time.AfterFunc(wait, limiter.wakeUp)
+					}
+					return
+				}
+				limiter.tokens--
+			}
+			w := limiter.waiters[priority][0]
+			limiter.waiters[priority] = limiter.waiters[priority][1:]
+			limiter.inFlight++
+			w.granted = true
+			close(w.ready)
+		}
+	}
+}
+
+func (limiter *limiter) wakeUp() {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	limiter.timer = nil
+	limiter.dispatch()
+}
+
+func (limiter *limiter) remove(priority Priority, w *waiter) {
+	waiters := limiter.waiters[priority]
+	for i, candidate := range waiters {
+		if candidate == w {
+			limiter.waiters[priority] = append(waiters[:i:i], waiters[i+1:]...)
+			return
+		}
+	}
+}
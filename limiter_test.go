@@ -0,0 +1,130 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+// blockingTransport Counts the requests in flight, requests to a vm wait for
+// the gate to be opened
+type blockingTransport struct {
+	lock        sync.Mutex
+	inFlight    int
+	maxInFlight int
+	order       []string
+	gate        chan struct{}
+	delay       time.Duration
+}
+
+func (transport *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport.lock.Lock()
+	transport.inFlight++
+	if transport.inFlight > transport.maxInFlight {
+		transport.maxInFlight = transport.inFlight
+	}
+	transport.order = append(transport.order, path.Base(req.URL.Path))
+	transport.lock.Unlock()
+	if transport.gate != nil && path.Base(path.Dir(req.URL.Path)) == "vms" {
+		<-transport.gate
+	}
+	time.Sleep(transport.delay)
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	transport.lock.Lock()
+	transport.inFlight--
+	transport.lock.Unlock()
+	return resp, err
+}
+
+func TestMaxInFlight(t *testing.T) {
+	t.Parallel()
+	url, username, password := testCredentials(t)
+	transport := &blockingTransport{delay: 5 * time.Millisecond}
+	con, err := ovirtapi.NewConnection(url, username, password, false, ovirtapi.WithTransport(transport), ovirtapi.WithMaxInFlight(2))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			con.GetAllVMs()
+		}()
+	}
+	wg.Wait()
+	if transport.maxInFlight != 2 {
+		t.Error("Expected 2 requests in flight at most, got", transport.maxInFlight)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+	url, username, password := testCredentials(t)
+	con, err := ovirtapi.NewConnection(url, username, password, false, ovirtapi.WithRateLimit(50, 1))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		con.GetAllVMs()
+	}
+	// The first request uses the token of the API root request, the burst is one request
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Error("Did not limit the request rate, 10 requests took", elapsed)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	con.GetAllVMs()
+	_, err = con.WithContext(ctx).GetAllVMs()
+	if err != context.DeadlineExceeded {
+		t.Error("Did not stop waiting for the rate limit when the context expired", err)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	t.Parallel()
+	url, username, password := testCredentials(t)
+	transport := &blockingTransport{gate: make(chan struct{})}
+	con, err := ovirtapi.NewConnection(url, username, password, false, ovirtapi.WithTransport(transport), ovirtapi.WithMaxInFlight(1))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	low := con.WithContext(ovirtapi.ContextWithPriority(context.Background(), ovirtapi.PriorityLow))
+	high := con.WithContext(ovirtapi.ContextWithPriority(context.Background(), ovirtapi.PriorityHigh))
+	wg := sync.WaitGroup{}
+	get := func(con *ovirtapi.Connection, id string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			con.GetVM(id)
+		}()
+		// Let the request reach the limiter before the next one
+		time.Sleep(20 * time.Millisecond)
+	}
+	get(low, "blocking")
+	for _, id := range []string{"low-1", "low-2", "low-3"} {
+		get(low, id)
+	}
+	get(con, "normal")
+	get(high, "high")
+	close(transport.gate)
+	wg.Wait()
+	expected := []string{"api", "blocking", "high", "normal", "low-1", "low-2", "low-3"}
+	for i, id := range expected {
+		if transport.order[i] != id {
+			t.Fatal("Expected requests in order", expected, "got", transport.order)
+		}
+	}
+}
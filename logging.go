@@ -0,0 +1,261 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Redacted The value logged in place of credentials and secrets
+const Redacted = "REDACTED"
+
+// redactedHeaders The headers logged as Redacted
+var redactedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie"}
+
+// redactedKeys The body attributes logged as Redacted, password covers the
+// fence agent, iSCSI, host and cloud-init user passwords, the tokens are
+// the ones of the SSO responses. The value of a ticket and the .vv file,
+// holding a ticket, open graphics consoles, the value of a proxy ticket lets
+// the websocket proxy connect to them.
+var redactedKeys = map[string]bool{
+	"access_token":                  true,
+	"password":                      true,
+	"proxy_ticket.value":            true,
+	"refresh_token":                 true,
+	"remote_viewer_connection_file": true,
+	"root_password":                 true,
+	"ticket.value":                  true,
+	"token":                         true,
+	"windows_license_key":           true,
+}
+
+// SecretKeys The body attributes holding credentials and secrets, redacted
+// from the logs, as a new map that may be extended by the caller, see
+// RedactBodyKeys
+func SecretKeys() map[string]bool {
+	keys := map[string]bool{}
+	for key := range redactedKeys {
+		keys[key] = true
+	}
+	return keys
+}
+
+// lastRequestID The id of the last request sent by any connection
+var lastRequestID uint64
+
+// WithLogger Logs the requests of the connection to logger, request and
+// response headers and bodies are included when the debug level is enabled
+func WithLogger(logger *slog.Logger) ConnectionOption {
+	return func(con *Connection) {
+		con.logger = logger
+	}
+}
+
+// requestLogger The logger of the connection, debug connections without a
+// logger log to the standard logger, nil when the connection does not log
+func (con *Connection) requestLogger() *slog.Logger {
+	if con.logger != nil {
+		return con.logger
+	}
+	if con.debug {
+		if con.debugLogger != nil {
+			return con.debugLogger
+		}
+		return slog.New(slog.NewTextHandler(log.Writer(), &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return nil
+}
+
+// newRequestID An id, unique in the process, identifying a request in the log
+func newRequestID() string {
+	return strconv.FormatUint(atomic.AddUint64(&lastRequestID, 1), 10)
+}
+
+// logRequest Logs a request before it is sent
+func logRequest(logger *slog.Logger, requestID string, req *http.Request, body []byte) {
+	attrs := []slog.Attr{
+		slog.String("request_id", requestID),
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+	}
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		attrs = append(attrs, slog.Any("header", RedactHeader(req.Header)))
+		if body != nil {
+			attrs = append(attrs, slog.String("body", string(RedactBody(body))))
+		}
+	}
+	logger.LogAttrs(context.Background(), slog.LevelDebug, "ovirt request", attrs...)
+}
+
+// logResponse Logs the response to a request, faults are logged as warnings
+func logResponse(logger *slog.Logger, requestID string, resp *http.Response, body []byte, attrs ...slog.Attr) {
+	attrs = append([]slog.Attr{
+		slog.String("request_id", requestID),
+		slog.String("method", resp.Request.Method),
+		slog.String("url", resp.Request.URL.String()),
+		slog.Int("status", resp.StatusCode),
+	}, attrs...)
+	level := slog.LevelDebug
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		level = slog.LevelWarn
+	}
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		attrs = append(attrs, slog.Any("header", RedactHeader(resp.Header)))
+		if body != nil {
+			attrs = append(attrs, slog.String("body", string(RedactBody(body))))
+		}
+	}
+	logger.LogAttrs(context.Background(), level, "ovirt response", attrs...)
+}
+
+// logFailure Logs a request that did not get a response
+func logFailure(logger *slog.Logger, requestID string, req *http.Request, err error, attrs ...slog.Attr) {
+	attrs = append([]slog.Attr{
+		slog.String("request_id", requestID),
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.String("error", err.Error()),
+	}, attrs...)
+	logger.LogAttrs(context.Background(), slog.LevelError, "ovirt request failed", attrs...)
+}
+
+// RedactHeader A copy of the header with the credentials replaced by Redacted
+func RedactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, key := range redactedHeaders {
+		if _, ok := redacted[key]; ok {
+			redacted.Set(key, Redacted)
+		}
+	}
+	return redacted
+}
+
+// RedactBody A copy of the JSON or XML body with the passwords and secrets
+// replaced by Redacted, other bodies are returned as is
+func RedactBody(body []byte) []byte {
+	return RedactBodyKeys(body, redactedKeys, Redacted)
+}
+
+// RedactBodyKeys A copy of the JSON or XML body with the values of the
+// attributes named by keys replaced by replacement, other bodies are returned
+// as is. Keys are in lower case, either the name of the attribute or
+// parent.name for the attributes of the objects named parent.
+func RedactBodyKeys(body []byte, keys map[string]bool, replacement string) []byte {
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '<' {
+		if redacted, ok := redactXML(body, keys, replacement); ok {
+			return redacted
+		}
+		return body
+	}
+	var value interface{}
+	if len(body) == 0 || json.Unmarshal(body, &value) != nil {
+		return body
+	}
+	redacted, err := json.Marshal(redactValue(value, "", keys, replacement))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactedKey Whether the attribute named key of the object named parent is redacted
+func redactedKey(keys map[string]bool, parent string, key string) bool {
+	key = strings.ToLower(key)
+	return keys[key] || parent != "" && keys[strings.ToLower(parent)+"."+key]
+}
+
+func redactValue(value interface{}, parent string, keys map[string]bool, replacement string) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if redactedKey(keys, parent, key) {
+				value[key] = replacement
+			} else {
+				value[key] = redactValue(child, key, keys, replacement)
+			}
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = redactValue(child, parent, keys, replacement)
+		}
+	}
+	return value
+}
+
+// redactXML A copy of the XML body with the content of the elements named by
+// keys replaced by value, ok is false when the body is not XML or has none of
+// the elements
+func redactXML(body []byte, keys map[string]bool, value string) (redacted []byte, ok bool) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	buffer := &bytes.Buffer{}
+	encoder := xml.NewEncoder(buffer)
+	// The elements enclosing the current token, outside of redacted elements
+	parents := []string{}
+	// The depth within a redacted element, its tokens are dropped
+	depth := 0
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if depth > 0 {
+				depth++
+				continue
+			}
+			parent := ""
+			if len(parents) > 0 {
+				parent = parents[len(parents)-1]
+			}
+			if redactedKey(keys, parent, token.Name.Local) {
+				depth, ok = 1, true
+				if err = encoder.EncodeToken(token.Copy()); err != nil {
+					return nil, false
+				}
+				if err = encoder.EncodeToken(xml.CharData(value)); err != nil {
+					return nil, false
+				}
+				continue
+			}
+			parents = append(parents, token.Name.Local)
+		case xml.EndElement:
+			if depth > 1 {
+				depth--
+				continue
+			}
+			if depth == 0 && len(parents) > 0 {
+				parents = parents[:len(parents)-1]
+			}
+			depth = 0
+		default:
+			if depth > 0 {
+				continue
+			}
+		}
+		if err = encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return nil, false
+		}
+	}
+	if !ok || encoder.Flush() != nil {
+		return nil, false
+	}
+	return buffer.Bytes(), true
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+func TestLogging(t *testing.T) {
+	t.Parallel()
+	url, username, password := testCredentials(t)
+	output := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	con, err := ovirtapi.NewConnection(url, username, password, false, ovirtapi.WithLogger(logger))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	newVM := con.NewVM()
+	newVM.Name = "test-logging"
+	newVM.Initialization = &ovirtapi.Initialization{
+		RootPassword:      "secret-root-password",
+		WindowsLicenseKey: "secret-license-key",
+		CloudInit: &ovirtapi.CloudInit{
+			Users: []ovirtapi.User{{UserName: "cloud", Password: "secret-user-password"}},
+		},
+	}
+	// The engine rejects the vm without a cluster, the request is still logged
+	newVM.Save()
+	_, err = con.GetVM("does-not-exist")
+	if err == nil {
+		t.Error("Did not fail retrieving a missing vm")
+	}
+	if strings.Contains(output.String(), "secret") {
+		t.Error("Did not redact the secrets", output.String())
+	}
+	if strings.Contains(output.String(), "Basic ") {
+		t.Error("Did not redact the authorization header", output.String())
+	}
+	records := 0
+	requestIDs := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		record := map[string]interface{}{}
+		err = json.Unmarshal([]byte(line), &record)
+		if err != nil {
+			t.Fatal("Error parsing log record", line, err)
+		}
+		records++
+		requestID, _ := record["request_id"].(string)
+		requestIDs[requestID]++
+		if record["msg"] == "ovirt response" {
+			if _, ok := record["status"]; !ok {
+				t.Error("Did not log the status", line)
+			}
+			if _, ok := record["latency"]; !ok {
+				t.Error("Did not log the latency", line)
+			}
+			if record["status"] == float64(404) && record["level"] != "WARN" {
+				t.Error("Did not log the fault as a warning", line)
+			}
+		}
+	}
+	if records != 6 || len(requestIDs) != 3 {
+		t.Error("Expected a request and a response record for each of the 3 requests", output.String())
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	t.Parallel()
+	redacted := string(ovirtapi.RedactBody([]byte(`{"root_password":"a","host":{"power_management":{"password":"b"}},"users":{"user":[{"password":"c"}]}}`)))
+	if redacted != `{"host":{"power_management":{"password":"REDACTED"}},"root_password":"REDACTED","users":{"user":[{"password":"REDACTED"}]}}` {
+		t.Error("Did not redact the body", redacted)
+	}
+	if string(ovirtapi.RedactBody([]byte("not json"))) != "not json" {
+		t.Error("Changed a body that is not JSON")
+	}
+	redacted = string(ovirtapi.RedactBody([]byte(`<vm><initialization><root_password>a</root_password><windows_license_key><![CDATA[b]]></windows_license_key></initialization><password/></vm>`)))
+	if redacted != `<vm><initialization><root_password>REDACTED</root_password><windows_license_key>REDACTED</windows_license_key></initialization><password>REDACTED</password></vm>` {
+		t.Error("Did not redact the XML body", redacted)
+	}
+	if body := `<vm><name>a</name></vm>`; string(ovirtapi.RedactBody([]byte(body))) != body {
+		t.Error("Changed an XML body without secrets")
+	}
+	if redacted = string(ovirtapi.RedactBody([]byte(`{"access_token":"a"}`))); redacted != `{"access_token":"REDACTED"}` {
+		t.Error("Did not redact the token", redacted)
+	}
+	// Only the value of tickets is a secret
+	redacted = string(ovirtapi.RedactBody([]byte(`{"ticket":{"value":"a","expiry":"60"},"property":{"value":"b"}}`)))
+	if redacted != `{"property":{"value":"b"},"ticket":{"expiry":"60","value":"REDACTED"}}` {
+		t.Error("Did not redact the ticket", redacted)
+	}
+	redacted = string(ovirtapi.RedactBody([]byte(`<action><ticket><value>a</value></ticket><property><value>b</value></property></action>`)))
+	if redacted != `<action><ticket><value>REDACTED</value></ticket><property><value>b</value></property></action>` {
+		t.Error("Did not redact the XML ticket", redacted)
+	}
+	keys := ovirtapi.SecretKeys()
+	keys["secret"] = true
+	redacted = string(ovirtapi.RedactBodyKeys([]byte(`<a><secret>b</secret><password>c</password></a>`), keys, "*"))
+	if redacted != `<a><secret>*</secret><password>*</password></a>` {
+		t.Error("Did not redact the given keys", redacted)
+	}
+	if ovirtapi.SecretKeys()["secret"] {
+		t.Error("Extended the secrets of the logs")
+	}
+}
+
+func TestLoggingXML(t *testing.T) {
+	t.Parallel()
+	engine := ovirtapitest.NewEngine()
+	defer engine.Close()
+	output := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false,
+		ovirtapi.WithLogger(logger), ovirtapi.WithFormat(ovirtapi.FormatXML), ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	newVM := con.NewVM()
+	newVM.Name = "test-logging"
+	newVM.Initialization = &ovirtapi.Initialization{
+		RootPassword:      "secret-root-password",
+		WindowsLicenseKey: "secret-license-key",
+		CloudInit: &ovirtapi.CloudInit{
+			Users: []ovirtapi.User{{UserName: "cloud", Password: "secret-user-password"}},
+		},
+	}
+	// The engine rejects the vm without a cluster, the request is still logged
+	newVM.Save()
+	if !strings.Contains(output.String(), "<root_password>REDACTED</root_password>") {
+		t.Error("Did not log the XML request", output.String())
+	}
+	if strings.Contains(output.String(), "secret") {
+		t.Error("Did not redact the secrets", output.String())
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics Receives the measurements of the requests made by a connection. The
+// resource identifies the service called without the ids of the objects, e.g.
+// vms, vms/diskattachments or hosts/deactivate.
+type Metrics interface {
+	// ObserveRequest Called once per request, the status is 0 when no response was received
+	ObserveRequest(method string, resource string, status int, latency time.Duration)
+	// ObserveFault Called for every fault returned by the engine
+	ObserveFault(method string, resource string, fault Fault)
+	// ObserveRetry Called every time a request is retried
+	ObserveRetry(method string, resource string)
+}
+
+// WithMetrics Records the requests of the connection to metrics
+func WithMetrics(metrics Metrics) ConnectionOption {
+	return func(con *Connection) {
+		con.metrics = metrics
+	}
+}
+
+// resourceName The service called by a request to requestURL, "api" for the API root
+func (con *Connection) resourceName(requestURL *url.URL) string {
+	path := strings.Trim(strings.TrimPrefix(requestURL.Path, con.endPoint.Path), "/")
+	if path == "" {
+		return "api"
+	}
+	// Paths alternate collections and ids, keep the collections and actions
+	segments := strings.Split(path, "/")
+	names := []string{}
+	for i := 0; i < len(segments); i += 2 {
+		names = append(names, segments[i])
+	}
+	return strings.Join(names, "/")
+}
+
+// LatencyBuckets The upper bounds of the latency histograms of a MetricsRegistry
+var LatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Histogram The distribution of the latencies of the requests to a resource
+type Histogram struct {
+	// Counts The number of latencies up to the matching LatencyBuckets bound, the
+	// last count holds the latencies above every bound
+	Counts []int
+	Count  int
+	Sum    time.Duration
+}
+
+// MetricsKey The method and resource the metrics of a MetricsRegistry are recorded by
+type MetricsKey struct {
+	Method   string
+	Resource string
+}
+
+// MetricsRegistry A Metrics keeping the measurements in memory, to be exported
+// by the application or inspected in tests
+type MetricsRegistry struct {
+	lock      sync.Mutex
+	requests  map[MetricsKey]map[int]int
+	latencies map[MetricsKey]*Histogram
+	faults    map[MetricsKey]map[string]int
+	retries   map[MetricsKey]int
+}
+
+// NewMetricsRegistry Returns an empty registry
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{
+		requests:  map[MetricsKey]map[int]int{},
+		latencies: map[MetricsKey]*Histogram{},
+		faults:    map[MetricsKey]map[string]int{},
+		retries:   map[MetricsKey]int{},
+	}
+}
+
+// ObserveRequest Counts the request by status and records its latency
+func (registry *MetricsRegistry) ObserveRequest(method string, resource string, status int, latency time.Duration) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	key := MetricsKey{method, resource}
+	if registry.requests[key] == nil {
+		registry.requests[key] = map[int]int{}
+	}
+	registry.requests[key][status]++
+	histogram := registry.latencies[key]
+	if histogram == nil {
+		histogram = &Histogram{Counts: make([]int, len(LatencyBuckets)+1)}
+		registry.latencies[key] = histogram
+	}
+	bucket := sort.Search(len(LatencyBuckets), func(i int) bool { return latency <= LatencyBuckets[i] })
+	histogram.Counts[bucket]++
+	histogram.Count++
+	histogram.Sum += latency
+}
+
+// ObserveFault Counts the fault by reason, faults without a reason are counted by status
+func (registry *MetricsRegistry) ObserveFault(method string, resource string, fault Fault) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	key := MetricsKey{method, resource}
+	if registry.faults[key] == nil {
+		registry.faults[key] = map[string]int{}
+	}
+	reason := fault.Reason
+	if reason == "" {
+		reason = http.StatusText(fault.StatusCode)
+	}
+	registry.faults[key][reason]++
+}
+
+// ObserveRetry Counts the retry
+func (registry *MetricsRegistry) ObserveRetry(method string, resource string) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	registry.retries[MetricsKey{method, resource}]++
+}
+
+// Keys The methods and resources requests were recorded for, sorted by resource and method
+func (registry *MetricsRegistry) Keys() []MetricsKey {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	keys := []MetricsKey{}
+	for key := range registry.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Resource != keys[j].Resource {
+			return keys[i].Resource < keys[j].Resource
+		}
+		return keys[i].Method < keys[j].Method
+	})
+	return keys
+}
+
+// Requests The number of requests to the resource with the method, by status
+func (registry *MetricsRegistry) Requests(method string, resource string) map[int]int {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	requests := map[int]int{}
+	for status, count := range registry.requests[MetricsKey{method, resource}] {
+		requests[status] = count
+	}
+	return requests
+}
+
+// Latency The latency histogram of the requests to the resource with the method
+func (registry *MetricsRegistry) Latency(method string, resource string) Histogram {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	histogram := registry.latencies[MetricsKey{method, resource}]
+	if histogram == nil {
+		return Histogram{Counts: make([]int, len(LatencyBuckets)+1)}
+	}
+	return Histogram{
+		Counts: append([]int{}, histogram.Counts...),
+		Count:  histogram.Count,
+		Sum:    histogram.Sum,
+	}
+}
+
+// Faults The number of faults returned for the resource with the method, by reason
+func (registry *MetricsRegistry) Faults(method string, resource string) map[string]int {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	faults := map[string]int{}
+	for reason, count := range registry.faults[MetricsKey{method, resource}] {
+		faults[reason] = count
+	}
+	return faults
+}
+
+// Retries The number of retries of requests to the resource with the method
+func (registry *MetricsRegistry) Retries(method string, resource string) int {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	return registry.retries[MetricsKey{method, resource}]
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+	url, username, password := testCredentials(t)
+	registry := ovirtapi.NewMetricsRegistry()
+	con, err := ovirtapi.NewConnection(url, username, password, false, ovirtapi.WithMetrics(registry), ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	allVMs, err := con.GetAllVMs()
+	if err != nil || len(allVMs) == 0 {
+		t.Fatal("Error retrieving vms", err)
+	}
+	err = allVMs[0].Update()
+	if err != nil {
+		t.Fatal("Error updating vm", err)
+	}
+	_, err = con.GetVM("does-not-exist")
+	if err == nil {
+		t.Fatal("Did not fail retrieving a missing vm")
+	}
+	if registry.Requests("GET", "api")[http.StatusOK] != 1 {
+		t.Error("Did not count the API root request", registry.Requests("GET", "api"))
+	}
+	requests := registry.Requests("GET", "vms")
+	if requests[http.StatusOK] != 2 || requests[http.StatusNotFound] != 1 {
+		t.Error("Did not count the vm requests by status", requests)
+	}
+	faults := registry.Faults("GET", "vms")
+	if len(faults) != 1 {
+		t.Error("Did not count the fault by reason", faults)
+	}
+	latency := registry.Latency("GET", "vms")
+	total := 0
+	for _, count := range latency.Counts {
+		total += count
+	}
+	if latency.Count != 3 || total != 3 || latency.Sum <= 0 {
+		t.Error("Did not record the latencies", latency)
+	}
+	if len(registry.Keys()) != 2 {
+		t.Error("Unexpected metrics keys", registry.Keys())
+	}
+}
+
+func TestMetricsRegistry(t *testing.T) {
+	t.Parallel()
+	registry := ovirtapi.NewMetricsRegistry()
+	registry.ObserveRequest("POST", "vms/start", http.StatusOK, 3*time.Millisecond)
+	registry.ObserveRequest("POST", "vms/start", 0, time.Minute)
+	registry.ObserveFault("POST", "vms/start", ovirtapi.Fault{StatusCode: http.StatusServiceUnavailable})
+	registry.ObserveRetry("POST", "vms/start")
+	latency := registry.Latency("POST", "vms/start")
+	if latency.Counts[0] != 1 || latency.Counts[len(ovirtapi.LatencyBuckets)] != 1 {
+		t.Error("Did not bucket the latencies", latency)
+	}
+	if registry.Faults("POST", "vms/start")["Service Unavailable"] != 1 {
+		t.Error("Did not count the fault without reason by status", registry.Faults("POST", "vms/start"))
+	}
+	if registry.Retries("POST", "vms/start") != 1 {
+		t.Error("Did not count the retry")
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// NextRun Makes VM.Save store the changes in the configuration the VM boots
+// with next, a running VM keeps its current configuration until it is
+// restarted and NextRunConfigurationExists reports the pending changes
+func NextRun() SaveOption {
+	return func(settings *saveOptions) {
+		settings.query.Set("next_run", "true")
+	}
+}
+
+// ConfigChange A setting differing between two configurations of a VM
+type ConfigChange struct {
+	// The JSON path of the setting, such as "cpu.topology.cores", as accepted by Patch
+	Field string
+	// The value of the setting in the current configuration, nil when unset
+	Current interface{}
+	// The value of the setting in the pending configuration, nil when unset
+	Pending interface{}
+}
+
+func (change ConfigChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", change.Field, change.Current, change.Pending)
+}
+
+// runtimeFields The attributes of objects describing their state rather than
+// their configuration, they are not compared between configurations
+var runtimeFields = map[string]bool{
+	"actions":                       true,
+	"creation_time":                 true,
+	"host":                          true,
+	"href":                          true,
+	"id":                            true,
+	"link":                          true,
+	"next_run_configuration_exists": true,
+	"rel":                           true,
+	"run_once":                      true,
+	"start_time":                    true,
+	"status":                        true,
+	"stop_time":                     true,
+}
+
+// GetNextRun Retrieve the configuration the VM will have on its next boot, the
+// same as its current configuration when no change is pending
+func (vm *VM) GetNextRun() (*VM, error) {
+	if vm.Href == "" {
+		return nil, fmt.Errorf("VM has not been saved to the server")
+	}
+	href := vm.Con.ResolveLink(vm.Href)
+	href.RawQuery = url.Values{"next_run": {"true"}}.Encode()
+	body, err := vm.Con.Request("GET", href, nil)
+	if err != nil {
+		return nil, err
+	}
+	object := vm.Con.NewVM()
+	err = vm.Con.decode(body, object)
+	if err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// NextRunChanges The settings the next boot of the VM will change, comparing
+// its current configuration on the server with its next run configuration
+func (vm *VM) NextRunChanges() ([]ConfigChange, error) {
+	if vm.Href == "" {
+		return nil, fmt.Errorf("VM has not been saved to the server")
+	}
+	current, err := vm.Con.GetVM(vm.ID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := vm.GetNextRun()
+	if err != nil {
+		return nil, err
+	}
+	return current.ConfigChanges(pending), nil
+}
+
+// ConfigChanges The settings differing between the configuration of the VM and
+// the pending configuration, in the order of the attributes of VM. The state of
+// the VM, such as its status or start time, is not compared.
+func (vm *VM) ConfigChanges(pending *VM) []ConfigChange {
+	changes := []ConfigChange{}
+	diffConfig("", reflect.ValueOf(vm).Elem(), reflect.ValueOf(pending).Elem(), &changes)
+	return changes
+}
+
+// diffConfig Appends the settings differing between the values at the path to changes
+func diffConfig(path string, current reflect.Value, pending reflect.Value, changes *[]ConfigChange) {
+	if current.Kind() == reflect.Ptr {
+		if current.IsNil() || pending.IsNil() {
+			if current.IsNil() != pending.IsNil() {
+				*changes = append(*changes, ConfigChange{path, configValue(current), configValue(pending)})
+			}
+			return
+		}
+		current, pending = current.Elem(), pending.Elem()
+	}
+	if current.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(current.Interface(), pending.Interface()) {
+			*changes = append(*changes, ConfigChange{path, configValue(current), configValue(pending)})
+		}
+		return
+	}
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		if embedded(field) && field.Type.Kind() == reflect.Struct {
+			diffConfig(path, current.Field(i), pending.Field(i), changes)
+			continue
+		}
+		name := jsonName(field)
+		if field.PkgPath != "" || name == "" || path == "" && runtimeFields[name] {
+			continue
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		diffConfig(name, current.Field(i), pending.Field(i), changes)
+	}
+}
+
+// configValue The value of a setting, nil when it is unset
+func configValue(value reflect.Value) interface{} {
+	if value.IsZero() {
+		return nil
+	}
+	return value.Interface()
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+// changedFields The fields of the changes
+func changedFields(changes []ovirtapi.ConfigChange) []string {
+	fields := []string{}
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	return fields
+}
+
+func TestNextRun(t *testing.T) {
+	t.Parallel()
+	engine, con, _, vm := newChangesVM(t)
+	if err := vm.WaitForStatus("down", time.Millisecond, time.Second); err != nil {
+		t.Fatal("Error waiting for the VM to be created", err)
+	}
+	if err := vm.Start("", "", "", "", "", nil); err != nil {
+		t.Fatal("Error starting the VM", err)
+	}
+	if err := vm.WaitForStatus("up", time.Millisecond, time.Second); err != nil {
+		t.Fatal("Error waiting for the VM to start", err)
+	}
+
+	vm.Description = "next boot"
+	vm.Memory = 2147483648
+	if err := vm.Save(ovirtapi.NextRun()); err != nil {
+		t.Fatal("Error saving the next run configuration", err)
+	}
+	if vm.Memory != 1073741824 || vm.NextRunConfigurationExists != "true" {
+		t.Error("Applied the next run configuration to the running VM", vm.Memory, vm.NextRunConfigurationExists)
+	}
+	pending, err := vm.GetNextRun()
+	if err != nil {
+		t.Fatal("Error getting the next run configuration", err)
+	}
+	if pending.Memory != 2147483648 || pending.Description != "next boot" {
+		t.Error("Did not get the next run configuration", pending.Memory, pending.Description)
+	}
+	changes, err := vm.NextRunChanges()
+	if err != nil {
+		t.Fatal("Error comparing the configurations", err)
+	}
+	if fields := changedFields(changes); !reflect.DeepEqual(fields, []string{"description", "memory"}) {
+		t.Error("Did not find the pending changes", fields)
+	}
+	if changes[1].Current != 1073741824 || changes[1].Pending != 2147483648 {
+		t.Error("Did not report the values of the change", changes[1])
+	}
+
+	// Stopping the VM applies the next run configuration
+	if err = vm.Stop("false"); err != nil {
+		t.Fatal("Error stopping the VM", err)
+	}
+	if stored := engine.Get("vms", vm.ID); stored["memory"] != "2147483648" || stored["next_run_configuration_exists"] != "false" {
+		t.Error("Did not apply the next run configuration", stored["memory"], stored["next_run_configuration_exists"])
+	}
+	if changes, err = vm.NextRunChanges(); err != nil || len(changes) != 0 {
+		t.Error("Found changes after applying the next run configuration", changes, err)
+	}
+
+	// Changes to a stopped VM apply immediately
+	if err = vm.Update(); err != nil {
+		t.Fatal("Error updating the VM", err)
+	}
+	vm.Comment = "stopped"
+	if err = vm.Save(ovirtapi.NextRun()); err != nil {
+		t.Fatal("Error saving the stopped VM", err)
+	}
+	if vm.Comment != "stopped" {
+		t.Error("Did not apply the change to the stopped VM", vm.Comment)
+	}
+
+	if _, err = con.NewVM().GetNextRun(); err == nil {
+		t.Error("Got the next run configuration of a VM that was not saved")
+	}
+}
+
+func TestConfigChanges(t *testing.T) {
+	t.Parallel()
+	current := &ovirtapi.VM{Status: "up", CPU: &ovirtapi.CPU{Topology: &ovirtapi.CPUTopology{Cores: 2, Sockets: 1}}}
+	current.Name = "vm"
+	pending := &ovirtapi.VM{Status: "down", CPU: &ovirtapi.CPU{Topology: &ovirtapi.CPUTopology{Cores: 4, Sockets: 1}}, USB: &ovirtapi.USB{Enabled: "true"}}
+	pending.Name = "vm"
+	changes := current.ConfigChanges(pending)
+	if fields := changedFields(changes); !reflect.DeepEqual(fields, []string{"cpu.topology.cores", "usb"}) {
+		t.Error("Did not compare the configurations", fields)
+	}
+	if changes[1].Current != nil || changes[1].Pending != pending.USB {
+		t.Error("Did not report an unset setting as nil", changes[1])
+	}
+}
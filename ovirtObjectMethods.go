@@ -5,7 +5,6 @@
 package ovirtapi
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 )
@@ -16,7 +15,7 @@ func (con *Connection) GetCluster(id string) (*Cluster, error) {
 		return nil, err
 	}
 	object := con.NewCluster()
-	err = json.Unmarshal(body, object)
+	err = con.decode(body, object)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +31,7 @@ func (object *Cluster) Update() error {
 		return err
 	}
 	tempObject := Cluster{OvirtObject: OvirtObject{Con: object.Con}}
-	err = json.Unmarshal(body, &tempObject)
+	err = object.Con.decode(body, &tempObject)
 	if err != nil {
 		return err
 	}
@@ -46,9 +45,7 @@ func (con *Connection) GetAllClusters() ([]*Cluster, error) {
 		return nil, err
 	}
 	objects := []*Cluster{}
-	err = json.Unmarshal(body, &struct {
-		Cluster *[]*Cluster
-	}{&objects})
+	err = con.decodeList(body, &objects)
 	if err != nil {
 		return nil, err
 	}
@@ -62,14 +59,14 @@ func (con *Connection) NewCluster() *Cluster {
 	return &Cluster{OvirtObject: OvirtObject{Con: con}}
 }
 
-func (object *Cluster) Save() error {
-	body, err := json.MarshalIndent(object, "", "    ")
+func (object *Cluster) Save(options ...SaveOption) error {
+	body, err := object.Con.encodeChanges(object)
 	if err != nil {
 		return err
 	}
 	// If there is a link, it is an already saved object, we need to update it
 	if object.OvirtObject.Href != "" {
-		body, err = object.Con.Request("PUT", object.Con.ResolveLink(object.Href), body)
+		body, err = object.Con.put(object, object.Href, body, options)
 		if err != nil {
 			return err
 		}
@@ -84,7 +81,7 @@ func (object *Cluster) Save() error {
 		}
 	}
 	tempObject := Cluster{OvirtObject: OvirtObject{Con: object.Con}}
-	err = json.Unmarshal(body, &tempObject)
+	err = object.Con.decode(body, &tempObject)
 	if err != nil {
 		return err
 	}
@@ -92,13 +89,19 @@ func (object *Cluster) Save() error {
 	return nil
 }
 
+// Patch Updates the server with the fields of the object named by their JSON
+// path, such as "name" or "description", whether they were modified or not
+func (object *Cluster) Patch(fields ...string) error {
+	return object.Con.patch(object, fields)
+}
+
 func (con *Connection) GetDataCenter(id string) (*DataCenter, error) {
 	body, err := con.GetLinkBody(reflect.TypeOf(DataCenter{}).Name()+"s", id)
 	if err != nil {
 		return nil, err
 	}
 	object := con.NewDataCenter()
-	err = json.Unmarshal(body, object)
+	err = con.decode(body, object)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +117,7 @@ func (object *DataCenter) Update() error {
 		return err
 	}
 	tempObject := DataCenter{OvirtObject: OvirtObject{Con: object.Con}}
-	err = json.Unmarshal(body, &tempObject)
+	err = object.Con.decode(body, &tempObject)
 	if err != nil {
 		return err
 	}
@@ -128,9 +131,7 @@ func (con *Connection) GetAllDataCenters() ([]*DataCenter, error) {
 		return nil, err
 	}
 	objects := []*DataCenter{}
-	err = json.Unmarshal(body, &struct {
-		DataCenter *[]*DataCenter
-	}{&objects})
+	err = con.decodeList(body, &objects)
 	if err != nil {
 		return nil, err
 	}
@@ -144,14 +145,14 @@ func (con *Connection) NewDataCenter() *DataCenter {
 	return &DataCenter{OvirtObject: OvirtObject{Con: con}}
 }
 
-func (object *DataCenter) Save() error {
-	body, err := json.MarshalIndent(object, "", "    ")
+func (object *DataCenter) Save(options ...SaveOption) error {
+	body, err := object.Con.encodeChanges(object)
 	if err != nil {
 		return err
 	}
 	// If there is a link, it is an already saved object, we need to update it
 	if object.OvirtObject.Href != "" {
-		body, err = object.Con.Request("PUT", object.Con.ResolveLink(object.Href), body)
+		body, err = object.Con.put(object, object.Href, body, options)
 		if err != nil {
 			return err
 		}
@@ -166,7 +167,7 @@ func (object *DataCenter) Save() error {
 		}
 	}
 	tempObject := DataCenter{OvirtObject: OvirtObject{Con: object.Con}}
-	err = json.Unmarshal(body, &tempObject)
+	err = object.Con.decode(body, &tempObject)
 	if err != nil {
 		return err
 	}
@@ -174,13 +175,19 @@ func (object *DataCenter) Save() error {
 	return nil
 }
 
+// Patch Updates the server with the fields of the object named by their JSON
+// path, such as "name" or "description", whether they were modified or not
+func (object *DataCenter) Patch(fields ...string) error {
+	return object.Con.patch(object, fields)
+}
+
 func (con *Connection) GetTemplate(id string) (*Template, error) {
 	body, err := con.GetLinkBody(reflect.TypeOf(Template{}).Name()+"s", id)
 	if err != nil {
 		return nil, err
 	}
 	object := con.NewTemplate()
-	err = json.Unmarshal(body, object)
+	err = con.decode(body, object)
 	if err != nil {
 		return nil, err
 	}
@@ -196,7 +203,7 @@ func (object *Template) Update() error {
 		return err
 	}
 	tempObject := Template{OvirtObject: OvirtObject{Con: object.Con}}
-	err = json.Unmarshal(body, &tempObject)
+	err = object.Con.decode(body, &tempObject)
 	if err != nil {
 		return err
 	}
@@ -210,9 +217,7 @@ func (con *Connection) GetAllTemplates() ([]*Template, error) {
 		return nil, err
 	}
 	objects := []*Template{}
-	err = json.Unmarshal(body, &struct {
-		Template *[]*Template
-	}{&objects})
+	err = con.decodeList(body, &objects)
 	if err != nil {
 		return nil, err
 	}
@@ -226,14 +231,14 @@ func (con *Connection) NewTemplate() *Template {
 	return &Template{OvirtObject: OvirtObject{Con: con}}
 }
 
-func (object *Template) Save() error {
-	body, err := json.MarshalIndent(object, "", "    ")
+func (object *Template) Save(options ...SaveOption) error {
+	body, err := object.Con.encodeChanges(object)
 	if err != nil {
 		return err
 	}
 	// If there is a link, it is an already saved object, we need to update it
 	if object.OvirtObject.Href != "" {
-		body, err = object.Con.Request("PUT", object.Con.ResolveLink(object.Href), body)
+		body, err = object.Con.put(object, object.Href, body, options)
 		if err != nil {
 			return err
 		}
@@ -248,10 +253,102 @@ func (object *Template) Save() error {
 		}
 	}
 	tempObject := Template{OvirtObject: OvirtObject{Con: object.Con}}
-	err = json.Unmarshal(body, &tempObject)
+	err = object.Con.decode(body, &tempObject)
 	if err != nil {
 		return err
 	}
 	*object = tempObject
 	return nil
 }
+
+// Patch Updates the server with the fields of the object named by their JSON
+// path, such as "name" or "description", whether they were modified or not
+func (object *Template) Patch(fields ...string) error {
+	return object.Con.patch(object, fields)
+}
+
+func (con *Connection) GetTag(id string) (*Tag, error) {
+	body, err := con.GetLinkBody(reflect.TypeOf(Tag{}).Name()+"s", id)
+	if err != nil {
+		return nil, err
+	}
+	object := con.NewTag()
+	err = con.decode(body, object)
+	if err != nil {
+		return nil, err
+	}
+	return object, err
+}
+
+func (object *Tag) Update() error {
+	if object.OvirtObject.Href == "" {
+		return fmt.Errorf("Object has not been saved to the server")
+	}
+	body, err := object.Con.Request("GET", object.Con.ResolveLink(object.Href), nil)
+	if err != nil {
+		return err
+	}
+	tempObject := Tag{OvirtObject: OvirtObject{Con: object.Con}}
+	err = object.Con.decode(body, &tempObject)
+	if err != nil {
+		return err
+	}
+	*object = tempObject
+	return nil
+}
+
+func (con *Connection) GetAllTags() ([]*Tag, error) {
+	body, err := con.GetLinkBody(reflect.TypeOf(Tag{}).Name()+"s", "")
+	if err != nil {
+		return nil, err
+	}
+	objects := []*Tag{}
+	err = con.decodeList(body, &objects)
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		object.Con = con
+	}
+	return objects, err
+}
+
+func (con *Connection) NewTag() *Tag {
+	return &Tag{OvirtObject: OvirtObject{Con: con}}
+}
+
+func (object *Tag) Save(options ...SaveOption) error {
+	body, err := object.Con.encodeChanges(object)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already saved object, we need to update it
+	if object.OvirtObject.Href != "" {
+		body, err = object.Con.put(object, object.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		link, err := object.Con.GetLink(reflect.TypeOf(Tag{}).Name() + "s")
+		if err != nil {
+			return err
+		}
+		body, err = object.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempObject := Tag{OvirtObject: OvirtObject{Con: object.Con}}
+	err = object.Con.decode(body, &tempObject)
+	if err != nil {
+		return err
+	}
+	*object = tempObject
+	return nil
+}
+
+// Patch Updates the server with the fields of the object named by their JSON
+// path, such as "name" or "description", whether they were modified or not
+func (object *Tag) Patch(fields ...string) error {
+	return object.Con.patch(object, fields)
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import "net/http"
+
+// transition The change of status caused by an action.
+type transition struct {
+	// The statuses the action is allowed from.
+	from []string
+	// The status set by the action.
+	status string
+	// The statuses the object goes through on the next reads.
+	next []string
+	// The detail of the fault returned when the action is not allowed.
+	detail string
+}
+
+// transitions The actions changing the status of objects, by collection and action.
+var transitions = map[string]map[string]transition{
+	"hosts": {
+		"activate": {
+			from:   []string{"maintenance", "non_operational"},
+			status: "connecting",
+			next:   []string{"up"},
+			detail: "[Cannot activate Host. Host is not in maintenance mode.]",
+		},
+		"deactivate": {
+			from:   []string{"up", "non_operational", "non_responsive"},
+			status: "preparing_for_maintenance",
+			next:   []string{"maintenance"},
+			detail: "[Cannot switch Host to Maintenance mode. Host is not active.]",
+		},
+	},
+	"vms": {
+		"reboot": {
+			from:   []string{"up"},
+			status: "reboot_in_progress",
+			next:   []string{"up"},
+			detail: "[Cannot reboot VM. VM is not running.]",
+		},
+		"shutdown": {
+			from:   []string{"up", "powering_up"},
+			status: "powering_down",
+			next:   []string{"down"},
+			detail: "[Cannot shutdown VM. VM is not running.]",
+		},
+		"start": {
+			from:   []string{"down", "suspended"},
+			status: "powering_up",
+			next:   []string{"up"},
+			detail: "[Cannot run VM. VM is running.]",
+		},
+		"stop": {
+			from:   []string{"up", "powering_up", "powering_down", "reboot_in_progress", "paused", "saving_state", "suspended", "migrating"},
+			status: "down",
+			detail: "[Cannot stop VM. VM is not running.]",
+		},
+		"suspend": {
+			from:   []string{"up"},
+			status: "saving_state",
+			next:   []string{"suspended"},
+			detail: "[Cannot hibernate VM. VM is not running.]",
+		},
+	},
+}
+
+// doAction Performs the action on the object, actions without a known effect succeed without changes
+func (engine *Engine) doAction(href string, action string, body map[string]interface{}) (int, interface{}) {
+	object := engine.objects[href]
+	collection := collectionName(href)
+	if transition, ok := transitions[collection][action]; ok {
+		if !contains(transition.from, object["status"]) {
+			return http.StatusConflict, actionFault("Operation Failed", transition.detail)
+		}
+		object["status"] = transition.status
+		engine.pending[href] = append([]string{}, transition.next...)
+		if collection == "vms" && action != "suspend" && action != "start" {
+			// Restarting the VM applies the changes waiting for its next run
+			engine.applyNextRun(href)
+		}
+		return http.StatusOK, map[string]interface{}{"status": "complete"}
+	}
+	switch collection {
+	case "graphicsconsoles":
+		return engine.consoleAction(href, action, body)
+	case "imagetransfers":
+		return engine.transferAction(href, action)
+	}
+	switch collection + "/" + action {
+	case "vms/detach":
+		if _, ok := object["vm_pool"]; !ok {
+			return http.StatusConflict, actionFault("Operation Failed", "[Cannot detach VM from Pool. VM is not attached to a VM-Pool.]")
+		}
+		delete(object, "vm_pool")
+	case "vmpools/allocatevm":
+		for _, member := range engine.members[APIPath+"/vms"] {
+			vm := engine.objects[member]
+			vmPool, _ := vm["vm_pool"].(map[string]interface{})
+			if vmPool != nil && vmPool["href"] == href && vm["status"] == "down" {
+				vm["status"] = "powering_up"
+				engine.pending[member] = []string{"up"}
+				return http.StatusOK, map[string]interface{}{"status": "complete", "vm": reference(vm)}
+			}
+		}
+		return http.StatusConflict, actionFault("Operation Failed", "[Cannot allocate and run VM from VM-Pool. There are no available VMs in the VM-Pool.]")
+	}
+	return http.StatusOK, map[string]interface{}{"status": "complete"}
+}
+
+func contains(values []string, value interface{}) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+// Redacted The value replacing credentials and tokens in cassettes
+const Redacted = ovirtapi.Redacted
+
+// scrubbedHeaders The headers never written to a cassette
+var scrubbedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// scrubbedKeys The body attributes and query parameters redacted in a cassette,
+// the secrets redacted from the logs
+var scrubbedKeys = ovirtapi.SecretKeys()
+
+// CassetteMode Whether a cassette records or replays the conversation
+type CassetteMode int
+
+const (
+	// Recording Requests are sent to the engine and the conversation is recorded
+	Recording CassetteMode = iota
+	// Replaying Requests are answered from the recorded conversation
+	Replaying
+)
+
+// RecordedRequest A request as stored in a cassette
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse A response as stored in a cassette
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction A request and the response the engine sent back
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Cassette A http.RoundTripper recording the conversation with an engine to a
+// file, or replaying a recorded conversation without an engine
+type Cassette struct {
+	Path         string
+	Mode         CassetteMode
+	Interactions []Interaction
+	// Transport used to reach the engine when recording, http.DefaultTransport when nil
+	Transport http.RoundTripper
+	lock      sync.Mutex
+	used      []bool
+	unmatched []string
+}
+
+// NewRecorder Returns a cassette recording the requests sent through transport,
+// the conversation is written to path by Save
+func NewRecorder(path string, transport http.RoundTripper) *Cassette {
+	return &Cassette{
+		Path:      path,
+		Mode:      Recording,
+		Transport: transport,
+	}
+}
+
+// LoadCassette Returns a cassette replaying the conversation recorded in path
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cassette := &Cassette{
+		Path: path,
+		Mode: Replaying,
+	}
+	err = json.Unmarshal(data, &cassette.Interactions)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing cassette %s: %s", path, err)
+	}
+	cassette.used = make([]bool, len(cassette.Interactions))
+	return cassette, nil
+}
+
+// RoundTrip Records or replays a single request
+func (cassette *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if cassette.Mode == Replaying {
+		return cassette.replay(req, recorded)
+	}
+	transport := cassette.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	cassette.lock.Lock()
+	defer cassette.lock.Unlock()
+	cassette.Interactions = append(cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+			Body:       scrubBody(body),
+		},
+	})
+	return resp, nil
+}
+
+// replay Answers the request with the first unused interaction matching it
+func (cassette *Cassette) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	cassette.lock.Lock()
+	defer cassette.lock.Unlock()
+	for i, interaction := range cassette.Interactions {
+		if cassette.used[i] || !sameRequest(interaction.Request, recorded) {
+			continue
+		}
+		cassette.used[i] = true
+		resp := newResponse(req, interaction.Response.StatusCode, "", []byte(interaction.Response.Body))
+		resp.Header = http.Header{}
+		for key, values := range interaction.Response.Header {
+			resp.Header[key] = append([]string{}, values...)
+		}
+		return resp, nil
+	}
+	description := recorded.Method + " " + recorded.Path
+	if recorded.Query != "" {
+		description += "?" + recorded.Query
+	}
+	if recorded.Body != "" {
+		description += " " + recorded.Body
+	}
+	cassette.unmatched = append(cassette.unmatched, description)
+	return nil, fmt.Errorf("No interaction recorded in cassette %s for %s", cassette.Path, description)
+}
+
+// Save Writes the recorded conversation to the cassette file
+func (cassette *Cassette) Save() error {
+	cassette.lock.Lock()
+	defer cassette.lock.Unlock()
+	data, err := json.MarshalIndent(cassette.Interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cassette.Path, append(data, '\n'), 0644)
+}
+
+// Unmatched The requests that were not found in the cassette while replaying
+func (cassette *Cassette) Unmatched() []string {
+	cassette.lock.Lock()
+	defer cassette.lock.Unlock()
+	return append([]string{}, cassette.unmatched...)
+}
+
+// Check Returns an error describing the requests that were not found in the cassette
+func (cassette *Cassette) Check() error {
+	unmatched := cassette.Unmatched()
+	if len(unmatched) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d requests not recorded in cassette %s:\n%s", len(unmatched), cassette.Path, strings.Join(unmatched, "\n"))
+}
+
+// recordRequest The scrubbed form of the request, the request body is restored for sending
+func recordRequest(req *http.Request) (RecordedRequest, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  scrubQuery(req.URL.Query()),
+		Header: scrubHeader(req.Header),
+	}
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return recorded, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		recorded.Body = scrubBody(body)
+	}
+	return recorded, nil
+}
+
+// sameRequest Whether two requests have the same method, path, query and body
+func sameRequest(a RecordedRequest, b RecordedRequest) bool {
+	return a.Method == b.Method && a.Path == b.Path && canonicalQuery(a.Query) == canonicalQuery(b.Query) && canonicalBody(a.Body) == canonicalBody(b.Body)
+}
+
+// canonicalQuery The query without the correlation id, random for every traced operation
+func canonicalQuery(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return query
+	}
+	values.Del("correlation_id")
+	return values.Encode()
+}
+
+// canonicalBody The body with a stable attribute order when it is JSON
+func canonicalBody(body string) string {
+	var value interface{}
+	if json.Unmarshal([]byte(body), &value) != nil {
+		return body
+	}
+	canonical, _ := json.Marshal(value)
+	return string(canonical)
+}
+
+func scrubHeader(header http.Header) http.Header {
+	scrubbed := http.Header{}
+	for key, values := range header {
+		scrubbed[key] = append([]string{}, values...)
+	}
+	for _, key := range scrubbedHeaders {
+		scrubbed.Del(key)
+	}
+	if len(scrubbed) == 0 {
+		return nil
+	}
+	return scrubbed
+}
+
+func scrubQuery(query url.Values) string {
+	for key := range query {
+		if scrubbedKeys[strings.ToLower(key)] {
+			query.Set(key, Redacted)
+		}
+	}
+	return query.Encode()
+}
+
+// scrubBody Redacts the credentials and tokens of a JSON or XML body, other bodies are kept as is
+func scrubBody(body []byte) string {
+	return string(ovirtapi.RedactBodyKeys(body, scrubbedKeys, Redacted))
+}
@@ -0,0 +1,185 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+func TestCassette(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	engine := ovirtapitest.NewEngine()
+	recorder := ovirtapitest.NewRecorder(path, nil)
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, ovirtapi.WithTransport(recorder), ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("Error connecting to the engine", err)
+	}
+	vms, err := con.SearchVMs("name=vm1")
+	if err != nil || len(vms) != 1 {
+		t.Fatal("Error searching vms", vms, err)
+	}
+	err = vms[0].Update()
+	if err != nil {
+		t.Fatal("Error updating vm", err)
+	}
+	_, err = con.GetVM("does-not-exist")
+	if err == nil {
+		t.Fatal("Did not fail retrieving a missing vm")
+	}
+	err = recorder.Save()
+	if err != nil {
+		t.Fatal("Error saving cassette", err)
+	}
+	engine.Close()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("Error reading cassette", err)
+	}
+	if strings.Contains(string(data), "Authorization") || strings.Contains(string(data), "Basic ") {
+		t.Error("Did not scrub the credentials from the cassette")
+	}
+
+	player, err := ovirtapitest.LoadCassette(path)
+	if err != nil {
+		t.Fatal("Error loading cassette", err)
+	}
+	con, err = ovirtapi.NewConnection(engine.URL(), engine.Username, "replayed", false, ovirtapi.WithTransport(player), ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("Error connecting to the replayed engine", err)
+	}
+	replayed, err := con.SearchVMs("name=vm1")
+	if err != nil || len(replayed) != 1 || replayed[0].ID != vms[0].ID {
+		t.Fatal("Error replaying vm search", replayed, err)
+	}
+	err = replayed[0].Update()
+	if err != nil {
+		t.Fatal("Error replaying vm update", err)
+	}
+	_, err = con.GetVM("does-not-exist")
+	if _, ok := err.(ovirtapi.Fault); !ok {
+		t.Error("Did not replay the fault", err)
+	}
+	if err = player.Check(); err != nil {
+		t.Error("Unexpected unmatched requests", err)
+	}
+	_, err = con.SearchVMs("name=vm2")
+	if err == nil {
+		t.Error("Did not fail on a request missing from the cassette")
+	}
+	if err = player.Check(); err == nil || !strings.Contains(err.Error(), "search=name%3Dvm2") {
+		t.Error("Did not report the unmatched request", err)
+	}
+}
+
+// cassetteTracer A tracer whose spans are discarded
+type cassetteTracer struct{}
+
+type cassetteSpan struct{}
+
+func (cassetteSpan) SetAttributes(attributes ...ovirtapi.Attribute) {}
+
+func (cassetteSpan) End(err error) {}
+
+func (cassetteTracer) Start(ctx context.Context, name string, attributes ...ovirtapi.Attribute) (context.Context, ovirtapi.Span) {
+	return ctx, cassetteSpan{}
+}
+
+func TestCassetteTracing(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	engine := ovirtapitest.NewEngine()
+	recorder := ovirtapitest.NewRecorder(path, nil)
+	options := []ovirtapi.ConnectionOption{ovirtapi.WithTracer(cassetteTracer{}), ovirtapi.WithFilter(false)}
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, append(options, ovirtapi.WithTransport(recorder))...)
+	if err != nil {
+		t.Fatal("Error connecting to the engine", err)
+	}
+	vms, err := con.SearchVMs("name=vm1")
+	if err != nil || len(vms) != 1 {
+		t.Fatal("Error searching vms", vms, err)
+	}
+	if err = recorder.Save(); err != nil {
+		t.Fatal("Error saving cassette", err)
+	}
+	engine.Close()
+	data, err := ioutil.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "correlation_id") {
+		t.Fatal("Did not record traced requests", err)
+	}
+
+	player, err := ovirtapitest.LoadCassette(path)
+	if err != nil {
+		t.Fatal("Error loading cassette", err)
+	}
+	con, err = ovirtapi.NewConnection(engine.URL(), engine.Username, "replayed", false, append(options, ovirtapi.WithTransport(player))...)
+	if err != nil {
+		t.Fatal("Error connecting to the replayed engine", err)
+	}
+	replayed, err := con.SearchVMs("name=vm1")
+	if err != nil || len(replayed) != 1 || replayed[0].ID != vms[0].ID {
+		t.Fatal("Error replaying traced vm search", replayed, err)
+	}
+	if err = player.Check(); err != nil {
+		t.Error("Unexpected unmatched requests", err)
+	}
+	if _, err = con.SearchVMs("name=vm2"); err == nil {
+		t.Error("Did not fail on a request missing from the cassette")
+	}
+}
+
+func TestCassetteXML(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	engine := ovirtapitest.NewEngine()
+	recorder := ovirtapitest.NewRecorder(path, nil)
+	options := []ovirtapi.ConnectionOption{ovirtapi.WithFormat(ovirtapi.FormatXML), ovirtapi.WithFilter(false)}
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, append(options, ovirtapi.WithTransport(recorder))...)
+	if err != nil {
+		t.Fatal("Error connecting to the engine", err)
+	}
+	saveVM := func(con *ovirtapi.Connection) error {
+		vm := con.NewVM()
+		vm.Name = "test-cassette"
+		vm.Initialization = &ovirtapi.Initialization{RootPassword: "secret-root-password"}
+		return vm.Save()
+	}
+	// The engine rejects the vm without a cluster, the request is still recorded
+	if err = saveVM(con); err == nil {
+		t.Fatal("Created a vm without a cluster")
+	}
+	if err = recorder.Save(); err != nil {
+		t.Fatal("Error saving cassette", err)
+	}
+	engine.Close()
+	data, err := ioutil.ReadFile(path)
+	if err != nil || strings.Contains(string(data), "secret") || !strings.Contains(string(data), `root_password\u003eREDACTED`) {
+		t.Error("Did not scrub the XML body from the cassette", string(data), err)
+	}
+
+	player, err := ovirtapitest.LoadCassette(path)
+	if err != nil {
+		t.Fatal("Error loading cassette", err)
+	}
+	con, err = ovirtapi.NewConnection(engine.URL(), engine.Username, "replayed", false, append(options, ovirtapi.WithTransport(player))...)
+	if err != nil {
+		t.Fatal("Error connecting to the replayed engine", err)
+	}
+	if _, ok := saveVM(con).(ovirtapi.Fault); !ok {
+		t.Error("Did not replay the fault")
+	}
+	if err = player.Check(); err != nil {
+		t.Error("Unexpected unmatched requests", err)
+	}
+}
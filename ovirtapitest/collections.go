@@ -0,0 +1,179 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+// collectionInfo Describes how the engine represents the objects of a collection.
+type collectionInfo struct {
+	// The name of a single object of the collection in list responses and references.
+	element string
+	// The sub collections linked from every object of the collection.
+	links []string
+	// The actions that can be posted to every object of the collection.
+	actions []string
+	// The statuses a new object goes through, the first one is set on creation.
+	status []string
+	// The attributes the engine requires when adding an object to a top level collection.
+	required []string
+}
+
+// collections Every collection served by the engine, top level or nested, by path segment.
+var collections = map[string]collectionInfo{
+	"affinitygroups": {
+		element: "affinity_group",
+		links:   []string{"hosts", "vms"},
+	},
+	"affinitylabels": {
+		element:  "affinity_label",
+		links:    []string{"hosts", "vms"},
+		required: []string{"name"},
+	},
+	"clusters": {
+		element:  "cluster",
+		links:    []string{"affinitygroups", "cpuprofiles", "permissions"},
+		required: []string{"name", "data_center"},
+	},
+	"cpuprofiles": {
+		element:  "cpu_profile",
+		links:    []string{"permissions"},
+		required: []string{"name", "cluster"},
+	},
+	"datacenters": {
+		element:  "data_center",
+		links:    []string{"clusters", "permissions", "qoss", "quotas"},
+		required: []string{"name"},
+	},
+	"diskattachments": {
+		element: "disk_attachment",
+	},
+	"diskprofiles": {
+		element:  "disk_profile",
+		links:    []string{"permissions"},
+		required: []string{"name", "storage_domain"},
+	},
+	"disks": {
+		element:  "disk",
+		links:    []string{"permissions", "statistics"},
+		actions:  []string{"copy", "export", "move", "sparsify"},
+		status:   []string{"locked", "ok"},
+		required: []string{"provisioned_size", "format"},
+	},
+	"graphicsconsoles": {
+		element: "graphics_console",
+		actions: []string{"proxyticket", "remoteviewerconnectionfile", "ticket"},
+	},
+	"hosts": {
+		element: "host",
+		links:   []string{"affinitylabels", "nics", "permissions", "statistics", "tags"},
+		actions: []string{"activate", "approve", "commitnetconfig", "deactivate", "enrollcertificate", "fence",
+			"forceselectspm", "install", "iscsidiscover", "iscsilogin", "refresh", "upgrade", "upgradecheck"},
+		status:   []string{"installing", "up"},
+		required: []string{"name", "address"},
+	},
+	"imagetransfers": {
+		element:  "image_transfer",
+		actions:  []string{"cancel", "extend", "finalize", "pause", "resume"},
+		required: []string{"disk"},
+	},
+	"nics": {
+		element: "nic",
+	},
+	"permissions": {
+		element: "permission",
+	},
+	"qoss": {
+		element:  "qos",
+		required: []string{"name", "type"},
+	},
+	"quotaclusterlimits": {
+		element: "quota_cluster_limit",
+	},
+	"quotas": {
+		element:  "quota",
+		links:    []string{"permissions", "quotaclusterlimits", "quotastoragelimits"},
+		required: []string{"name"},
+	},
+	"quotastoragelimits": {
+		element: "quota_storage_limit",
+	},
+	"snapshots": {
+		element: "snapshot",
+	},
+	"sshpublickeys": {
+		element: "ssh_public_key",
+	},
+	"statistics": {
+		element: "statistic",
+	},
+	"storagedomains": {
+		element:  "storage_domain",
+		links:    []string{"diskprofiles", "permissions"},
+		required: []string{"name", "type"},
+	},
+	"tags": {
+		element:  "tag",
+		required: []string{"name"},
+	},
+	"templates": {
+		element:  "template",
+		links:    []string{"diskattachments", "nics", "permissions", "tags"},
+		actions:  []string{"export"},
+		status:   []string{"locked", "ok"},
+		required: []string{"name", "vm"},
+	},
+	"users": {
+		element:  "user",
+		links:    []string{"permissions", "sshpublickeys", "tags"},
+		required: []string{"user_name"},
+	},
+	"vmpools": {
+		element:  "vm_pool",
+		links:    []string{"permissions"},
+		actions:  []string{"allocatevm"},
+		required: []string{"name", "cluster", "template"},
+	},
+	"vms": {
+		element: "vm",
+		links: []string{"affinitylabels", "diskattachments", "graphicsconsoles", "nics", "permissions", "snapshots",
+			"statistics", "tags"},
+		actions: []string{"cancelmigration", "clone", "commitsnapshot", "detach", "freezefilesystems", "logon",
+			"maintenance", "migrate", "reboot", "reordermacaddresses", "shutdown", "start", "stop", "suspend",
+			"thawfilesystems", "undosnapshot"},
+		status:   []string{"image_locked", "down"},
+		required: []string{"name", "cluster", "template"},
+	},
+}
+
+// topLevel The collections linked from the API root.
+var topLevel = []string{
+	"affinitylabels",
+	"clusters",
+	"cpuprofiles",
+	"datacenters",
+	"diskprofiles",
+	"disks",
+	"hosts",
+	"imagetransfers",
+	"storagedomains",
+	"tags",
+	"templates",
+	"users",
+	"vmpools",
+	"vms",
+}
+
+// view A nested collection that lists the objects of a top level collection referencing the parent object.
+type view struct {
+	collection string
+	field      string
+}
+
+// views The nested collections that are views of top level collections, by parent and nested path segment.
+var views = map[string]view{
+	"clusters/cpuprofiles":        {collection: "cpuprofiles", field: "cluster"},
+	"datacenters/clusters":        {collection: "clusters", field: "data_center"},
+	"storagedomains/diskprofiles": {collection: "diskprofiles", field: "storage_domain"},
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// PKIPath The path the engine serves its certificates under.
+	PKIPath = "/ovirt-engine/services/pki-resource"
+	// CACertificate The certificate of the certificate authority of the engine, in PEM format.
+	CACertificate = "-----BEGIN CERTIFICATE-----\nZmFrZSBvVmlydCBlbmdpbmUgY2VydGlmaWNhdGUgYXV0aG9yaXR5\n-----END CERTIFICATE-----\n"
+	// DefaultTicketExpiry The number of seconds a console ticket is valid when the request does not say.
+	DefaultTicketExpiry = 120
+)
+
+// ticket A password issued for a graphics console.
+type ticket struct {
+	// The href of the console the ticket opens.
+	console string
+	// When the ticket stops being accepted.
+	expires time.Time
+}
+
+// createConsoles Adds the graphics consoles of a new VM, SPICE unless the display of the VM is VNC
+func (engine *Engine) createConsoles(vm map[string]interface{}) {
+	protocol := "spice"
+	if display, ok := vm["display"].(map[string]interface{}); ok && display["type"] == "vnc" {
+		protocol = "vnc"
+	}
+	href := vm["href"].(string)
+	engine.create(href+"/graphicsconsoles", href, map[string]interface{}{
+		"protocol": protocol,
+		"address":  "host1.example.com",
+		"port":     "5900",
+		"tls_port": "5901",
+	})
+}
+
+// issueTicket Issues a ticket for the console, valid for the number of seconds
+// requested in the body of the action, and returns its value and expiry
+func (engine *Engine) issueTicket(console string, body map[string]interface{}) (string, int) {
+	expiry := DefaultTicketExpiry
+	if requested, ok := body["ticket"].(map[string]interface{}); ok && requested["expiry"] != nil {
+		fmt.Sscan(fmt.Sprint(requested["expiry"]), &expiry)
+	}
+	value := strings.Replace(engine.newID(), "-", "", -1)
+	engine.tickets[value] = ticket{console: console, expires: time.Now().Add(time.Duration(expiry) * time.Second)}
+	return value, expiry
+}
+
+// ValidTicket Whether the value is a console ticket issued by the engine and not expired yet
+func (engine *Engine) ValidTicket(value string) bool {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	ticket, ok := engine.tickets[value]
+	return ok && time.Now().Before(ticket.expires)
+}
+
+// consoleAction Performs the actions of graphics consoles
+func (engine *Engine) consoleAction(href string, action string, body map[string]interface{}) (int, interface{}) {
+	console := engine.objects[href]
+	switch action {
+	case "ticket":
+		value, expiry := engine.issueTicket(href, body)
+		return http.StatusOK, map[string]interface{}{
+			"status": "complete",
+			"ticket": map[string]interface{}{"value": value, "expiry": fmt.Sprint(expiry)},
+		}
+	case "proxyticket":
+		return http.StatusOK, map[string]interface{}{
+			"status":       "complete",
+			"proxy_ticket": map[string]interface{}{"value": engine.issueProxyTicket(console)},
+		}
+	case "remoteviewerconnectionfile":
+		value, expiry := engine.issueTicket(href, nil)
+		file := fmt.Sprintf("[virt-viewer]\ntype=%s\nhost=%s\nport=%s\n", console["protocol"], console["address"], console["port"])
+		if console["protocol"] == "spice" {
+			file += fmt.Sprintf("tls-port=%s\n", console["tls_port"])
+		}
+		file += fmt.Sprintf("password=%s\n# Password is valid for %d seconds.\ndelete-this-file=1\n", value, expiry)
+		file += fmt.Sprintf("ca=%s\n", strings.Replace(CACertificate, "\n", `\n`, -1))
+		return http.StatusOK, map[string]interface{}{"status": "complete", "remote_viewer_connection_file": file}
+	}
+	return http.StatusOK, map[string]interface{}{"status": "complete"}
+}
+
+// servePKI Serves the certificate of the certificate authority of the engine
+func servePKI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("resource") != "ca-certificate" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-x509-ca-cert")
+	w.Write([]byte(CACertificate))
+}
@@ -0,0 +1,700 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+// Package ovirtapitest provides an in-process fake oVirt engine for testing code built on ovirtapi without a live engine.
+package ovirtapitest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// APIPath The path the engine serves the API under.
+	APIPath = "/ovirt-engine/api"
+	// DefaultUsername The user name accepted by a new engine.
+	DefaultUsername = "admin@internal"
+	// DefaultPassword The password accepted by a new engine.
+	DefaultPassword = "ovirt"
+	// RootTagID The ID of the root tag.
+	RootTagID = "00000000-0000-0000-0000-000000000000"
+	// BlankTemplateID The ID of the Blank template.
+	BlankTemplateID = "00000000-0000-0000-0000-000000000000"
+)
+
+// Engine A fake oVirt engine serving the API from memory.
+//
+// New objects go through the statuses a real engine reports, advancing one
+// step every time the object is read, so polling loops terminate without
+// waiting on timers.
+type Engine struct {
+	// The HTTP server the engine is listening on.
+	Server *httptest.Server
+	// The credentials the engine accepts.
+	Username string
+	Password string
+
+	lock      sync.Mutex
+	lastID    int
+	objects   map[string]map[string]interface{}
+	members   map[string][]string
+	pending   map[string][]string
+	nextRun   map[string]map[string]interface{}
+	tickets   map[string]ticket
+	proxyKey  []byte
+	phases    map[string][]string
+	transfers map[string]string
+	imageio   *ImageioServer
+	accounts  map[string]account
+	adminUser string
+	version   [4]int
+	// The caller of the request being served, set while the lock is held
+	caller caller
+}
+
+// NewEngine Start a fake engine populated with the objects of a freshly installed engine
+func NewEngine() *Engine {
+	engine := &Engine{
+		Username:  DefaultUsername,
+		Password:  DefaultPassword,
+		objects:   map[string]map[string]interface{}{},
+		members:   map[string][]string{},
+		pending:   map[string][]string{},
+		nextRun:   map[string]map[string]interface{}{},
+		tickets:   map[string]ticket{},
+		proxyKey:  make([]byte, 32),
+		phases:    map[string][]string{},
+		transfers: map[string]string{},
+		accounts:  map[string]account{},
+		version:   [4]int{4, 4, 0, 0},
+	}
+	rand.Read(engine.proxyKey)
+	engine.seed()
+	engine.Server = httptest.NewServer(engine)
+	return engine
+}
+
+// URL The API endpoint to pass to ovirtapi.NewConnection
+func (engine *Engine) URL() string {
+	return engine.Server.URL + APIPath
+}
+
+// Close Shuts the engine down
+func (engine *Engine) Close() {
+	engine.Server.Close()
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	if engine.imageio != nil {
+		engine.imageio.Close()
+	}
+}
+
+// Add Adds an object to a top level collection, bypassing validation, and returns its id.
+// A status given in the object is kept instead of the initial status of the collection.
+func (engine *Engine) Add(collection string, object map[string]interface{}) string {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	status, hasStatus := object["status"]
+	object = engine.create(APIPath+"/"+collection, "", object)
+	if hasStatus {
+		object["status"] = status
+		delete(engine.pending, object["href"].(string))
+	}
+	return object["id"].(string)
+}
+
+// Get Returns a copy of the object of the top level collection with the given id, nil if it does not exist
+func (engine *Engine) Get(collection string, id string) map[string]interface{} {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	object, ok := engine.objects[APIPath+"/"+collection+"/"+id]
+	if !ok {
+		return nil
+	}
+	return copyObject(object)
+}
+
+// SetVersion Sets the version the engine reports, 4.4.0.0 by default
+func (engine *Engine) SetVersion(major int, minor int, build int, revision int) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	engine.version = [4]int{major, minor, build, revision}
+}
+
+// SetStatus Sets the status of an object and the statuses it will go through on the next reads
+func (engine *Engine) SetStatus(collection string, id string, status string, next ...string) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	href := APIPath + "/" + collection + "/" + id
+	if object, ok := engine.objects[href]; ok {
+		object["status"] = status
+		engine.pending[href] = next
+	}
+}
+
+// Set Sets attributes of an object of a top level collection, bypassing
+// validation, such as the attributes the engine reports on its own
+func (engine *Engine) Set(collection string, id string, attributes map[string]interface{}) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	if object, ok := engine.objects[APIPath+"/"+collection+"/"+id]; ok {
+		for key, value := range attributes {
+			object[key] = value
+		}
+	}
+}
+
+func (engine *Engine) seed() {
+	dataCenter := engine.create(APIPath+"/datacenters", "", map[string]interface{}{
+		"name":           "Default",
+		"description":    "The default Data Center",
+		"local":          "false",
+		"quota_mode":     "disabled",
+		"status":         "up",
+		"storage_format": "v5",
+		"version":        map[string]interface{}{"major": "4", "minor": "4"},
+	})
+	cluster := engine.create(APIPath+"/clusters", "", map[string]interface{}{
+		"name":        "Default",
+		"description": "The default server cluster",
+		"cpu":         map[string]interface{}{"architecture": "x86_64", "type": "Intel Haswell-noTSX Family"},
+		"data_center": reference(dataCenter),
+		"version":     map[string]interface{}{"major": "4", "minor": "4"},
+	})
+	storageDomain := engine.create(APIPath+"/storagedomains", "", map[string]interface{}{
+		"name":         "data",
+		"type":         "data",
+		"storage_type": "nfs",
+		"data_centers": map[string]interface{}{"data_center": []interface{}{reference(dataCenter)}},
+	})
+	engine.create(APIPath+"/cpuprofiles", "", map[string]interface{}{
+		"name":    "Default",
+		"cluster": reference(cluster),
+	})
+	engine.create(APIPath+"/diskprofiles", "", map[string]interface{}{
+		"name":           "data",
+		"storage_domain": reference(storageDomain),
+	})
+	template := engine.create(APIPath+"/templates", "", map[string]interface{}{
+		"id":          BlankTemplateID,
+		"name":        "Blank",
+		"description": "Blank template",
+		"memory":      "1073741824",
+		"cluster":     reference(cluster),
+		"version":     map[string]interface{}{"version_name": "base version", "version_number": "1"},
+	})
+	engine.create(APIPath+"/tags", "", map[string]interface{}{
+		"id":          RootTagID,
+		"name":        "root",
+		"description": "root",
+	})
+	admin := engine.create(APIPath+"/users", "", map[string]interface{}{
+		"name":      "admin",
+		"user_name": "admin@internal-authz",
+		"namespace": "*",
+	})
+	engine.adminUser = admin["href"].(string)
+	engine.create(APIPath+"/hosts", "", map[string]interface{}{
+		"name":    "host1",
+		"address": "host1.example.com",
+		"cluster": reference(cluster),
+		"memory":  "68719476736",
+		"port":    "54321",
+		"type":    "rhel",
+	})
+	engine.create(APIPath+"/vms", "", map[string]interface{}{
+		"name":     "vm1",
+		"memory":   "1073741824",
+		"type":     "server",
+		"cluster":  reference(cluster),
+		"template": reference(template),
+	})
+	for href, object := range engine.objects {
+		info := collections[collectionName(href)]
+		if len(info.status) > 0 {
+			object["status"] = info.status[len(info.status)-1]
+			delete(engine.pending, href)
+		}
+	}
+}
+
+// ServeHTTP Serves an API request
+func (engine *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == PKIPath {
+		servePKI(w, r)
+		return
+	}
+	if r.URL.Path != APIPath && !strings.HasPrefix(r.URL.Path, APIPath+"/") {
+		writeResponse(w, r, http.StatusNotFound, fault("Not Found", r.URL.Path))
+		return
+	}
+	var body map[string]interface{}
+	reqBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeResponse(w, r, http.StatusBadRequest, fault("Bad Request", err.Error()))
+		return
+	}
+	if len(bytes.TrimSpace(reqBody)) > 0 {
+		if strings.Contains(r.Header.Get("Content-Type"), "xml") {
+			body, err = decodeXML(reqBody)
+		} else {
+			decoder := json.NewDecoder(bytes.NewReader(reqBody))
+			decoder.UseNumber()
+			err = decoder.Decode(&body)
+		}
+		if err != nil {
+			writeResponse(w, r, http.StatusBadRequest, fault("Bad Request", "Failed to parse the request body: "+err.Error()))
+			return
+		}
+	}
+	engine.lock.Lock()
+	caller, ok := engine.authenticate(r)
+	if !ok {
+		engine.lock.Unlock()
+		writeResponse(w, r, http.StatusUnauthorized, fault("Unauthorized", "Invalid user name or password"))
+		return
+	}
+	if !caller.filtered && !caller.admin {
+		engine.lock.Unlock()
+		writeResponse(w, r, http.StatusForbidden, fault("Operation Failed", "[User is not authorized to perform this action.]"))
+		return
+	}
+	engine.caller = caller
+	status, response := engine.handle(r.Method, strings.TrimSuffix(r.URL.Path, "/"), r.URL.Query(), body)
+	var respBody []byte
+	contentType := "application/json"
+	if response != nil {
+		respBody, contentType, err = marshalResponse(r, status, response)
+	}
+	engine.lock.Unlock()
+	if err != nil {
+		writeResponse(w, r, http.StatusInternalServerError, fault("Internal Server Error", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(respBody)
+}
+
+// writeResponse Writes the response in the format the request accepts
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, response interface{}) {
+	body, contentType, _ := marshalResponse(r, status, response)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func fault(reason string, detail string) map[string]interface{} {
+	return map[string]interface{}{"reason": reason, "detail": detail}
+}
+
+// actionFault The engine wraps faults of failed actions in the action
+func actionFault(reason string, detail string) map[string]interface{} {
+	return map[string]interface{}{"status": "failed", "fault": fault(reason, detail)}
+}
+
+func notFound(href string) (int, interface{}) {
+	return http.StatusNotFound, fault("Operation Failed", "Entity not found: "+href)
+}
+
+func (engine *Engine) handle(method string, href string, query url.Values, body map[string]interface{}) (int, interface{}) {
+	if href == APIPath {
+		if method != "GET" {
+			return http.StatusMethodNotAllowed, fault("Method Not Allowed", method)
+		}
+		return http.StatusOK, engine.root()
+	}
+	segments := strings.Split(strings.TrimPrefix(href, APIPath+"/"), "/")
+	if len(segments)%2 == 0 {
+		return engine.handleObject(method, href, query, body)
+	}
+	parent := ""
+	if len(segments) > 1 {
+		parent = strings.TrimSuffix(href, "/"+segments[len(segments)-1])
+		object, ok := engine.objects[parent]
+		if !ok || !engine.visible(object) {
+			return notFound(parent)
+		}
+		if method == "POST" && hasAction(object, segments[len(segments)-1]) {
+			return engine.doAction(parent, segments[len(segments)-1], body)
+		}
+	}
+	name := segments[len(segments)-1]
+	if _, ok := collections[name]; !ok {
+		return notFound(href)
+	}
+	if len(segments) == 1 && !isTopLevel(name) {
+		return notFound(href)
+	}
+	if len(segments) > 1 {
+		if view, ok := views[collectionName(parent)+"/"+name]; ok {
+			return engine.handleView(method, parent, view, query, body)
+		}
+	}
+	switch method {
+	case "GET":
+		return http.StatusOK, engine.list(href, query)
+	case "POST":
+		if body == nil {
+			return http.StatusBadRequest, fault("Bad Request", "Request body is empty")
+		}
+		if len(segments) == 1 {
+			delete(body, "id")
+			if missing := missingAttributes(collections[name], body); len(missing) > 0 {
+				return http.StatusBadRequest, fault("Incomplete parameters",
+					fmt.Sprintf("%s %v required for add", collections[name].element, missing))
+			}
+		}
+		if id, ok := body["id"].(string); ok && len(segments) > 1 {
+			if _, exists := engine.objects[href+"/"+id]; exists {
+				return http.StatusConflict, fault("Operation Failed", "Entity already exists: "+id)
+			}
+		}
+		object := engine.create(href, parent, body)
+		if engine.caller.filtered && len(segments) == 1 {
+			// The engine makes the creator of an object its owner
+			engine.grant(engine.caller.user, object["href"].(string))
+		}
+		return http.StatusCreated, copyObject(object)
+	}
+	return http.StatusMethodNotAllowed, fault("Method Not Allowed", method)
+}
+
+func (engine *Engine) handleObject(method string, href string, query url.Values, body map[string]interface{}) (int, interface{}) {
+	object, ok := engine.objects[href]
+	if !ok || !engine.visible(object) {
+		return notFound(href)
+	}
+	nextRun := query.Get("next_run") == "true" && collectionName(href) == "vms"
+	switch method {
+	case "GET":
+		engine.advance(href)
+		if pending, ok := engine.nextRun[href]; ok && nextRun {
+			return http.StatusOK, copyObject(pending)
+		}
+		return http.StatusOK, copyObject(object)
+	case "PUT":
+		if body == nil {
+			return http.StatusBadRequest, fault("Bad Request", "Request body is empty")
+		}
+		if nextRun && object["status"] != "down" {
+			// Changes to a running VM wait in the next run configuration until it is restarted
+			pending, ok := engine.nextRun[href]
+			if !ok {
+				pending = copyObject(object)
+				engine.nextRun[href] = pending
+			}
+			update(pending, body)
+			object["next_run_configuration_exists"] = "true"
+			pending["next_run_configuration_exists"] = "true"
+			return http.StatusOK, copyObject(object)
+		}
+		update(object, body)
+		return http.StatusOK, copyObject(object)
+	case "DELETE":
+		engine.remove(href)
+		return http.StatusOK, nil
+	}
+	return http.StatusMethodNotAllowed, fault("Method Not Allowed", method)
+}
+
+// update Sets the attributes of the object sent in the body of a PUT request
+func update(object map[string]interface{}, body map[string]interface{}) {
+	for key, value := range body {
+		switch key {
+		case "id", "href", "link", "actions", "status":
+			// Read only attributes are ignored by the engine
+		default:
+			object[key] = value
+		}
+	}
+}
+
+// applyNextRun Replaces the configuration of the VM with its next run configuration
+func (engine *Engine) applyNextRun(href string) {
+	pending, ok := engine.nextRun[href]
+	if !ok {
+		return
+	}
+	object := engine.objects[href]
+	for key, value := range pending {
+		switch key {
+		case "status", "next_run_configuration_exists":
+		default:
+			object[key] = value
+		}
+	}
+	object["next_run_configuration_exists"] = "false"
+	delete(engine.nextRun, href)
+}
+
+func (engine *Engine) handleView(method string, parent string, view view, query url.Values, body map[string]interface{}) (int, interface{}) {
+	href := APIPath + "/" + view.collection
+	switch method {
+	case "GET":
+		parentID := engine.objects[parent]["id"]
+		objects := []interface{}{}
+		for _, member := range engine.members[href] {
+			object := engine.objects[member]
+			if reference, ok := object[view.field].(map[string]interface{}); ok && reference["id"] == parentID {
+				if engine.visible(object) && matches(engine, object, query.Get("search")) {
+					objects = append(objects, copyObject(object))
+				}
+			}
+		}
+		return http.StatusOK, map[string]interface{}{collections[view.collection].element: objects}
+	case "POST":
+		if body == nil {
+			return http.StatusBadRequest, fault("Bad Request", "Request body is empty")
+		}
+		body[view.field] = reference(engine.objects[parent])
+		return engine.handle("POST", href, query, body)
+	}
+	return http.StatusMethodNotAllowed, fault("Method Not Allowed", method)
+}
+
+func (engine *Engine) root() map[string]interface{} {
+	links := []interface{}{}
+	for _, name := range topLevel {
+		links = append(links, map[string]interface{}{"href": APIPath + "/" + name, "rel": name})
+	}
+	count := func(collection string, activeStatus string) map[string]interface{} {
+		active := 0
+		for _, member := range engine.members[APIPath+"/"+collection] {
+			if activeStatus == "" || engine.objects[member]["status"] == activeStatus {
+				active++
+			}
+		}
+		return map[string]interface{}{
+			"active": fmt.Sprint(active),
+			"total":  fmt.Sprint(len(engine.members[APIPath+"/"+collection])),
+		}
+	}
+	return map[string]interface{}{
+		"link": links,
+		"product_info": map[string]interface{}{
+			"name":   "oVirt Engine",
+			"vendor": "ovirt.org",
+			"version": map[string]interface{}{
+				"build":        strconv.Itoa(engine.version[2]),
+				"full_version": fmt.Sprintf("%d.%d.%d.%d-fake", engine.version[0], engine.version[1], engine.version[2], engine.version[3]),
+				"major":        strconv.Itoa(engine.version[0]),
+				"minor":        strconv.Itoa(engine.version[1]),
+				"revision":     strconv.Itoa(engine.version[3]),
+			},
+		},
+		"special_objects": map[string]interface{}{
+			"link": []interface{}{
+				map[string]interface{}{"href": APIPath + "/templates/" + BlankTemplateID, "rel": "templates/blank"},
+				map[string]interface{}{"href": APIPath + "/tags/" + RootTagID, "rel": "tags/root"},
+			},
+		},
+		"summary": map[string]interface{}{
+			"hosts":           count("hosts", "up"),
+			"storage_domains": count("storagedomains", ""),
+			"users":           count("users", ""),
+			"vms":             count("vms", "up"),
+		},
+	}
+}
+
+func (engine *Engine) newID() string {
+	engine.lastID++
+	return fmt.Sprintf("%08x-0000-4000-8000-%012x", engine.lastID, engine.lastID)
+}
+
+// create Stores a new object in the collection, references to existing objects keep the id of the referenced object
+func (engine *Engine) create(collection string, parent string, object map[string]interface{}) map[string]interface{} {
+	name := collectionName(collection + "/x")
+	info := collections[name]
+	id, _ := object["id"].(string)
+	isReference := parent != "" && id != ""
+	if id == "" {
+		id = engine.newID()
+	}
+	href := collection + "/" + id
+	object["id"] = id
+	object["href"] = href
+	if !isReference {
+		links := []interface{}{}
+		for _, link := range info.links {
+			links = append(links, map[string]interface{}{"href": href + "/" + link, "rel": link})
+		}
+		object["link"] = links
+		if len(info.actions) > 0 {
+			actions := []interface{}{}
+			for _, action := range info.actions {
+				actions = append(actions, map[string]interface{}{"href": href + "/" + action, "rel": action})
+			}
+			object["actions"] = map[string]interface{}{"link": actions}
+		}
+		if len(info.status) > 0 {
+			object["status"] = info.status[0]
+			engine.pending[href] = append([]string{}, info.status[1:]...)
+		}
+	}
+	if parent != "" {
+		if _, ok := object[collections[collectionName(parent)].element]; !ok {
+			object[collections[collectionName(parent)].element] = reference(engine.objects[parent])
+		}
+	}
+	engine.objects[href] = object
+	engine.members[collection] = append(engine.members[collection], href)
+	switch name {
+	case "tags":
+		if _, ok := object["parent"]; !ok && id != RootTagID && parent == "" {
+			object["parent"] = map[string]interface{}{"href": APIPath + "/tags/" + RootTagID, "id": RootTagID}
+		}
+	case "vmpools":
+		if parent == "" {
+			engine.createPoolVMs(object)
+		}
+	case "vms":
+		if parent == "" {
+			engine.createConsoles(object)
+		}
+	case "imagetransfers":
+		engine.startTransfer(object)
+	}
+	return object
+}
+
+// createPoolVMs Adds the virtual machines of a new pool
+func (engine *Engine) createPoolVMs(pool map[string]interface{}) {
+	size := 0
+	fmt.Sscan(fmt.Sprint(pool["size"]), &size)
+	for i := 1; i <= size; i++ {
+		engine.create(APIPath+"/vms", "", map[string]interface{}{
+			"name":     fmt.Sprintf("%s-%d", pool["name"], i),
+			"cluster":  pool["cluster"],
+			"template": pool["template"],
+			"type":     "desktop",
+			"vm_pool":  reference(pool),
+		})
+	}
+}
+
+// remove Deletes the object, its nested collections and, for pools, its virtual machines
+func (engine *Engine) remove(href string) {
+	if collectionName(href) == "vmpools" {
+		for _, member := range append([]string{}, engine.members[APIPath+"/vms"]...) {
+			if vmPool, ok := engine.objects[member]["vm_pool"].(map[string]interface{}); ok && vmPool["href"] == href {
+				engine.remove(member)
+			}
+		}
+	}
+	for other := range engine.objects {
+		if other == href || strings.HasPrefix(other, href+"/") {
+			delete(engine.objects, other)
+			delete(engine.pending, other)
+			delete(engine.nextRun, other)
+			delete(engine.phases, other)
+		}
+	}
+	for collection, members := range engine.members {
+		if strings.HasPrefix(collection, href+"/") {
+			delete(engine.members, collection)
+			continue
+		}
+		for i, member := range members {
+			if member == href {
+				engine.members[collection] = append(members[:i:i], members[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// advance Moves the object to its next pending status, and image transfers to their next phase
+func (engine *Engine) advance(href string) {
+	if next := engine.phases[href]; len(next) > 0 {
+		engine.objects[href]["phase"] = next[0]
+		engine.phases[href] = next[1:]
+	}
+	next := engine.pending[href]
+	if len(next) == 0 {
+		return
+	}
+	engine.objects[href]["status"] = next[0]
+	engine.pending[href] = next[1:]
+}
+
+func (engine *Engine) list(collection string, query url.Values) map[string]interface{} {
+	objects := []interface{}{}
+	for _, member := range engine.members[collection] {
+		object := engine.objects[member]
+		if engine.visible(object) && matches(engine, object, query.Get("search")) {
+			objects = append(objects, copyObject(object))
+		}
+	}
+	return map[string]interface{}{collections[collectionName(collection+"/x")].element: objects}
+}
+
+// collectionName The name of the collection the object with the given href belongs to
+func collectionName(href string) string {
+	segments := strings.Split(href, "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	return segments[len(segments)-2]
+}
+
+func isTopLevel(name string) bool {
+	for _, topLevelName := range topLevel {
+		if name == topLevelName {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAction(object map[string]interface{}, action string) bool {
+	actions, ok := object["actions"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	links, _ := actions["link"].([]interface{})
+	for _, link := range links {
+		if link.(map[string]interface{})["rel"] == action {
+			return true
+		}
+	}
+	return false
+}
+
+func missingAttributes(info collectionInfo, object map[string]interface{}) []string {
+	missing := []string{}
+	for _, attribute := range info.required {
+		if value, ok := object[attribute]; !ok || value == "" || value == nil {
+			missing = append(missing, attribute)
+		}
+	}
+	return missing
+}
+
+// reference The representation of a link to the object
+func reference(object map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"href": object["href"], "id": object["id"]}
+}
+
+// copyObject A deep copy of the object, so responses are not changed by later requests
+func copyObject(object map[string]interface{}) map[string]interface{} {
+	body, _ := json.Marshal(object)
+	copied := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	decoder.Decode(&copied)
+	return copied
+}
@@ -0,0 +1,175 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+func TestEngine(t *testing.T) {
+	t.Parallel()
+	engine := ovirtapitest.NewEngine()
+	defer engine.Close()
+	_, err := ovirtapi.NewConnection(engine.URL(), engine.Username, "badpass", false)
+	if fault, ok := err.(ovirtapi.Fault); !ok || fault.StatusCode != http.StatusUnauthorized {
+		t.Error("Did not fail when passed bad password", err)
+	}
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("Error connecting to the engine", err)
+	}
+	if con.ProductInfo().Version.Major != "4" || con.Summary().Hosts.Total != 1 {
+		t.Error("Unexpected API root", con.ProductInfo(), con.Summary())
+	}
+	_, err = con.GetVM("does-not-exist")
+	if fault, ok := err.(ovirtapi.Fault); !ok || fault.StatusCode != http.StatusNotFound || fault.Reason == "" {
+		t.Error("Did not return a fault for a missing vm", err)
+	}
+	newVM := con.NewVM()
+	newVM.Name = "engine-vm"
+	err = newVM.Save()
+	if fault, ok := err.(ovirtapi.Fault); !ok || fault.StatusCode != http.StatusBadRequest {
+		t.Error("Did not reject a vm without cluster and template", err)
+	}
+	allClusters, err := con.GetAllClusters()
+	if err != nil || len(allClusters) != 1 {
+		t.Fatal("Error retrieving the default cluster", err)
+	}
+	newVM.Cluster = allClusters[0]
+	newVM.Template = &ovirtapi.Template{OvirtObject: ovirtapi.OvirtObject{Link: ovirtapi.Link{ID: ovirtapitest.BlankTemplateID}}}
+	err = newVM.Save()
+	if err != nil {
+		t.Fatal("Error creating vm", err)
+	}
+	for _, status := range []string{"image_locked", "down", "down"} {
+		if newVM.Status != status {
+			t.Fatalf("Expected vm status %s, got %s", status, newVM.Status)
+		}
+		err = newVM.Update()
+		if err != nil {
+			t.Fatal("Error updating vm", err)
+		}
+	}
+	err = newVM.Start("", "", "", "", "", nil)
+	if err != nil {
+		t.Fatal("Error starting vm", err)
+	}
+	if engine.Get("vms", newVM.ID)["status"] != "powering_up" {
+		t.Error("Did not start powering up the vm")
+	}
+	err = newVM.Update()
+	if err != nil {
+		t.Fatal("Error updating vm", err)
+	}
+	if newVM.Status != "up" {
+		t.Fatal("Expected vm status up, got", newVM.Status)
+	}
+	err = newVM.Start("", "", "", "", "", nil)
+	fault, ok := err.(ovirtapi.Fault)
+	if !ok || fault.StatusCode != http.StatusConflict || fault.Detail != "[Cannot run VM. VM is running.]" {
+		t.Error("Did not return the action fault when starting a running vm", err)
+	}
+	engine.SetStatus("vms", newVM.ID, "down")
+	if engine.Get("vms", newVM.ID)["status"] != "down" {
+		t.Error("Did not set the vm status")
+	}
+	err = newVM.Delete()
+	if err != nil {
+		t.Fatal("Error deleting vm", err)
+	}
+	if engine.Get("vms", newVM.ID) != nil {
+		t.Error("Did not delete the vm")
+	}
+}
+
+func TestEngineSearch(t *testing.T) {
+	t.Parallel()
+	engine := ovirtapitest.NewEngine()
+	defer engine.Close()
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("Error connecting to the engine", err)
+	}
+	engine.Add("vms", map[string]interface{}{"name": "web-1", "status": "up"})
+	engine.Add("vms", map[string]interface{}{"name": "web-2", "status": "down"})
+	vms, err := con.SearchVMs("name=web-*")
+	if err != nil || len(vms) != 2 {
+		t.Error("Did not find the vms by name prefix", vms, err)
+	}
+	vms, err = con.SearchVMs("name=web-* and status=up")
+	if err != nil || len(vms) != 1 || vms[0].Name != "web-1" {
+		t.Error("Did not find the running vm", vms, err)
+	}
+}
+
+func TestEngineFilter(t *testing.T) {
+	t.Parallel()
+	engine := ovirtapitest.NewEngine()
+	defer engine.Close()
+	userID := engine.AddUser("user1@internal", "userpass", false)
+	vmID := engine.Add("vms", map[string]interface{}{"name": "user-vm", "status": "down"})
+	engine.Grant(userID, "vms", vmID)
+
+	admin, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("Error connecting to the engine", err)
+	}
+	vms, err := admin.GetAllVMs()
+	if err != nil || len(vms) != 2 {
+		t.Error("Admin mode did not return every vm", vms, err)
+	}
+	filtered := admin.WithContext(ovirtapi.ContextWithFilter(context.Background(), true))
+	vms, err = filtered.GetAllVMs()
+	if err != nil || len(vms) != 0 {
+		t.Error("Filtered mode returned vms without permissions", vms, err)
+	}
+
+	user, err := ovirtapi.NewConnection(engine.URL(), "user1@internal", "userpass", false)
+	if err != nil {
+		t.Fatal("Error connecting to the engine as a user", err)
+	}
+	if !user.Filter() || admin.Filter() {
+		t.Error("Unexpected modes", user.Filter(), admin.Filter())
+	}
+	vms, err = user.GetAllVMs()
+	if err != nil || len(vms) != 1 || vms[0].ID != vmID {
+		t.Error("Filtered mode did not return the vm of the user", vms, err)
+	}
+	_, err = user.GetVM(vmID)
+	if err != nil {
+		t.Error("Error retrieving the vm of the user", err)
+	}
+	allClusters, err := admin.GetAllClusters()
+	if err != nil || len(allClusters) != 1 {
+		t.Fatal("Error retrieving the default cluster", err)
+	}
+	_, err = user.GetCluster(allClusters[0].ID)
+	if fault, ok := err.(ovirtapi.Fault); !ok || fault.StatusCode != http.StatusNotFound {
+		t.Error("Did not hide the cluster from the user", err)
+	}
+	unfiltered := user.WithContext(ovirtapi.ContextWithFilter(context.Background(), false))
+	_, err = unfiltered.GetAllVMs()
+	if fault, ok := err.(ovirtapi.Fault); !ok || fault.StatusCode != http.StatusForbidden {
+		t.Error("Did not reject admin mode for a user", err)
+	}
+
+	newTag := user.NewTag()
+	newTag.Name = "user-tag"
+	err = newTag.Save()
+	if err != nil {
+		t.Fatal("Error creating tag as a user", err)
+	}
+	err = newTag.Update()
+	if err != nil {
+		t.Error("The creator cannot see the tag", err)
+	}
+}
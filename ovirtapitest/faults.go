@@ -0,0 +1,228 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// FaultKind The failure injected in place of, or on top of, the engine response
+type FaultKind int
+
+const (
+	// DiskLocked A 409 fault telling the disk is locked, wrapped in the action for action requests
+	DiskLocked FaultKind = iota
+	// Unavailable A 503 response without a fault, as sent by the proxy in front of a restarting engine
+	Unavailable
+	// Slow The engine response is delayed by the Delay of the injection
+	Slow
+	// Truncated The engine response body ends after half of its content
+	Truncated
+	// Malformed The engine response body is not valid JSON
+	Malformed
+	// Dropped The connection is reset before the request reaches the engine
+	Dropped
+)
+
+// DiskLockedDetail The detail of the faults injected by DiskLocked
+const DiskLockedDetail = "[Cannot edit VM. Related operation is currently in progress. Disk is locked. Please try again later.]"
+
+// Injection A failure injected into the requests matching Method and Path
+type Injection struct {
+	Kind FaultKind
+	// The method of the affected requests, any method when empty
+	Method string
+	// The pattern, as used by path.Match, matching the path of the affected requests
+	// or one of its parents, any path when empty
+	Path string
+	// Only every Nth matching request is affected, every matching request when 0
+	Nth int
+	// The number of failures injected before the injection stops, unlimited when 0
+	Times int
+	// The delay of Slow responses
+	Delay    time.Duration
+	matched  int
+	injected int
+}
+
+// FaultInjector A http.RoundTripper injecting failures into the requests sent
+// to an engine, a cassette or any other transport
+type FaultInjector struct {
+	// Transport the requests are sent through, http.DefaultTransport when nil
+	Transport  http.RoundTripper
+	lock       sync.Mutex
+	injections []*Injection
+	injected   int
+}
+
+// NewFaultInjector Returns a fault injector sending the requests through transport
+func NewFaultInjector(transport http.RoundTripper) *FaultInjector {
+	return &FaultInjector{Transport: transport}
+}
+
+// Inject Adds an injection, injections are checked in the order they were added
+// and a request is affected by the first injection selecting it
+func (injector *FaultInjector) Inject(injection Injection) {
+	injector.lock.Lock()
+	defer injector.lock.Unlock()
+	injector.injections = append(injector.injections, &injection)
+}
+
+// Reset Removes every injection
+func (injector *FaultInjector) Reset() {
+	injector.lock.Lock()
+	defer injector.lock.Unlock()
+	injector.injections = nil
+}
+
+// Injected The number of requests a failure was injected into
+func (injector *FaultInjector) Injected() int {
+	injector.lock.Lock()
+	defer injector.lock.Unlock()
+	return injector.injected
+}
+
+// RoundTrip Sends the request, injecting the failure of the injection selecting it
+func (injector *FaultInjector) RoundTrip(req *http.Request) (*http.Response, error) {
+	injection := injector.selectInjection(req)
+	if injection == nil {
+		return injector.send(req)
+	}
+	if req.Body != nil && (injection.Kind == DiskLocked || injection.Kind == Unavailable || injection.Kind == Dropped) {
+		// The request never reaches the transport, which would close the body
+		req.Body.Close()
+	}
+	switch injection.Kind {
+	case DiskLocked:
+		response := fault("Operation Failed", DiskLockedDetail)
+		if isAction(req) {
+			response = actionFault("Operation Failed", DiskLockedDetail)
+		}
+		body, _ := json.Marshal(response)
+		return newResponse(req, http.StatusConflict, "application/json", body), nil
+	case Unavailable:
+		return newResponse(req, http.StatusServiceUnavailable, "text/html", []byte("<html><body><h1>503 Service Unavailable</h1></body></html>")), nil
+	case Slow:
+		select {
+		case <-time.After(injection.Delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		return injector.send(req)
+	case Truncated:
+		resp, err := injector.send(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body[:len(body)/2]), errorReader{io.ErrUnexpectedEOF}))
+		return resp, nil
+	case Malformed:
+		resp, err := injector.send(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		body := []byte(`{"malformed": [`)
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		resp.Header.Del("Content-Length")
+		return resp, nil
+	}
+	return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+}
+
+// selectInjection The injection affecting the request, nil if the request is sent untouched
+func (injector *FaultInjector) selectInjection(req *http.Request) *Injection {
+	injector.lock.Lock()
+	defer injector.lock.Unlock()
+	for _, injection := range injector.injections {
+		if injection.Method != "" && !strings.EqualFold(injection.Method, req.Method) {
+			continue
+		}
+		if injection.Path != "" && !matchPath(injection.Path, req.URL.Path) {
+			continue
+		}
+		if injection.Times > 0 && injection.injected >= injection.Times {
+			continue
+		}
+		injection.matched++
+		if injection.Nth > 1 && injection.matched%injection.Nth != 0 {
+			continue
+		}
+		injection.injected++
+		injector.injected++
+		return injection
+	}
+	return nil
+}
+
+func (injector *FaultInjector) send(req *http.Request) (*http.Response, error) {
+	transport := injector.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
+
+// matchPath Whether the pattern matches the path or one of its parents
+func matchPath(pattern string, requestPath string) bool {
+	for ; requestPath != "/" && requestPath != "."; requestPath = path.Dir(requestPath) {
+		if matched, _ := path.Match(pattern, requestPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isAction Whether the request posts an action, actions are the only posts not made to a collection
+func isAction(req *http.Request) bool {
+	if req.Method != "POST" {
+		return false
+	}
+	_, isCollection := collections[path.Base(req.URL.Path)]
+	return !isCollection
+}
+
+func newResponse(req *http.Request, status int, contentType string, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// errorReader A reader failing with err
+type errorReader struct {
+	err error
+}
+
+func (reader errorReader) Read(p []byte) (int, error) {
+	return 0, reader.err
+}
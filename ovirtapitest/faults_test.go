@@ -0,0 +1,82 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+func TestFaultInjector(t *testing.T) {
+	t.Parallel()
+	engine := ovirtapitest.NewEngine()
+	defer engine.Close()
+	injector := ovirtapitest.NewFaultInjector(nil)
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, ovirtapi.WithTransport(injector), ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("Error connecting to the engine", err)
+	}
+	vms, err := con.SearchVMs("name=vm1")
+	if err != nil || len(vms) != 1 {
+		t.Fatal("Error searching vms", vms, err)
+	}
+	vm := vms[0]
+
+	injector.Inject(ovirtapitest.Injection{Kind: ovirtapitest.DiskLocked, Path: ovirtapitest.APIPath + "/vms/*"})
+	err = vm.Start("", "", "", "", "", nil)
+	fault, ok := err.(ovirtapi.Fault)
+	if !ok || fault.StatusCode != http.StatusConflict || fault.Detail != ovirtapitest.DiskLockedDetail {
+		t.Error("Did not parse the action wrapped fault", err)
+	}
+	err = vm.Save()
+	fault, ok = err.(ovirtapi.Fault)
+	if !ok || fault.StatusCode != http.StatusConflict || fault.Detail != ovirtapitest.DiskLockedDetail {
+		t.Error("Did not parse the fault", err)
+	}
+	injector.Reset()
+
+	injector.Inject(ovirtapitest.Injection{Kind: ovirtapitest.Unavailable, Method: "GET", Nth: 2, Times: 1})
+	for i, expected := range []int{0, http.StatusServiceUnavailable, 0, 0} {
+		err = vm.Update()
+		fault, _ = err.(ovirtapi.Fault)
+		if fault.StatusCode != expected || (expected == 0 && err != nil) {
+			t.Errorf("Request %d: expected status %d, got %v", i+1, expected, err)
+		}
+		if expected != 0 && fault.Reason != "" {
+			t.Error("Unexpected reason in fault without body", fault)
+		}
+	}
+	if injector.Injected() != 3 {
+		t.Error("Unexpected number of injected failures", injector.Injected())
+	}
+	injector.Reset()
+
+	for _, kind := range []ovirtapitest.FaultKind{ovirtapitest.Truncated, ovirtapitest.Malformed, ovirtapitest.Dropped} {
+		injector.Inject(ovirtapitest.Injection{Kind: kind, Times: 1})
+		err = vm.Update()
+		if err == nil {
+			t.Error("Did not fail with injected fault kind", kind)
+		}
+		if _, ok := err.(ovirtapi.Fault); ok {
+			t.Error("Unexpected fault for injected fault kind", kind, err)
+		}
+	}
+
+	injector.Inject(ovirtapitest.Injection{Kind: ovirtapitest.Slow, Delay: 50 * time.Millisecond, Times: 1})
+	start := time.Now()
+	err = vm.Update()
+	if err != nil {
+		t.Error("Error updating vm through a slow response", err)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("Did not delay the response")
+	}
+}
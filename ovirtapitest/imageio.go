@@ -0,0 +1,319 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ImageioBlockSize The granularity of the extents reported by the imageio stand-in
+const ImageioBlockSize = 64 * 1024
+
+// ImageioChecksumAlgorithm The only algorithm the imageio stand-in computes
+// checksums with, it has no default algorithm unlike imageio
+const ImageioChecksumAlgorithm = "sha256"
+
+// ImageioChecksumBlockSize The size of the blocks of the image digested by
+// checksums when the request has no block_size, the default of imageio
+const ImageioChecksumBlockSize = 4 * 1024 * 1024
+
+// ImageioStats The requests served by the imageio stand-in
+type ImageioStats struct {
+	Reads        int
+	Writes       int
+	Zeroes       int
+	Flushes      int
+	Checksums    int
+	BytesRead    int64
+	BytesWritten int64
+	BytesZeroed  int64
+	// The most requests served at the same time.
+	MaxInFlight int
+}
+
+// imageioTicket The image a ticket gives access to
+type imageioTicket struct {
+	disk      string
+	direction string
+}
+
+// ImageioServer A stand-in for the imageio daemon serving the data of the
+// disks of an engine during image transfers, at /images/<ticket>
+type ImageioServer struct {
+	// The HTTP server the daemon is listening on.
+	Server *httptest.Server
+
+	lock     sync.Mutex
+	images   map[string][]byte
+	tickets  map[string]imageioTicket
+	stats    ImageioStats
+	inFlight int
+}
+
+// NewImageioServer Start an imageio stand-in without images
+func NewImageioServer() *ImageioServer {
+	imageio := &ImageioServer{
+		images:  map[string][]byte{},
+		tickets: map[string]imageioTicket{},
+	}
+	imageio.Server = httptest.NewServer(imageio)
+	return imageio
+}
+
+// Close Shuts the daemon down
+func (imageio *ImageioServer) Close() {
+	imageio.Server.Close()
+}
+
+// Image Returns a copy of the data of the disk with the given id
+func (imageio *ImageioServer) Image(diskID string) []byte {
+	imageio.lock.Lock()
+	defer imageio.lock.Unlock()
+	return append([]byte{}, imageio.images[diskID]...)
+}
+
+// SetImage Replaces the data of the disk with the given id
+func (imageio *ImageioServer) SetImage(diskID string, data []byte) {
+	imageio.lock.Lock()
+	defer imageio.lock.Unlock()
+	imageio.images[diskID] = append([]byte{}, data...)
+}
+
+// Stats The requests served since the daemon started
+func (imageio *ImageioServer) Stats() ImageioStats {
+	imageio.lock.Lock()
+	defer imageio.lock.Unlock()
+	return imageio.stats
+}
+
+// addTicket Gives access to the image of the disk, of size bytes, with the ticket
+func (imageio *ImageioServer) addTicket(ticket string, diskID string, direction string, size int) {
+	imageio.lock.Lock()
+	defer imageio.lock.Unlock()
+	imageio.tickets[ticket] = imageioTicket{disk: diskID, direction: direction}
+	image := imageio.images[diskID]
+	if len(image) < size {
+		imageio.images[diskID] = append(image, make([]byte, size-len(image))...)
+	}
+}
+
+func (imageio *ImageioServer) removeTicket(ticket string) {
+	imageio.lock.Lock()
+	defer imageio.lock.Unlock()
+	delete(imageio.tickets, ticket)
+}
+
+// imageioError Writes an error the way imageio reports them
+func imageioError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	http.Error(w, fmt.Sprintf(format, args...), status)
+}
+
+// ServeHTTP Serves the imageio API: OPTIONS, GET with ranges, PUT with
+// Content-Range, PATCH zero and flush, and GET extents and checksum
+func (imageio *ImageioServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/images/"), "/")
+	if !strings.HasPrefix(r.URL.Path, "/images/") || len(path) > 2 || len(path) == 2 && path[1] != "extents" && path[1] != "checksum" {
+		imageioError(w, http.StatusNotFound, "No resource %s", r.URL.Path)
+		return
+	}
+	imageio.lock.Lock()
+	imageio.inFlight++
+	if imageio.inFlight > imageio.stats.MaxInFlight {
+		imageio.stats.MaxInFlight = imageio.inFlight
+	}
+	imageio.lock.Unlock()
+	defer func() {
+		imageio.lock.Lock()
+		imageio.inFlight--
+		imageio.lock.Unlock()
+	}()
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		imageioError(w, http.StatusBadRequest, "Error reading request: %s", err)
+		return
+	}
+	imageio.lock.Lock()
+	defer imageio.lock.Unlock()
+	ticket, ok := imageio.tickets[path[0]]
+	if !ok {
+		imageioError(w, http.StatusForbidden, "No such ticket %s", path[0])
+		return
+	}
+	image := imageio.images[ticket.disk]
+	switch {
+	case len(path) == 2 && path[1] == "extents":
+		imageio.serveExtents(w, r, image)
+		return
+	case len(path) == 2:
+		imageio.serveChecksum(w, r, image)
+		return
+	}
+	switch r.Method {
+	case "OPTIONS":
+		writeJSON(w, map[string]interface{}{"features": []string{"extents", "zero", "flush"}, "max_readers": 8, "max_writers": 8})
+	case "GET":
+		start, end := int64(0), int64(len(image))-1
+		if header := r.Header.Get("Range"); header != "" {
+			if _, err := fmt.Sscanf(header, "bytes=%d-%d", &start, &end); err != nil || start > end || end >= int64(len(image)) {
+				imageioError(w, http.StatusRequestedRangeNotSatisfiable, "Invalid range %s", header)
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(image)))
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		imageio.stats.Reads++
+		imageio.stats.BytesRead += end - start + 1
+		w.Write(image[start : end+1])
+	case "PUT":
+		if ticket.direction != "upload" {
+			imageioError(w, http.StatusForbidden, "Ticket %s does not allow writing", path[0])
+			return
+		}
+		start := int64(0)
+		if header := r.Header.Get("Content-Range"); header != "" {
+			if _, err := fmt.Sscanf(header, "bytes %d-", &start); err != nil {
+				imageioError(w, http.StatusBadRequest, "Invalid Content-Range %s", header)
+				return
+			}
+		}
+		if start+int64(len(body)) > int64(len(image)) {
+			imageioError(w, http.StatusRequestedRangeNotSatisfiable, "Writing beyond the end of the image")
+			return
+		}
+		copy(image[start:], body)
+		imageio.stats.Writes++
+		imageio.stats.BytesWritten += int64(len(body))
+		if r.URL.Query().Get("flush") != "n" {
+			imageio.stats.Flushes++
+		}
+	case "PATCH":
+		if ticket.direction != "upload" {
+			imageioError(w, http.StatusForbidden, "Ticket %s does not allow writing", path[0])
+			return
+		}
+		request := struct {
+			Op     string `json:"op"`
+			Offset int64  `json:"offset"`
+			Size   int64  `json:"size"`
+			Flush  bool   `json:"flush"`
+		}{}
+		if err := json.Unmarshal(body, &request); err != nil {
+			imageioError(w, http.StatusBadRequest, "Invalid request: %s", err)
+			return
+		}
+		switch request.Op {
+		case "zero":
+			if request.Offset < 0 || request.Size < 0 || request.Offset+request.Size > int64(len(image)) {
+				imageioError(w, http.StatusRequestedRangeNotSatisfiable, "Zeroing beyond the end of the image")
+				return
+			}
+			copy(image[request.Offset:request.Offset+request.Size], make([]byte, request.Size))
+			imageio.stats.Zeroes++
+			imageio.stats.BytesZeroed += request.Size
+			if request.Flush {
+				imageio.stats.Flushes++
+			}
+		case "flush":
+			imageio.stats.Flushes++
+		default:
+			imageioError(w, http.StatusBadRequest, "Unsupported operation %q", request.Op)
+		}
+	default:
+		imageioError(w, http.StatusMethodNotAllowed, "Method %s not allowed", r.Method)
+	}
+}
+
+// serveExtents Lists the extents of the image, blocks of zeroes are zero extents
+func (imageio *ImageioServer) serveExtents(w http.ResponseWriter, r *http.Request, image []byte) {
+	if r.Method != "GET" {
+		imageioError(w, http.StatusMethodNotAllowed, "Method %s not allowed", r.Method)
+		return
+	}
+	type extent struct {
+		Start  int64 `json:"start"`
+		Length int64 `json:"length"`
+		Zero   bool  `json:"zero"`
+		Hole   bool  `json:"hole"`
+	}
+	extents := []extent{}
+	for start := 0; start < len(image); start += ImageioBlockSize {
+		end := start + ImageioBlockSize
+		if end > len(image) {
+			end = len(image)
+		}
+		zero := isZero(image[start:end])
+		if last := len(extents) - 1; last >= 0 && extents[last].Zero == zero {
+			extents[last].Length += int64(end - start)
+			continue
+		}
+		extents = append(extents, extent{Start: int64(start), Length: int64(end - start), Zero: zero})
+	}
+	writeJSON(w, extents)
+}
+
+// serveChecksum Computes the checksum of the whole image like imageio: the
+// digests of the blocks of the image are checksummed together
+func (imageio *ImageioServer) serveChecksum(w http.ResponseWriter, r *http.Request, image []byte) {
+	if r.Method != "GET" {
+		imageioError(w, http.StatusMethodNotAllowed, "Method %s not allowed", r.Method)
+		return
+	}
+	query := r.URL.Query()
+	if algorithm := query.Get("algorithm"); algorithm != ImageioChecksumAlgorithm {
+		imageioError(w, http.StatusBadRequest, "Unsupported algorithm %q", algorithm)
+		return
+	}
+	blockSize := ImageioChecksumBlockSize
+	if value := query.Get("block_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			imageioError(w, http.StatusBadRequest, "Invalid block_size %q", value)
+			return
+		}
+		blockSize = size
+	}
+	imageio.stats.Checksums++
+	checksum := sha256.New()
+	for start := 0; start < len(image); start += blockSize {
+		end := start + blockSize
+		if end > len(image) {
+			end = len(image)
+		}
+		digest := sha256.Sum256(image[start:end])
+		checksum.Write(digest[:])
+	}
+	writeJSON(w, map[string]interface{}{
+		"algorithm":  ImageioChecksumAlgorithm,
+		"block_size": blockSize,
+		"checksum":   hex.EncodeToString(checksum.Sum(nil)),
+	})
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	body, _ := json.Marshal(value)
+	io.WriteString(w, string(body))
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Imageio The imageio stand-in serving the image transfers of the engine,
+// started with the first transfer
+func (engine *Engine) Imageio() *ImageioServer {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	return engine.imageioServer()
+}
+
+func (engine *Engine) imageioServer() *ImageioServer {
+	if engine.imageio == nil {
+		engine.imageio = NewImageioServer()
+	}
+	return engine.imageio
+}
+
+// startTransfer Issues the imageio ticket of a new image transfer and locks its disk
+func (engine *Engine) startTransfer(transfer map[string]interface{}) {
+	href := transfer["href"].(string)
+	diskRef, _ := transfer["disk"].(map[string]interface{})
+	disk, ok := engine.objects[APIPath+"/disks/"+fmt.Sprint(diskRef["id"])]
+	if !ok {
+		transfer["phase"] = "finished_failure"
+		return
+	}
+	if transfer["direction"] == nil {
+		transfer["direction"] = "upload"
+	}
+	size := 0
+	fmt.Sscan(fmt.Sprint(disk["provisioned_size"]), &size)
+	ticket := strings.Replace(engine.newID(), "-", "", -1)
+	imageio := engine.imageioServer()
+	imageio.addTicket(ticket, disk["id"].(string), transfer["direction"].(string), size)
+	engine.transfers[href] = ticket
+	transfer["disk"] = reference(disk)
+	transfer["transfer_url"] = imageio.Server.URL + "/images/" + ticket
+	transfer["proxy_url"] = imageio.Server.URL + "/images/" + ticket
+	transfer["phase"] = "initializing"
+	engine.phases[href] = []string{"transferring"}
+	disk["status"] = "locked"
+	delete(engine.pending, disk["href"].(string))
+}
+
+// endTransfer Revokes the imageio ticket of the transfer and unlocks its disk
+func (engine *Engine) endTransfer(href string) {
+	if ticket, ok := engine.transfers[href]; ok {
+		engine.imageio.removeTicket(ticket)
+		delete(engine.transfers, href)
+	}
+	diskRef, _ := engine.objects[href]["disk"].(map[string]interface{})
+	if disk, ok := engine.objects[fmt.Sprint(diskRef["href"])]; ok {
+		disk["status"] = "ok"
+	}
+}
+
+// transferAction Performs the actions of image transfers
+func (engine *Engine) transferAction(href string, action string) (int, interface{}) {
+	transfer := engine.objects[href]
+	phase := transfer["phase"]
+	switch action {
+	case "finalize":
+		if phase != "transferring" && phase != "paused_user" {
+			return http.StatusConflict, actionFault("Operation Failed", fmt.Sprintf("[Cannot finalize the transfer in phase %s.]", phase))
+		}
+		engine.endTransfer(href)
+		transfer["phase"] = "finalizing_success"
+		engine.phases[href] = []string{"finished_success"}
+	case "cancel":
+		if strings.HasPrefix(fmt.Sprint(phase), "finished") || phase == "cancelled" {
+			return http.StatusConflict, actionFault("Operation Failed", fmt.Sprintf("[Cannot cancel the transfer in phase %s.]", phase))
+		}
+		engine.endTransfer(href)
+		transfer["phase"] = "cancelled"
+		delete(engine.phases, href)
+	case "pause":
+		if phase != "transferring" {
+			return http.StatusConflict, actionFault("Operation Failed", fmt.Sprintf("[Cannot pause the transfer in phase %s.]", phase))
+		}
+		transfer["phase"] = "paused_user"
+	case "resume":
+		if phase != "paused_user" {
+			return http.StatusConflict, actionFault("Operation Failed", fmt.Sprintf("[Cannot resume the transfer in phase %s.]", phase))
+		}
+		transfer["phase"] = "transferring"
+	}
+	return http.StatusOK, map[string]interface{}{"status": "complete"}
+}
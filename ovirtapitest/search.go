@@ -0,0 +1,135 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// matches Whether the object matches the search query, the engine supports
+// clauses of the form attribute=value joined with "and", where the attribute
+// is tag, pool or any attribute of the object, values may be quoted.
+func matches(engine *Engine, object map[string]interface{}, query string) bool {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return true
+	}
+	for _, clause := range splitClauses(query) {
+		parts := strings.SplitN(clause, "=", 2)
+		if len(parts) != 2 {
+			return false
+		}
+		attribute := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := unquote(strings.TrimSpace(parts[1]))
+		switch attribute {
+		case "tag":
+			if !engine.hasTag(object, value) {
+				return false
+			}
+		case "pool":
+			if !engine.inPool(object, value) {
+				return false
+			}
+		default:
+			if !matchValue(object[attribute], value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// splitClauses Splits the query on the "and" outside of quoted values
+func splitClauses(query string) []string {
+	clauses := []string{}
+	start, quoted := 0, false
+	for i := 0; i < len(query); i++ {
+		switch {
+		case query[i] == '\\' && quoted:
+			i++
+		case query[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(query[i:], " and "):
+			clauses = append(clauses, query[start:i])
+			i += len(" and ") - 1
+			start = i + 1
+		}
+	}
+	return append(clauses, query[start:])
+}
+
+// unquote The value of a clause without its quotes and escapes
+func unquote(value string) string {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+	unquoted := []byte{}
+	for i := 1; i < len(value)-1; i++ {
+		if value[i] == '\\' && i+1 < len(value)-1 {
+			i++
+		}
+		unquoted = append(unquoted, value[i])
+	}
+	return string(unquoted)
+}
+
+// matchValue Compares an attribute with a search value, a trailing * matches any suffix
+func matchValue(attribute interface{}, value string) bool {
+	if attribute == nil {
+		return false
+	}
+	if reference, ok := attribute.(map[string]interface{}); ok {
+		attribute = reference["name"]
+		if attribute == nil {
+			return false
+		}
+	}
+	text := fmt.Sprint(attribute)
+	if strings.HasSuffix(value, "*") {
+		return strings.HasPrefix(text, strings.TrimSuffix(value, "*"))
+	}
+	return text == value
+}
+
+// hasTag Whether the tag with the given name, or one of its descendants, is assigned to the object
+func (engine *Engine) hasTag(object map[string]interface{}, name string) bool {
+	tagIDs := map[string]bool{}
+	for _, member := range engine.members[APIPath+"/tags"] {
+		if engine.objects[member]["name"] == name {
+			tagIDs[engine.objects[member]["id"].(string)] = true
+		}
+	}
+	// Add the descendants until no more are found
+	for found := true; found; {
+		found = false
+		for _, member := range engine.members[APIPath+"/tags"] {
+			tag := engine.objects[member]
+			parent, _ := tag["parent"].(map[string]interface{})
+			if parent != nil && tagIDs[fmt.Sprint(parent["id"])] && !tagIDs[tag["id"].(string)] {
+				tagIDs[tag["id"].(string)] = true
+				found = true
+			}
+		}
+	}
+	for _, assigned := range engine.members[object["href"].(string)+"/tags"] {
+		if tagIDs[engine.objects[assigned]["id"].(string)] {
+			return true
+		}
+	}
+	return false
+}
+
+// inPool Whether the object belongs to the pool with the given name
+func (engine *Engine) inPool(object map[string]interface{}, name string) bool {
+	vmPool, ok := object["vm_pool"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	pool, ok := engine.objects[fmt.Sprint(vmPool["href"])]
+	return ok && pool["name"] == name
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// UserRole The role of the permissions given by Grant, and to the creator of
+// an object in filtered mode
+const UserRole = "UserRole"
+
+// account The credentials of a user of the engine
+type account struct {
+	password string
+	// The href of the user object
+	user string
+	// Whether the user has an administrative role on the whole system
+	admin bool
+}
+
+// caller The user making the request being served, and whether the request is filtered
+type caller struct {
+	user     string
+	admin    bool
+	filtered bool
+}
+
+// ancestors The references through which an object inherits the permissions
+// of another object, nested objects also inherit the permissions of their parent
+var ancestors = []string{"cluster", "data_center", "storage_domain", "vm_pool"}
+
+// AddUser Adds a user logging in with userName and password and returns its
+// id, only admin users may send requests that are not filtered
+func (engine *Engine) AddUser(userName string, password string, admin bool) string {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	user := engine.create(APIPath+"/users", "", map[string]interface{}{
+		"name":      userName,
+		"user_name": userName,
+		"namespace": "*",
+	})
+	engine.accounts[userName] = account{password: password, user: user["href"].(string), admin: admin}
+	return user["id"].(string)
+}
+
+// Grant Gives the user the UserRole on the object of the top level collection
+// with the given id, and on the objects inheriting its permissions
+func (engine *Engine) Grant(userID string, collection string, id string) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	engine.grant(APIPath+"/users/"+userID, APIPath+"/"+collection+"/"+id)
+}
+
+func (engine *Engine) grant(user string, href string) {
+	if _, ok := engine.objects[href]; !ok {
+		return
+	}
+	engine.create(href+"/permissions", href, map[string]interface{}{
+		"role": map[string]interface{}{"name": UserRole, "administrative": "false"},
+		"user": reference(engine.objects[user]),
+	})
+}
+
+// authenticate The caller with the credentials of the request, false when they are not valid
+func (engine *Engine) authenticate(r *http.Request) (caller, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return caller{}, false
+	}
+	filtered := r.Header.Get("Filter") == "true"
+	if username == engine.Username && password == engine.Password {
+		return caller{user: engine.adminUser, admin: true, filtered: filtered}, true
+	}
+	account, ok := engine.accounts[username]
+	if !ok || password != account.password {
+		return caller{}, false
+	}
+	return caller{user: account.user, admin: account.admin, filtered: filtered}, true
+}
+
+// visible Whether the caller may see the object, in filtered mode only the objects
+// the caller has a user role permission on, directly or inherited, are visible.
+// Administrative roles are ignored in filtered mode, as the real engine does.
+func (engine *Engine) visible(object map[string]interface{}) bool {
+	if !engine.caller.filtered {
+		return true
+	}
+	seen := map[string]bool{}
+	for hrefs := []string{object["href"].(string)}; len(hrefs) > 0; hrefs = hrefs[1:] {
+		href := hrefs[0]
+		if seen[href] {
+			continue
+		}
+		seen[href] = true
+		for _, member := range engine.members[href+"/permissions"] {
+			permission := engine.objects[member]
+			user, _ := permission["user"].(map[string]interface{})
+			role, _ := permission["role"].(map[string]interface{})
+			if user != nil && user["href"] == engine.caller.user && role != nil && role["administrative"] != "true" {
+				return true
+			}
+		}
+		if segments := strings.Split(strings.TrimPrefix(href, APIPath+"/"), "/"); len(segments) > 2 {
+			hrefs = append(hrefs, APIPath+"/"+strings.Join(segments[:len(segments)-2], "/"))
+		}
+		for _, field := range ancestors {
+			if ancestor, ok := engine.objects[href][field].(map[string]interface{}); ok {
+				if ancestorHref, ok := ancestor["href"].(string); ok {
+					hrefs = append(hrefs, ancestorHref)
+				}
+			}
+		}
+	}
+	return false
+}
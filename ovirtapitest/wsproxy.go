@@ -0,0 +1,164 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ticketTime The format of the validity of proxy tickets
+const ticketTime = "20060102150405"
+
+// websocketGUID The GUID of the websocket handshake, RFC 6455
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// signedTicket A proxy ticket, the data of the ticket is the console it opens
+// and the signature covers the fields listed in SignedFields
+type signedTicket struct {
+	Salt         string `json:"salt"`
+	ValidFrom    string `json:"validFrom"`
+	ValidTo      string `json:"validTo"`
+	Data         string `json:"data"`
+	SignedFields string `json:"signedFields"`
+	Signature    string `json:"signature"`
+}
+
+// ticketData The console a proxy ticket opens
+type ticketData struct {
+	Host      string `json:"host"`
+	Port      string `json:"port"`
+	SSLTarget bool   `json:"ssl_target"`
+}
+
+func (ticket *signedTicket) sign(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(ticket.Salt + ticket.ValidFrom + ticket.ValidTo + ticket.Data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// issueProxyTicket Signs a ticket allowing the websocket proxy to connect to the console
+func (engine *Engine) issueProxyTicket(console map[string]interface{}) string {
+	data := ticketData{Host: fmt.Sprint(console["address"]), Port: fmt.Sprint(console["port"])}
+	if console["protocol"] == "spice" {
+		data.Port, data.SSLTarget = fmt.Sprint(console["tls_port"]), true
+	}
+	encoded, _ := json.Marshal(data)
+	now := time.Now().UTC()
+	ticket := signedTicket{
+		Salt:         strings.Replace(engine.newID(), "-", "", -1),
+		ValidFrom:    now.Add(-time.Minute).Format(ticketTime),
+		ValidTo:      now.Add(DefaultTicketExpiry * time.Second).Format(ticketTime),
+		Data:         base64.StdEncoding.EncodeToString(encoded),
+		SignedFields: "salt,validFrom,validTo,data",
+	}
+	ticket.Signature = ticket.sign(engine.proxyKey)
+	body, _ := json.Marshal(ticket)
+	return base64.StdEncoding.EncodeToString(body)
+}
+
+// VerifyProxyTicket Checks the signature and validity of a proxy ticket issued
+// by the engine and returns the address of the console it opens
+func (engine *Engine) VerifyProxyTicket(value string) (string, error) {
+	body, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	ticket := signedTicket{}
+	if err = json.Unmarshal(body, &ticket); err != nil {
+		return "", err
+	}
+	if !hmac.Equal([]byte(ticket.sign(engine.proxyKey)), []byte(ticket.Signature)) {
+		return "", errors.New("Invalid ticket signature")
+	}
+	now := time.Now().UTC().Format(ticketTime)
+	if now < ticket.ValidFrom || now > ticket.ValidTo {
+		return "", errors.New("Ticket is not valid at this time")
+	}
+	encoded, err := base64.StdEncoding.DecodeString(ticket.Data)
+	if err != nil {
+		return "", err
+	}
+	data := ticketData{}
+	if err = json.Unmarshal(encoded, &data); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(data.Host, data.Port), nil
+}
+
+// WebsocketProxy A stand-in for the websocket proxy of an engine, accepting the
+// websockets opened with the proxy tickets of the engine. The handshake is
+// completed and the websocket closed, nothing is relayed to the console.
+type WebsocketProxy struct {
+	// The HTTP server the proxy is listening on.
+	Server *httptest.Server
+
+	engine  *Engine
+	lock    sync.Mutex
+	targets []string
+}
+
+// NewWebsocketProxy Start a websocket proxy accepting the proxy tickets of the engine
+func NewWebsocketProxy(engine *Engine) *WebsocketProxy {
+	proxy := &WebsocketProxy{engine: engine}
+	proxy.Server = httptest.NewServer(proxy)
+	return proxy
+}
+
+// Address The address of the proxy, host:port
+func (proxy *WebsocketProxy) Address() string {
+	return proxy.Server.Listener.Addr().String()
+}
+
+// Close Shuts the proxy down
+func (proxy *WebsocketProxy) Close() {
+	proxy.Server.Close()
+}
+
+// Targets The addresses of the consoles the proxy accepted websockets for
+func (proxy *WebsocketProxy) Targets() []string {
+	proxy.lock.Lock()
+	defer proxy.lock.Unlock()
+	return append([]string{}, proxy.targets...)
+}
+
+// ServeHTTP Accepts a websocket whose path is a valid proxy ticket
+func (proxy *WebsocketProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "Not a websocket handshake", http.StatusBadRequest)
+		return
+	}
+	target, err := proxy.engine.VerifyProxyTicket(strings.TrimPrefix(r.URL.Path, "/"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	proxy.lock.Lock()
+	proxy.targets = append(proxy.targets, target)
+	proxy.lock.Unlock()
+	conn, buffer, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	accept := sha1.Sum([]byte(key + websocketGUID))
+	fmt.Fprintf(buffer, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(accept[:]))
+	buffer.Flush()
+}
@@ -0,0 +1,179 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xmlAttributes The attributes of the XML representation of objects and links
+var xmlAttributes = map[string]bool{"href": true, "id": true, "rel": true}
+
+// xmlDates The timestamps, milliseconds since the epoch in JSON, the XML representation sends as dates
+var xmlDates = map[string]bool{"creation_time": true, "start_time": true, "stop_time": true}
+
+// xmlDateFormat The format of the dates of the XML representation
+const xmlDateFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// acceptsXML Whether the response to the request is sent as XML
+func acceptsXML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "xml")
+}
+
+// marshalResponse The body and content type of the response to the request, in
+// the format the request accepts
+func marshalResponse(r *http.Request, status int, response interface{}) ([]byte, string, error) {
+	if !acceptsXML(r) {
+		body, err := json.MarshalIndent(response, "", "  ")
+		return body, "application/json", err
+	}
+	buffer := &bytes.Buffer{}
+	encoder := xml.NewEncoder(buffer)
+	encoder.Indent("", "  ")
+	err := encodeXML(encoder, rootElement(r.Method, strings.TrimSuffix(r.URL.Path, "/"), status, response), response)
+	if err == nil {
+		err = encoder.Flush()
+	}
+	return buffer.Bytes(), "application/xml", err
+}
+
+// rootElement The name of the root element of the response to a request of href
+func rootElement(method string, href string, status int, response interface{}) string {
+	if href == APIPath {
+		return "api"
+	}
+	if object, ok := response.(map[string]interface{}); ok && status >= 400 {
+		if _, ok := object["reason"]; ok {
+			return "fault"
+		}
+	}
+	segments := strings.Split(href, "/")
+	if info, ok := collections[segments[len(segments)-1]]; ok {
+		if method == "GET" {
+			return info.element + "s"
+		}
+		return info.element
+	}
+	if info, ok := collections[collectionName(href)]; ok {
+		return info.element
+	}
+	return "action"
+}
+
+// encodeXML Encodes the value of a decoded JSON document as the element name,
+// lists are repeated elements and the href, id and rel strings are attributes
+func encodeXML(encoder *xml.Encoder, name string, value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, item := range value {
+			if err := encodeXML(encoder, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		start := xml.StartElement{Name: xml.Name{Local: name}}
+		keys := []string{}
+		for key, field := range value {
+			if text, ok := field.(string); ok && xmlAttributes[key] {
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: key}, Value: text})
+			} else {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(start.Attr, func(i, j int) bool { return start.Attr[i].Name.Local < start.Attr[j].Name.Local })
+		sort.Strings(keys)
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := encodeXML(encoder, key, value[key]); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	default:
+		text := fmt.Sprint(value)
+		if milliseconds, err := strconv.ParseInt(text, 10, 64); err == nil && xmlDates[name] {
+			text = time.UnixMilli(milliseconds).UTC().Format(xmlDateFormat)
+		}
+		return encoder.EncodeElement(text, xml.StartElement{Name: xml.Name{Local: name}})
+	}
+}
+
+// decodeXML Decodes an XML request body into the representation of the same
+// JSON body. Elements with attributes or children are objects, other elements
+// are strings, repeated elements, links and the elements of a list wrapper
+// such as disk_attachments>disk_attachment are lists.
+func decodeXML(body []byte) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := decodeXMLElement(decoder, start)
+			if object, ok := value.(map[string]interface{}); ok {
+				return object, err
+			}
+			return map[string]interface{}{}, err
+		}
+	}
+}
+
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	object := map[string]interface{}{}
+	for _, attr := range start.Attr {
+		object[attr.Name.Local] = attr.Value
+	}
+	text := ""
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			value, err := decodeXMLElement(decoder, token)
+			if err != nil {
+				return nil, err
+			}
+			name := token.Name.Local
+			if existing, ok := object[name]; ok {
+				if list, ok := existing.([]interface{}); ok {
+					object[name] = append(list, value)
+				} else {
+					object[name] = []interface{}{existing, value}
+				}
+			} else if name == "link" || start.Name.Local == name+"s" {
+				object[name] = []interface{}{value}
+			} else if date, err := time.Parse(time.RFC3339Nano, fmt.Sprint(value)); err == nil && xmlDates[name] {
+				object[name] = json.Number(strconv.FormatInt(date.UnixMilli(), 10))
+			} else {
+				object[name] = value
+			}
+		case xml.CharData:
+			text += string(token)
+		case xml.EndElement:
+			if len(object) == 0 {
+				return text, nil
+			}
+			return object, nil
+		}
+	}
+}
@@ -7,123 +7,166 @@
 package ovirtapi
 
 import (
-	"encoding/json"
 	"errors"
 	"net/url"
 	"reflect"
+	"strings"
 )
 
 type Link struct {
-	Href string `json:"href,omitempty"`
-	Rel  string `json:"rel,omitempty"`
-	ID   string `json:"id,omitempty"`
+	Href string `json:"href,omitempty" xml:"href,attr,omitempty"`
+	Rel  string `json:"rel,omitempty" xml:"rel,attr,omitempty"`
+	ID   string `json:"id,omitempty" xml:"id,attr,omitempty"`
 }
 
+// OvirtObject The attributes and connection shared by every object of the API.
+// Objects are not safe for concurrent modification, Update and Save replace the
+// whole object, goroutines sharing a connection should each retrieve their own
+// copy of an object. Objects remember how they were when loaded from the engine,
+// Save sends only the fields modified since and Patch only the fields named.
 type OvirtObject struct {
 	Link
-	Con         *Connection `json:"-"`
-	Name        string      `json:"name,omitempty"`
-	Description string      `json:"description,omitempty"`
-	Actions     *Actions    `json:"actions,omitempty"`
-	Links       []Link      `json:"link,omitempty"`
+	Con         *Connection `json:"-" xml:"-"`
+	Name        string      `json:"name,omitempty" xml:"name,omitempty"`
+	Description string      `json:"description,omitempty" xml:"description,omitempty"`
+	Actions     *Actions    `json:"actions,omitempty" xml:"actions,omitempty"`
+	Links       []Link      `json:"link,omitempty" xml:"link,omitempty"`
+	snapshot    []byte
 }
 
 type Actions struct {
-	Links []Link `json:"link,omitempty"`
+	Links []Link `json:"link,omitempty" xml:"link,omitempty"`
 }
 
 type Action struct {
-	AllowPartialImport string         `json:"allow_partial_import,omitempty"`
-	Async              string         `json:"async,omitempty"`
-	Bricks             []GlusterBrick `json:"bricks,omitempty"`
-	Certificates       []Certificate  `json:"certificates,omitempty"`
-	CheckConnectivity  string         `json:"check_connectivity,omitempty"`
-	Clone              string         `json:"clone,omitempty"`
-	Cluster            *Cluster       `json:"cluster,omitempty"`
-	CollapseSnapshots  string         `json:"collapse_snapshots,omitempty"`
+	AllowPartialImport string         `json:"allow_partial_import,omitempty" xml:"allow_partial_import,omitempty"`
+	Async              string         `json:"async,omitempty" xml:"async,omitempty"`
+	Bricks             []GlusterBrick `json:"bricks>brick,omitempty" xml:"bricks>brick,omitempty"`
+	Certificates       []Certificate  `json:"certificates>certificate,omitempty" xml:"certificates>certificate,omitempty"`
+	CheckConnectivity  string         `json:"check_connectivity,omitempty" xml:"check_connectivity,omitempty"`
+	Clone              string         `json:"clone,omitempty" xml:"clone,omitempty"`
+	Cluster            *Cluster       `json:"cluster,omitempty" xml:"cluster,omitempty"`
+	CollapseSnapshots  string         `json:"collapse_snapshots,omitempty" xml:"collapse_snapshots,omitempty"`
 	// Free text containing comments about this object.
-	Comment             string      `json:"comment,omitempty"`
-	ConnectivityTimeout int         `json:"connectivity_timeout,omitempty"`
-	DataCenter          *DataCenter `json:"data_center,omitempty"`
-	DeployHostedEngine  string      `json:"deploy_hosted_engine,omitempty"`
+	Comment             string      `json:"comment,omitempty" xml:"comment,omitempty"`
+	ConnectivityTimeout int         `json:"connectivity_timeout,omitempty" xml:"connectivity_timeout,omitempty"`
+	DataCenter          *DataCenter `json:"data_center,omitempty" xml:"data_center,omitempty"`
+	DeployHostedEngine  string      `json:"deploy_hosted_engine,omitempty" xml:"deploy_hosted_engine,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
 	// TODO: Details          GlusterVolumeProfileDetails `json:"details,omitempty"`
-	DiscardSnapshots string `json:"discard_snapshots,omitempty"`
-	Disk             *Disk  `json:"disk,omitempty"`
+	DiscardSnapshots string `json:"discard_snapshots,omitempty" xml:"discard_snapshots,omitempty"`
+	Disk             *Disk  `json:"disk,omitempty" xml:"disk,omitempty"`
 	// TODO: Disks            []Disk                      `json:"disks,omitempty"`
-	Exclusive string `json:"exclusive,omitempty"`
-	Fault     *Fault `json:"fault,omitempty"`
-	FenceType string `json:"fence_type,omitempty"`
-	Filter    string `json:"filter,omitempty"`
-	FixLayout string `json:"fix_layout,omitempty"`
-	Force     string `json:"force,omitempty"`
+	Exclusive string `json:"exclusive,omitempty" xml:"exclusive,omitempty"`
+	Fault     *Fault `json:"fault,omitempty" xml:"fault,omitempty"`
+	FenceType string `json:"fence_type,omitempty" xml:"fence_type,omitempty"`
+	Filter    string `json:"filter,omitempty" xml:"filter,omitempty"`
+	FixLayout string `json:"fix_layout,omitempty" xml:"fix_layout,omitempty"`
+	Force     string `json:"force,omitempty" xml:"force,omitempty"`
 	// TODO: GracePeriod      GracePeriod                 `json:"grace_period,omitempty"`
-	Host *Host `json:"host,omitempty"`
+	Host *Host `json:"host,omitempty" xml:"host,omitempty"`
 	// A unique identifier.
-	ID               string        `json:"_i_d,omitempty"`
-	Image            string        `json:"image,omitempty"`
-	ImportAsTemplate string        `json:"import_as_template,omitempty"`
-	IsAttached       string        `json:"is_attached,omitempty"`
-	ISCSI            *ISCSIDetails `json:"iscsi,omitempty"`
-	IscsiTargets     []string      `json:"iscsi_targets,omitempty"`
+	ID               string        `json:"_i_d,omitempty" xml:"_i_d,omitempty"`
+	Image            string        `json:"image,omitempty" xml:"image,omitempty"`
+	ImportAsTemplate string        `json:"import_as_template,omitempty" xml:"import_as_template,omitempty"`
+	IsAttached       string        `json:"is_attached,omitempty" xml:"is_attached,omitempty"`
+	ISCSI            *ISCSIDetails `json:"iscsi,omitempty" xml:"iscsi,omitempty"`
+	IscsiTargets     []string      `json:"iscsi_targets>iscsi_target,omitempty" xml:"iscsi_targets>iscsi_target,omitempty"`
 	// TODO: Job                        Job                 `json:"job,omitempty"`
 	// TODO: LogicalUnits               []LogicalUnit       `json:"logical_units,omitempty"`
-	MaintenanceEnabled string `json:"maintenance_enabled,omitempty"`
+	MaintenanceEnabled string `json:"maintenance_enabled,omitempty" xml:"maintenance_enabled,omitempty"`
 	// TODO: ModifiedBonds              []HostNic           `json:"modified_bonds,omitempty"`
 	// TODO: ModifiedLabels             []NetworkLabel      `json:"modified_labels,omitempty"`
 	// TODO: ModifiedNetworkAttachments []NetworkAttachment `json:"modified_network_attachments,omitempty"`
 	// A human-readable name in plain text.
-	Name            string           `json:"name,omitempty"`
-	Option          *Option          `json:"option,omitempty"`
-	Pause           string           `json:"pause,omitempty"`
-	PowerManagement *PowerManagement `json:"power_management,omitempty"`
-	// TODO: ProxyTicket                    ProxyTicket                          `json:"proxy_ticket,omitempty"`
-	Reason                     string `json:"reason,omitempty"`
-	ReassignBadMacs            string `json:"reassign_bad_macs,omitempty"`
-	RemoteViewerConnectionFile string `json:"remote_viewer_connection_file,omitempty"`
+	Name                       string           `json:"name,omitempty" xml:"name,omitempty"`
+	Option                     *Option          `json:"option,omitempty" xml:"option,omitempty"`
+	Pause                      string           `json:"pause,omitempty" xml:"pause,omitempty"`
+	PowerManagement            *PowerManagement `json:"power_management,omitempty" xml:"power_management,omitempty"`
+	ProxyTicket                *ProxyTicket     `json:"proxy_ticket,omitempty" xml:"proxy_ticket,omitempty"`
+	Reason                     string           `json:"reason,omitempty" xml:"reason,omitempty"`
+	ReassignBadMacs            string           `json:"reassign_bad_macs,omitempty" xml:"reassign_bad_macs,omitempty"`
+	RemoteViewerConnectionFile string           `json:"remote_viewer_connection_file,omitempty" xml:"remote_viewer_connection_file,omitempty"`
 	// TODO: RemovedBonds                   []HostNic                            `json:"removed_bonds,omitempty"`
 	// TODO: RemovedLabels                  []NetworkLabel                       `json:"removed_labels,omitempty"`
 	// TODO: RemovedNetworkAttachments      []NetworkAttachment                  `json:"removed_network_attachments,omitempty"`
-	ResolutionType string `json:"resolution_type,omitempty"`
-	RestoreMemory  string `json:"restore_memory,omitempty"`
-	RootPassword   string `json:"root_password,omitempty"`
+	ResolutionType string `json:"resolution_type,omitempty" xml:"resolution_type,omitempty"`
+	RestoreMemory  string `json:"restore_memory,omitempty" xml:"restore_memory,omitempty"`
+	RootPassword   string `json:"root_password,omitempty" xml:"root_password,omitempty"`
 	// TODO: Snapshot                       Snapshot                             `json:"snapshot,omitempty"`
-	SSH                *SSH   `json:"ssh,omitempty"`
-	Status             string `json:"status,omitempty"`
-	StopGlusterService string `json:"stop_gluster_service,omitempty"`
+	SSH                *SSH   `json:"ssh,omitempty" xml:"ssh,omitempty"`
+	Status             string `json:"status,omitempty" xml:"status,omitempty"`
+	StopGlusterService string `json:"stop_gluster_service,omitempty" xml:"stop_gluster_service,omitempty"`
 	// TODO: StorageDomain      *StorageDomain `json:"storage_domain,omitempty"`
 	// TODO: StorageDomains                 []StorageDomain                      `json:"storage_domains,omitempty"`
-	Succeeded string `json:"succeeded,omitempty"`
+	Succeeded string `json:"succeeded,omitempty" xml:"succeeded,omitempty"`
 	// TODO: SynchronizedNetworkAttachments []NetworkAttachment                  `json:"synchronized_network_attachments,omitempty"`
-	Template *Template `json:"template,omitempty"`
-	// TODO: Ticket                         Ticket                               `json:"ticket,omitempty"`
-	UnDeployHostedEngine string `json:"undeploy_hosted_engine,omitempty"`
-	UseCloudInit         string `json:"use_cloud_init,omitempty"`
-	UseSysPrep           string `json:"use_sysprep,omitempty"`
+	Template             *Template `json:"template,omitempty" xml:"template,omitempty"`
+	Ticket               *Ticket   `json:"ticket,omitempty" xml:"ticket,omitempty"`
+	UnDeployHostedEngine string    `json:"undeploy_hosted_engine,omitempty" xml:"undeploy_hosted_engine,omitempty"`
+	UseCloudInit         string    `json:"use_cloud_init,omitempty" xml:"use_cloud_init,omitempty"`
+	UseSysPrep           string    `json:"use_sysprep,omitempty" xml:"use_sysprep,omitempty"`
 	// TODO: VirtualFunctionsConfiguration  HostNicVirtualFunctionsConfiguration `json:"virtual_functions_configuration,omitempty"`
-	VM *VM `json:"_v_m,omitempty"`
+	VM *VM `json:"vm,omitempty" xml:"vm,omitempty"`
 	// TODO: VnicProfileMappings            []VnicProfileMapping                 `json:"vnic_profile_mappings,omitempty"`
 }
 
 func (ovirtObject *OvirtObject) DoAction(action string, parameters interface{}) (err error) {
 	for _, link := range ovirtObject.Actions.Links {
 		if link.Rel == action {
+			con, span := ovirtObject.Con.startSpan("ovirt.action", ovirtObject.Con.objectAttributes(ovirtObject.Href, action)...)
+			defer func() { span.End(err) }()
 			var body []byte
-			body, err = json.Marshal(parameters)
+			body, err = ovirtObject.Con.encode(parameters)
 			if err != nil {
 				return err
 			}
-			_, err = ovirtObject.Con.Request("POST", ovirtObject.Con.ResolveLink(link.Href), body)
+			_, err = con.Request("POST", con.ResolveLink(link.Href), body)
 			return
 		}
 	}
 	return errors.New("Action not found")
 }
 
+// doActionResponse Posts the action to the object and decodes the action the engine answers with
+func (ovirtObject *OvirtObject) doActionResponse(action string, parameters Action) (*Action, error) {
+	if ovirtObject.Actions == nil {
+		return nil, errors.New("Action not found")
+	}
+	for _, link := range ovirtObject.Actions.Links {
+		if link.Rel == action {
+			body, err := ovirtObject.Con.encode(parameters)
+			if err != nil {
+				return nil, err
+			}
+			body, err = ovirtObject.Con.Request("POST", ovirtObject.Con.ResolveLink(link.Href), body)
+			if err != nil {
+				return nil, err
+			}
+			response := &Action{}
+			err = ovirtObject.Con.decode(body, response)
+			return response, err
+		}
+	}
+	return nil, errors.New("Action not found")
+}
+
 type linkResponse struct {
-	DiskAttachment []DiskAttachment `json:"disk_attachment,omitempty"`
-	NIC            []NIC            `json:"nic,omitempty"`
+	AffinityGroup     []AffinityGroup     `json:"affinity_group,omitempty" xml:"affinity_group,omitempty"`
+	AffinityLabel     []AffinityLabel     `json:"affinity_label,omitempty" xml:"affinity_label,omitempty"`
+	CPUProfile        []CPUProfile        `json:"cpu_profile,omitempty" xml:"cpu_profile,omitempty"`
+	DiskAttachment    []DiskAttachment    `json:"disk_attachment,omitempty" xml:"disk_attachment,omitempty"`
+	GraphicsConsole   []GraphicsConsole   `json:"graphics_console,omitempty" xml:"graphics_console,omitempty"`
+	Host              []Host              `json:"host,omitempty" xml:"host,omitempty"`
+	NIC               []NIC               `json:"nic,omitempty" xml:"nic,omitempty"`
+	QoS               []QoS               `json:"qos,omitempty" xml:"qos,omitempty"`
+	Quota             []Quota             `json:"quota,omitempty" xml:"quota,omitempty"`
+	QuotaClusterLimit []QuotaClusterLimit `json:"quota_cluster_limit,omitempty" xml:"quota_cluster_limit,omitempty"`
+	QuotaStorageLimit []QuotaStorageLimit `json:"quota_storage_limit,omitempty" xml:"quota_storage_limit,omitempty"`
+	SSHPublicKey      []SSHPublicKey      `json:"ssh_public_key,omitempty" xml:"ssh_public_key,omitempty"`
+	Tag               []Tag               `json:"tag,omitempty" xml:"tag,omitempty"`
+	VM                []VM                `json:"vm,omitempty" xml:"vm,omitempty"`
 }
 
 func (ovirtObject *OvirtObject) GetLink(rel string) (*url.URL, error) {
@@ -149,8 +192,16 @@ func (ovirtObject *OvirtObject) getLinkResponse(rel string, addParameters map[st
 				return nil, err
 			}
 			linkResp := &linkResponse{}
-			err = json.Unmarshal(body, linkResp)
-			return linkResp, err
+			err = ovirtObject.Con.decode(body, linkResp)
+			if err != nil {
+				return nil, err
+			}
+			// The objects listed are saved with the fields modified since they were loaded
+			val := reflect.ValueOf(linkResp).Elem()
+			for i := 0; i < val.NumField(); i++ {
+				ovirtObject.Con.track(val.Field(i).Addr().Interface(), nil)
+			}
+			return linkResp, nil
 		}
 	}
 	return nil, errors.New("Link not found")
@@ -174,8 +225,12 @@ func (ovirtObject *OvirtObject) GetLinkObject(rel string, id string, addParamete
 func (ovirtObject *OvirtObject) AddLinkObject(rel string, newObject interface{}, addParameters map[string]string) (string, error) {
 	for _, link := range ovirtObject.Links {
 		if rel == link.Rel {
-			var body []byte
-			body, err := json.MarshalIndent(newObject, "", "    ")
+			// Links to existing objects are sent as the element the collection lists
+			element := elementName(reflect.TypeOf(newObject))
+			if element == "" {
+				element = strings.TrimSuffix(rel, "s")
+			}
+			body, err := ovirtObject.Con.encodeElement(element, newObject)
 			if err != nil {
 				return "", err
 			}
@@ -186,8 +241,11 @@ func (ovirtObject *OvirtObject) AddLinkObject(rel string, newObject interface{},
 			href := ovirtObject.Con.ResolveLink(link.Href)
 			href.RawQuery = values.Encode()
 			resp, err := ovirtObject.Con.Request("POST", href, body)
+			if err != nil {
+				return "", err
+			}
 			respLink := Link{}
-			err = json.Unmarshal(resp, &respLink)
+			err = ovirtObject.Con.decode(resp, &respLink)
 			if err != nil {
 				return "", err
 			}
@@ -0,0 +1,175 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The types of QoS
+const (
+	QoSTypeCPU         = "cpu"
+	QoSTypeHostNetwork = "hostnetwork"
+	QoSTypeNetwork     = "network"
+	QoSTypeStorage     = "storage"
+)
+
+// QoS This type represents the attributes to define Quality of service (QoS).
+type QoS struct {
+	OvirtObject
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// The maximum processing capability in %, used by cpu QoS.
+	CPULimit int `json:"cpu_limit,omitempty,string" xml:"cpu_limit,omitempty"`
+	// The committed rate in Mbps for inbound traffic, used by network QoS.
+	InboundAverage int `json:"inbound_average,omitempty,string" xml:"inbound_average,omitempty"`
+	// The amount of data in KB that can be sent in a single burst, used by network QoS.
+	InboundBurst int `json:"inbound_burst,omitempty,string" xml:"inbound_burst,omitempty"`
+	// The maximum inbound rate in Mbps, used by network QoS.
+	InboundPeak int `json:"inbound_peak,omitempty,string" xml:"inbound_peak,omitempty"`
+	// The maximum permitted total number of input and output operations per second, used by storage QoS.
+	MaxIOPS int `json:"max_iops,omitempty,string" xml:"max_iops,omitempty"`
+	// The maximum permitted number of input operations per second, used by storage QoS.
+	MaxReadIOPS int `json:"max_read_iops,omitempty,string" xml:"max_read_iops,omitempty"`
+	// The maximum permitted throughput for read operations in MB/s, used by storage QoS.
+	MaxReadThroughput int `json:"max_read_throughput,omitempty,string" xml:"max_read_throughput,omitempty"`
+	// The maximum permitted total throughput in MB/s, used by storage QoS.
+	MaxThroughput int `json:"max_throughput,omitempty,string" xml:"max_throughput,omitempty"`
+	// The maximum permitted number of output operations per second, used by storage QoS.
+	MaxWriteIOPS int `json:"max_write_iops,omitempty,string" xml:"max_write_iops,omitempty"`
+	// The maximum permitted throughput for write operations in MB/s, used by storage QoS.
+	MaxWriteThroughput int `json:"max_write_throughput,omitempty,string" xml:"max_write_throughput,omitempty"`
+	// The committed rate in Mbps for outbound traffic, used by network QoS.
+	OutboundAverage int `json:"outbound_average,omitempty,string" xml:"outbound_average,omitempty"`
+	// The weighted share of the link used by host network QoS.
+	OutboundAverageLinkshare int `json:"outbound_average_linkshare,omitempty,string" xml:"outbound_average_linkshare,omitempty"`
+	// The committed rate in Mbps used by host network QoS.
+	OutboundAverageRealtime int `json:"outbound_average_realtime,omitempty,string" xml:"outbound_average_realtime,omitempty"`
+	// The maximum bandwidth in Mbps used by host network QoS.
+	OutboundAverageUpperlimit int `json:"outbound_average_upperlimit,omitempty,string" xml:"outbound_average_upperlimit,omitempty"`
+	// The amount of data in KB that can be sent in a single burst, used by network QoS.
+	OutboundBurst int `json:"outbound_burst,omitempty,string" xml:"outbound_burst,omitempty"`
+	// The maximum outbound rate in Mbps, used by network QoS.
+	OutboundPeak int `json:"outbound_peak,omitempty,string" xml:"outbound_peak,omitempty"`
+	// The kind of resources this entry can be assigned to, one of cpu, storage, network or hostnetwork.
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
+	// The data center the QoS belongs to.
+	DataCenter *Link `json:"data_center,omitempty" xml:"data_center,omitempty"`
+}
+
+// GetQoSs Retrieve the QoS entries of the data center
+func (dataCenter *DataCenter) GetQoSs() ([]*QoS, error) {
+	linkResp, err := dataCenter.getLinkResponse("qoss", nil)
+	if err != nil {
+		return nil, err
+	}
+	qoss := []*QoS{}
+	for i := range linkResp.QoS {
+		qos := &linkResp.QoS[i]
+		qos.Con = dataCenter.Con
+		qoss = append(qoss, qos)
+	}
+	return qoss, nil
+}
+
+// GetQoS Retrieve a QoS entry of the data center from the server
+func (dataCenter *DataCenter) GetQoS(id string) (*QoS, error) {
+	link, err := dataCenter.GetLink("qoss")
+	if err != nil {
+		return nil, err
+	}
+	link.Path += "/" + id
+	body, err := dataCenter.Con.Request("GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	qos := dataCenter.NewQoS()
+	err = dataCenter.Con.decode(body, qos)
+	if err != nil {
+		return nil, err
+	}
+	return qos, err
+}
+
+// GetQoSByName Retrieve the QoS entry of the data center with the given name
+func (dataCenter *DataCenter) GetQoSByName(name string) (*QoS, error) {
+	qoss, err := dataCenter.GetQoSs()
+	if err != nil {
+		return nil, err
+	}
+	for _, qos := range qoss {
+		if qos.Name == name {
+			return qos, nil
+		}
+	}
+	return nil, fmt.Errorf("QoS %s not found", name)
+}
+
+// NewQoS Create a new QoS structure belonging to the data center
+func (dataCenter *DataCenter) NewQoS() *QoS {
+	return &QoS{
+		OvirtObject: OvirtObject{Con: dataCenter.Con},
+		DataCenter:  &Link{Href: dataCenter.Href, ID: dataCenter.ID},
+	}
+}
+
+// Update Synchronize the local QoS with a copy from the server
+func (qos *QoS) Update() error {
+	if qos.Href == "" {
+		return fmt.Errorf("QoS has not been saved to the server")
+	}
+	body, err := qos.Con.Request("GET", qos.Con.ResolveLink(qos.Href), nil)
+	if err != nil {
+		return err
+	}
+	tempQoS := QoS{OvirtObject: OvirtObject{Con: qos.Con}}
+	err = qos.Con.decode(body, &tempQoS)
+	if err != nil {
+		return err
+	}
+	*qos = tempQoS
+	return nil
+}
+
+// Save Updates the server with the local copy of the QoS, sending only the fields
+// modified since it was loaded
+func (qos *QoS) Save(options ...SaveOption) error {
+	body, err := qos.Con.encodeChanges(qos)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already saved QoS, we need to update it
+	if qos.Href != "" {
+		body, err = qos.Con.put(qos, qos.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		if qos.DataCenter == nil || qos.DataCenter.Href == "" {
+			return errors.New("QoS is not associated with a data center")
+		}
+		link := qos.Con.ResolveLink(qos.DataCenter.Href + "/qoss")
+		body, err = qos.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempQoS := QoS{OvirtObject: OvirtObject{Con: qos.Con}}
+	err = qos.Con.decode(body, &tempQoS)
+	if err != nil {
+		return err
+	}
+	*qos = tempQoS
+	return nil
+}
+
+// Patch Updates the server with the fields of the QoS named by their JSON
+// path, such as "name" or "max_iops", whether they were modified or not
+func (qos *QoS) Patch(fields ...string) error {
+	return qos.Con.patch(qos, fields)
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+func TestQoS(t *testing.T) {
+	t.Parallel()
+	con := newTestConnection(t)
+	newDataCenter := con.NewDataCenter()
+	newDataCenter.Name = "test-qos-data-center"
+	newDataCenter.Local = "true"
+	err := newDataCenter.Save()
+	if err != nil {
+		t.Fatal("Error creating data center for the QoS", err)
+	}
+	defer newDataCenter.Delete()
+	newQoS := newDataCenter.NewQoS()
+	newQoS.Name = "test-storage-qos"
+	newQoS.Type = ovirtapi.QoSTypeStorage
+	newQoS.MaxIOPS = 500
+	err = newQoS.Save()
+	if err != nil {
+		t.Fatal("Error creating new QoS", err)
+	}
+	retrievedQoS, err := newDataCenter.GetQoSByName(newQoS.Name)
+	if err != nil {
+		t.Fatal("Error retrieving QoS", err)
+	}
+	if retrievedQoS.MaxIOPS != 500 {
+		t.Error("QoS did not keep its iops limit", retrievedQoS.MaxIOPS)
+	}
+	retrievedQoS.MaxIOPS = 1000
+	err = retrievedQoS.Save()
+	if err != nil {
+		t.Fatal("Error updating QoS", err)
+	}
+	err = retrievedQoS.Delete()
+	if err != nil {
+		t.Fatal("Error Deleting QoS", err)
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// QuotaUnlimited The limit value the engine uses for a quota resource without a limit.
+const QuotaUnlimited = -1
+
+// QuotaClusterLimit Represents the memory and virtual CPU limits of a quota on a cluster, or on all the clusters of the data center when Cluster is not set.
+type QuotaClusterLimit struct {
+	Link
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// A human-readable description in plain text.
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
+	// The memory limit in GiB, -1 for unlimited.
+	MemoryLimit float64 `json:"memory_limit,omitempty,string" xml:"memory_limit,omitempty"`
+	// The memory in GiB consumed by the virtual machines of the quota.
+	MemoryUsage float64 `json:"memory_usage,omitempty,string" xml:"memory_usage,omitempty"`
+	// A human-readable name in plain text.
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+	// The number of virtual CPUs limit, -1 for unlimited.
+	VCPULimit int `json:"vcpu_limit,omitempty,string" xml:"vcpu_limit,omitempty"`
+	// The number of virtual CPUs consumed by the virtual machines of the quota.
+	VCPUUsage int `json:"vcpu_usage,omitempty,string" xml:"vcpu_usage,omitempty"`
+	// The cluster the limit applies to.
+	Cluster *Link `json:"cluster,omitempty" xml:"cluster,omitempty"`
+	// The quota the limit belongs to.
+	Quota *Link `json:"quota,omitempty" xml:"quota,omitempty"`
+}
+
+// QuotaStorageLimit Represents the storage limit of a quota on a storage domain, or on all the storage domains of the data center when StorageDomain is not set.
+type QuotaStorageLimit struct {
+	Link
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// A human-readable description in plain text.
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
+	// The storage limit in GiB, -1 for unlimited.
+	Limit int `json:"limit,omitempty,string" xml:"limit,omitempty"`
+	// A human-readable name in plain text.
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+	// The storage in GiB consumed by the disks of the quota.
+	Usage float64 `json:"usage,omitempty,string" xml:"usage,omitempty"`
+	// The quota the limit belongs to.
+	Quota *Link `json:"quota,omitempty" xml:"quota,omitempty"`
+	// The storage domain the limit applies to.
+	StorageDomain *Link `json:"storage_domain,omitempty" xml:"storage_domain,omitempty"`
+}
+
+// Quota Represents a quota object, a set of memory, virtual CPU and storage limits in a data center.
+type Quota struct {
+	OvirtObject
+	// The percentage over the cluster limits the consumption may reach before the quota blocks new resources, a grace of 20 blocks them at 120%.
+	ClusterHardLimitPct int `json:"cluster_hard_limit_pct,omitempty,string" xml:"cluster_hard_limit_pct,omitempty"`
+	// The percentage of the cluster limits after which the quota warns about its consumption.
+	ClusterSoftLimitPct int `json:"cluster_soft_limit_pct,omitempty,string" xml:"cluster_soft_limit_pct,omitempty"`
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// The percentage over the storage limits the consumption may reach before the quota blocks new resources, a grace of 20 blocks them at 120%.
+	StorageHardLimitPct int `json:"storage_hard_limit_pct,omitempty,string" xml:"storage_hard_limit_pct,omitempty"`
+	// The percentage of the storage limits after which the quota warns about its consumption.
+	StorageSoftLimitPct int `json:"storage_soft_limit_pct,omitempty,string" xml:"storage_soft_limit_pct,omitempty"`
+	// The data center the quota belongs to.
+	DataCenter *Link `json:"data_center,omitempty" xml:"data_center,omitempty"`
+}
+
+// GetQuotas Retrieve the quotas of the data center
+func (dataCenter *DataCenter) GetQuotas() ([]*Quota, error) {
+	linkResp, err := dataCenter.getLinkResponse("quotas", nil)
+	if err != nil {
+		return nil, err
+	}
+	quotas := []*Quota{}
+	for i := range linkResp.Quota {
+		quota := &linkResp.Quota[i]
+		quota.Con = dataCenter.Con
+		quotas = append(quotas, quota)
+	}
+	return quotas, nil
+}
+
+// GetQuota Retrieve a quota of the data center from the server
+func (dataCenter *DataCenter) GetQuota(id string) (*Quota, error) {
+	link, err := dataCenter.GetLink("quotas")
+	if err != nil {
+		return nil, err
+	}
+	link.Path += "/" + id
+	body, err := dataCenter.Con.Request("GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	quota := dataCenter.NewQuota()
+	err = dataCenter.Con.decode(body, quota)
+	if err != nil {
+		return nil, err
+	}
+	return quota, err
+}
+
+// NewQuota Create a new quota structure belonging to the data center
+func (dataCenter *DataCenter) NewQuota() *Quota {
+	return &Quota{
+		OvirtObject: OvirtObject{Con: dataCenter.Con},
+		DataCenter:  &Link{Href: dataCenter.Href, ID: dataCenter.ID},
+	}
+}
+
+// Update Synchronize the local quota with a copy from the server
+func (quota *Quota) Update() error {
+	if quota.Href == "" {
+		return fmt.Errorf("quota has not been saved to the server")
+	}
+	body, err := quota.Con.Request("GET", quota.Con.ResolveLink(quota.Href), nil)
+	if err != nil {
+		return err
+	}
+	tempQuota := Quota{OvirtObject: OvirtObject{Con: quota.Con}}
+	err = quota.Con.decode(body, &tempQuota)
+	if err != nil {
+		return err
+	}
+	*quota = tempQuota
+	return nil
+}
+
+// Save Updates the server with the local copy of the quota, sending only the fields
+// modified since it was loaded
+func (quota *Quota) Save(options ...SaveOption) error {
+	body, err := quota.Con.encodeChanges(quota)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already saved quota, we need to update it
+	if quota.Href != "" {
+		body, err = quota.Con.put(quota, quota.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		if quota.DataCenter == nil || quota.DataCenter.Href == "" {
+			return errors.New("Quota is not associated with a data center")
+		}
+		link := quota.Con.ResolveLink(quota.DataCenter.Href + "/quotas")
+		body, err = quota.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempQuota := Quota{OvirtObject: OvirtObject{Con: quota.Con}}
+	err = quota.Con.decode(body, &tempQuota)
+	if err != nil {
+		return err
+	}
+	*quota = tempQuota
+	return nil
+}
+
+// Patch Updates the server with the fields of the quota named by their JSON
+// path, such as "description" or "cluster_hard_limit_pct", whether they were modified or not
+func (quota *Quota) Patch(fields ...string) error {
+	return quota.Con.patch(quota, fields)
+}
+
+// GetClusterLimits Retrieve the cluster limits of the quota
+func (quota *Quota) GetClusterLimits() ([]QuotaClusterLimit, error) {
+	linkResp, err := quota.getLinkResponse("quotaclusterlimits", nil)
+	if err != nil {
+		return nil, err
+	}
+	return linkResp.QuotaClusterLimit, nil
+}
+
+// AddClusterLimit Adds a cluster limit to the quota, returning the id of the new limit
+func (quota *Quota) AddClusterLimit(limit QuotaClusterLimit) (string, error) {
+	return quota.AddLinkObject("quotaclusterlimits", limit, nil)
+}
+
+// RemoveClusterLimit Removes the cluster limit with the given id from the quota
+func (quota *Quota) RemoveClusterLimit(id string) error {
+	return quota.RemoveLinkObject("quotaclusterlimits", id, nil)
+}
+
+// GetStorageLimits Retrieve the storage limits of the quota
+func (quota *Quota) GetStorageLimits() ([]QuotaStorageLimit, error) {
+	linkResp, err := quota.getLinkResponse("quotastoragelimits", nil)
+	if err != nil {
+		return nil, err
+	}
+	return linkResp.QuotaStorageLimit, nil
+}
+
+// AddStorageLimit Adds a storage limit to the quota, returning the id of the new limit
+func (quota *Quota) AddStorageLimit(limit QuotaStorageLimit) (string, error) {
+	return quota.AddLinkObject("quotastoragelimits", limit, nil)
+}
+
+// RemoveStorageLimit Removes the storage limit with the given id from the quota
+func (quota *Quota) RemoveStorageLimit(id string) error {
+	return quota.RemoveLinkObject("quotastoragelimits", id, nil)
+}
+
+// QuotaLimitReport The consumption of a single quota resource compared to its limit.
+type QuotaLimitReport struct {
+	// The limited resource, one of memory, vcpu or storage.
+	Resource string
+	// The cluster or storage domain the limit applies to, nil when it applies to the whole data center.
+	Target *Link
+	// The consumed amount, in GiB for memory and storage.
+	Usage float64
+	// The limit, in GiB for memory and storage.
+	Limit float64
+	// Whether the resource has no limit.
+	Unlimited bool
+	// The consumption as a percentage of the limit, 0 when unlimited.
+	Percent float64
+	// Whether the consumption reached the soft limit percentage of the quota.
+	SoftLimitExceeded bool
+	// Whether the consumption reached the limit plus the hard limit grace percentage of the quota.
+	HardLimitExceeded bool
+}
+
+// QuotaReport The consumption of every limit of a quota.
+type QuotaReport struct {
+	Quota  *Quota
+	Limits []QuotaLimitReport
+}
+
+func newQuotaLimitReport(resource string, target *Link, usage, limit float64, softPct, hardPct int) QuotaLimitReport {
+	report := QuotaLimitReport{
+		Resource:  resource,
+		Target:    target,
+		Usage:     usage,
+		Limit:     limit,
+		Unlimited: limit == QuotaUnlimited,
+	}
+	if report.Unlimited {
+		return report
+	}
+	if limit > 0 {
+		report.Percent = usage * 100 / limit
+	} else if usage > 0 {
+		// Anything consumed against a zero limit is over every threshold
+		report.SoftLimitExceeded = true
+		report.HardLimitExceeded = true
+		return report
+	}
+	report.SoftLimitExceeded = softPct > 0 && report.Percent >= float64(softPct)
+	report.HardLimitExceeded = report.Percent >= 100+float64(hardPct)
+	return report
+}
+
+// NewQuotaReport Compare the consumption of the limits with the soft limit percentages of the quota,
+// and with the limits increased by the hard limit grace percentages
+func NewQuotaReport(quota *Quota, clusterLimits []QuotaClusterLimit, storageLimits []QuotaStorageLimit) *QuotaReport {
+	report := &QuotaReport{Quota: quota}
+	for _, limit := range clusterLimits {
+		report.Limits = append(report.Limits,
+			newQuotaLimitReport("memory", limit.Cluster, limit.MemoryUsage, limit.MemoryLimit, quota.ClusterSoftLimitPct, quota.ClusterHardLimitPct),
+			newQuotaLimitReport("vcpu", limit.Cluster, float64(limit.VCPUUsage), float64(limit.VCPULimit), quota.ClusterSoftLimitPct, quota.ClusterHardLimitPct),
+		)
+	}
+	for _, limit := range storageLimits {
+		report.Limits = append(report.Limits,
+			newQuotaLimitReport("storage", limit.StorageDomain, limit.Usage, float64(limit.Limit), quota.StorageSoftLimitPct, quota.StorageHardLimitPct),
+		)
+	}
+	return report
+}
+
+// Report Retrieve the limits of the quota and report their consumption
+func (quota *Quota) Report() (*QuotaReport, error) {
+	clusterLimits, err := quota.GetClusterLimits()
+	if err != nil {
+		return nil, err
+	}
+	storageLimits, err := quota.GetStorageLimits()
+	if err != nil {
+		return nil, err
+	}
+	return NewQuotaReport(quota, clusterLimits, storageLimits), nil
+}
+
+// GetQuotaReports Report the consumption of every quota of the data center
+func (dataCenter *DataCenter) GetQuotaReports() ([]*QuotaReport, error) {
+	quotas, err := dataCenter.GetQuotas()
+	if err != nil {
+		return nil, err
+	}
+	reports := []*QuotaReport{}
+	for _, quota := range quotas {
+		report, err := quota.Report()
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+func TestQuota(t *testing.T) {
+	t.Parallel()
+	con := newTestConnection(t)
+	newDataCenter := con.NewDataCenter()
+	newDataCenter.Name = "test-quota-data-center"
+	newDataCenter.Local = "true"
+	newDataCenter.QuotaMode = "audit"
+	err := newDataCenter.Save()
+	if err != nil {
+		t.Fatal("Error creating data center for the quota", err)
+	}
+	defer newDataCenter.Delete()
+	newQuota := newDataCenter.NewQuota()
+	newQuota.Name = "test-quota"
+	newQuota.ClusterSoftLimitPct = 80
+	newQuota.ClusterHardLimitPct = 20
+	err = newQuota.Save()
+	if err != nil {
+		t.Fatal("Error creating new quota", err)
+	}
+	_, err = newQuota.AddClusterLimit(ovirtapi.QuotaClusterLimit{
+		MemoryLimit: 16,
+		VCPULimit:   ovirtapi.QuotaUnlimited,
+	})
+	if err != nil {
+		t.Fatal("Error adding cluster limit to the quota", err)
+	}
+	report, err := newQuota.Report()
+	if err != nil {
+		t.Fatal("Error reporting quota consumption", err)
+	}
+	if len(report.Limits) != 2 {
+		t.Fatal("Quota report does not contain the memory and vcpu limits", report.Limits)
+	}
+	if report.Limits[0].Limit != 16 || report.Limits[0].SoftLimitExceeded {
+		t.Error("Unexpected memory limit report", report.Limits[0])
+	}
+	if !report.Limits[1].Unlimited {
+		t.Error("Unexpected vcpu limit report", report.Limits[1])
+	}
+	retrievedQuota, err := newDataCenter.GetQuota(newQuota.ID)
+	if err != nil {
+		t.Fatal("Error retrieving quota", err)
+	}
+	retrievedQuota.Description = "about to delete"
+	err = retrievedQuota.Save()
+	if err != nil {
+		t.Fatal("Error updating quota", err)
+	}
+	err = retrievedQuota.Delete()
+	if err != nil {
+		t.Fatal("Error Deleting quota", err)
+	}
+}
+
+func TestNewQuotaReport(t *testing.T) {
+	t.Parallel()
+	quota := &ovirtapi.Quota{
+		ClusterSoftLimitPct: 80,
+		ClusterHardLimitPct: 20,
+		StorageSoftLimitPct: 80,
+		StorageHardLimitPct: 20,
+	}
+	cases := []struct {
+		name       string
+		usage      float64
+		limit      float64
+		unlimited  bool
+		percent    float64
+		soft, hard bool
+	}{
+		{"under the soft limit", 10, 100, false, 10, false, false},
+		{"usage over the grace percentage", 30, 100, false, 30, false, false},
+		{"soft limit", 80, 100, false, 80, true, false},
+		{"at the limit", 100, 100, false, 100, true, false},
+		{"within the grace", 119, 100, false, 119, true, false},
+		{"grace boundary", 120, 100, false, 120, true, true},
+		{"over the grace", 150, 100, false, 150, true, true},
+		{"unlimited", 1000, ovirtapi.QuotaUnlimited, true, 0, false, false},
+		{"zero limit unused", 0, 0, false, 0, false, false},
+		{"zero limit used", 1, 0, false, 0, true, true},
+	}
+	for _, c := range cases {
+		report := ovirtapi.NewQuotaReport(quota,
+			[]ovirtapi.QuotaClusterLimit{{MemoryUsage: c.usage, MemoryLimit: c.limit}},
+			[]ovirtapi.QuotaStorageLimit{{Usage: c.usage, Limit: int(c.limit)}})
+		if len(report.Limits) != 3 {
+			t.Fatal("Quota report does not contain the memory, vcpu and storage limits", report.Limits)
+		}
+		for _, limit := range []ovirtapi.QuotaLimitReport{report.Limits[0], report.Limits[2]} {
+			if limit.Unlimited != c.unlimited || limit.Percent != c.percent || limit.SoftLimitExceeded != c.soft || limit.HardLimitExceeded != c.hard {
+				t.Error("Unexpected", limit.Resource, "report", c.name, limit)
+			}
+		}
+	}
+	report := ovirtapi.NewQuotaReport(&ovirtapi.Quota{ClusterSoftLimitPct: 80},
+		[]ovirtapi.QuotaClusterLimit{{MemoryUsage: 100, MemoryLimit: 100}}, nil)
+	if limit := report.Limits[0]; !limit.HardLimitExceeded {
+		t.Error("Quota without grace did not block at its limit", limit)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// VMConsolePort The port the vmconsole proxy of the engine listens on by default
+const VMConsolePort = 2222
+
+// VMConsoleUser The user the vmconsole proxy of the engine is logged into with
+const VMConsoleUser = "ovirt-vmconsole"
+
+// SSHPublicKey A public key of a user, used to log into the vmconsole proxy of the engine.
+type SSHPublicKey struct {
+	OvirtObject
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// The public key in the format of an authorized_keys file, such as "ssh-ed25519 AAAA... user@host".
+	Content string `json:"content,omitempty" xml:"content,omitempty"`
+	// A reference to the user owning the key.
+	User *Link `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+// GetUserSSHPublicKeys Retrieve the SSH public keys of the user with the given id
+func (con *Connection) GetUserSSHPublicKeys(userID string) ([]*SSHPublicKey, error) {
+	user, err := con.userObject(userID)
+	if err != nil {
+		return nil, err
+	}
+	linkResp, err := user.getLinkResponse("sshpublickeys", nil)
+	if err != nil {
+		return nil, err
+	}
+	keys := []*SSHPublicKey{}
+	for i := range linkResp.SSHPublicKey {
+		key := &linkResp.SSHPublicKey[i]
+		key.Con = con
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// NewUserSSHPublicKey Create a new SSH public key structure belonging to the user with the given id
+func (con *Connection) NewUserSSHPublicKey(userID string) (*SSHPublicKey, error) {
+	user, err := con.userObject(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &SSHPublicKey{
+		OvirtObject: OvirtObject{Con: con},
+		User:        &Link{Href: user.Href, ID: userID},
+	}, nil
+}
+
+// Save Updates the server with the local copy of the SSH public key, sending only the fields
+// modified since it was loaded
+func (key *SSHPublicKey) Save(options ...SaveOption) error {
+	body, err := key.Con.encodeChanges(key)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already saved key, we need to update it
+	if key.Href != "" {
+		body, err = key.Con.put(key, key.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		if key.User == nil || key.User.Href == "" {
+			return errors.New("SSH public key is not associated with a user")
+		}
+		link := key.Con.ResolveLink(key.User.Href + "/sshpublickeys")
+		body, err = key.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempKey := SSHPublicKey{OvirtObject: OvirtObject{Con: key.Con}}
+	err = key.Con.decode(body, &tempKey)
+	if err != nil {
+		return err
+	}
+	*key = tempKey
+	return nil
+}
+
+// SetSerialConsole Enables or disables the serial console device of the VM,
+// sending only the console with the options of Save. The engine applies the
+// change to a running VM on its next boot, NextRun stores it in the next run
+// configuration explicitly.
+func (vm *VM) SetSerialConsole(enabled bool, options ...SaveOption) error {
+	vm.Console = &Console{Enabled: strconv.FormatBool(enabled)}
+	return vm.Con.patch(vm, []string{"console"}, options...)
+}
+
+// SerialConsoleProxy The connection details of the serial console of a VM
+// through the vmconsole proxy of the engine. The proxy accepts the SSH public
+// keys of the users with the permission to connect to the console of the VM.
+type SerialConsoleProxy struct {
+	// The address and port of the proxy.
+	Host string
+	Port int
+	// The user the proxy is logged into with.
+	User string
+	// The id of the VM the console is connected to.
+	VMID string
+}
+
+// SerialConsoleOption Changes the settings of the vmconsole proxy assumed by SerialConsoleProxy
+type SerialConsoleOption func(proxy *SerialConsoleProxy)
+
+// WithVMConsoleProxy The vmconsole proxy listens on host and port instead of
+// the port VMConsolePort of the host of the engine
+func WithVMConsoleProxy(host string, port int) SerialConsoleOption {
+	return func(proxy *SerialConsoleProxy) {
+		proxy.Host = host
+		proxy.Port = port
+	}
+}
+
+// WithVMConsoleUser The vmconsole proxy is logged into with user instead of VMConsoleUser
+func WithVMConsoleUser(user string) SerialConsoleOption {
+	return func(proxy *SerialConsoleProxy) {
+		proxy.User = user
+	}
+}
+
+// SerialConsoleProxy The connection details of the serial console of the VM.
+// The engine does not publish the settings of its vmconsole proxy, by default
+// the proxy runs on the host of the engine with the default settings of
+// ovirt-vmconsole-proxy, the options override them.
+func (vm *VM) SerialConsoleProxy(options ...SerialConsoleOption) (*SerialConsoleProxy, error) {
+	if vm.Href == "" {
+		return nil, fmt.Errorf("VM has not been saved to the server")
+	}
+	if vm.Console == nil || vm.Console.Enabled != "true" {
+		return nil, fmt.Errorf("VM %s does not have a serial console", vm.Name)
+	}
+	proxy := &SerialConsoleProxy{
+		Host: vm.Con.endPoint.Hostname(),
+		Port: VMConsolePort,
+		User: VMConsoleUser,
+		VMID: vm.ID,
+	}
+	for _, option := range options {
+		option(proxy)
+	}
+	return proxy, nil
+}
+
+// Command The ssh command connecting to the serial console
+func (proxy *SerialConsoleProxy) Command() []string {
+	return []string{"ssh", "-t", "-p", strconv.Itoa(proxy.Port), proxy.User + "@" + proxy.Host,
+		"connect", "--vm-id=" + proxy.VMID}
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+func TestSerialConsole(t *testing.T) {
+	t.Parallel()
+	engine, _, recorder, vm := newChangesVM(t)
+	if _, err := vm.SerialConsoleProxy(); err == nil {
+		t.Error("Got the serial console proxy of a VM without serial console")
+	}
+	if err := vm.SetSerialConsole(true); err != nil {
+		t.Fatal("Error enabling the serial console", err)
+	}
+	if sent := recorder.last(t); !reflect.DeepEqual(sent, []string{"console"}) {
+		t.Error("Did not send only the console", sent)
+	}
+	if console, ok := engine.Get("vms", vm.ID)["console"].(map[string]interface{}); !ok || console["enabled"] != "true" {
+		t.Error("Did not enable the serial console", engine.Get("vms", vm.ID)["console"])
+	}
+	proxy, err := vm.SerialConsoleProxy()
+	if err != nil {
+		t.Fatal("Error getting the serial console proxy", err)
+	}
+	expected := []string{"ssh", "-t", "-p", "2222", "ovirt-vmconsole@127.0.0.1", "connect", "--vm-id=" + vm.ID}
+	if command := proxy.Command(); !reflect.DeepEqual(command, expected) {
+		t.Error("Unexpected serial console command", command)
+	}
+	proxy, err = vm.SerialConsoleProxy(ovirtapi.WithVMConsoleProxy("vmconsole.example.com", 2223), ovirtapi.WithVMConsoleUser("console"))
+	expected = []string{"ssh", "-t", "-p", "2223", "console@vmconsole.example.com", "connect", "--vm-id=" + vm.ID}
+	if err != nil || !reflect.DeepEqual(proxy.Command(), expected) {
+		t.Error("Did not override the proxy settings", proxy, err)
+	}
+	if err = vm.SetSerialConsole(false); err != nil || vm.Console == nil || vm.Console.Enabled != "false" {
+		t.Error("Did not disable the serial console", vm.Console, err)
+	}
+
+	// A running VM gets the console on its next boot
+	engine.SetStatus("vms", vm.ID, "up")
+	if err = vm.SetSerialConsole(true, ovirtapi.NextRun()); err != nil {
+		t.Fatal("Error enabling the serial console on the next run", err)
+	}
+	if console, ok := engine.Get("vms", vm.ID)["console"].(map[string]interface{}); !ok || console["enabled"] != "false" {
+		t.Error("Enabled the serial console of the running VM", engine.Get("vms", vm.ID)["console"])
+	}
+	pending, err := vm.GetNextRun()
+	if err != nil || pending.Console == nil || pending.Console.Enabled != "true" {
+		t.Error("Did not enable the serial console on the next run", err)
+	}
+}
+
+func TestSSHPublicKeys(t *testing.T) {
+	t.Parallel()
+	engine, con, _, _ := newChangesVM(t)
+	userID := engine.AddUser("operator@internal", "secret", false)
+	key, err := con.NewUserSSHPublicKey(userID)
+	if err != nil {
+		t.Fatal("Error creating the key", err)
+	}
+	key.Content = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGZha2U operator@example.com"
+	if err = key.Save(); err != nil {
+		t.Fatal("Error saving the key", err)
+	}
+	if key.ID == "" || key.User == nil || key.User.ID != userID {
+		t.Error("Unexpected saved key", key)
+	}
+	key.Comment = "laptop"
+	if err = key.Save(); err != nil {
+		t.Fatal("Error updating the key", err)
+	}
+	keys, err := con.GetUserSSHPublicKeys(userID)
+	if err != nil {
+		t.Fatal("Error getting the keys", err)
+	}
+	if len(keys) != 1 || keys[0].Content != key.Content || keys[0].Comment != "laptop" {
+		t.Error("Unexpected keys", keys)
+	}
+	if err = keys[0].Delete(); err != nil {
+		t.Fatal("Error deleting the key", err)
+	}
+	if keys, err = con.GetUserSSHPublicKeys(userID); err != nil || len(keys) != 0 {
+		t.Error("Did not delete the key", keys, err)
+	}
+	if err = (&ovirtapi.SSHPublicKey{OvirtObject: ovirtapi.OvirtObject{Con: con}}).Save(); err == nil {
+		t.Error("Saved a key without user")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+// testCredentials The engine named by OVIRT_URL, OVIRT_USERNAME and
+// OVIRT_PASSWORD, or a fake engine stopped with the test when OVIRT_URL is not set.
+func testCredentials(t *testing.T) (url string, username string, password string) {
+	url = os.Getenv("OVIRT_URL")
+	if url == "" {
+		engine := ovirtapitest.NewEngine()
+		t.Cleanup(engine.Close)
+		return engine.URL(), engine.Username, engine.Password
+	}
+	username = os.Getenv("OVIRT_USERNAME")
+	if username == "" {
+		t.Fatal("OVIRT_USERNAME is not set")
+	}
+	password = os.Getenv("OVIRT_PASSWORD")
+	if password == "" {
+		t.Fatal("OVIRT_PASSWORD is not set")
+	}
+	return url, username, password
+}
+
+// cassettePath The cassette holding the conversation of the test with a real engine
+func cassettePath(t *testing.T) string {
+	return filepath.Join("testdata", "cassettes", strings.Replace(t.Name(), "/", "_", -1)+".json")
+}
+
+// testTransport Records the conversation with the engine to the cassette of the test
+// when OVIRT_RECORD is set, replays the cassette of the test when OVIRT_URL is not set
+// and a cassette exists, and talks directly to the engine otherwise.
+func testTransport(t *testing.T) []ovirtapi.ConnectionOption {
+	path := cassettePath(t)
+	if os.Getenv("OVIRT_URL") == "" {
+		if _, err := os.Stat(path); err != nil {
+			return nil
+		}
+		cassette, err := ovirtapitest.LoadCassette(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := cassette.Check(); err != nil {
+				t.Error(err)
+			}
+		})
+		return []ovirtapi.ConnectionOption{ovirtapi.WithTransport(cassette)}
+	}
+	if record, _ := strconv.ParseBool(os.Getenv("OVIRT_RECORD")); !record {
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cassette := ovirtapitest.NewRecorder(path, nil)
+	t.Cleanup(func() {
+		if err := cassette.Save(); err != nil {
+			t.Error("error saving cassette", err)
+		}
+	})
+	return []ovirtapi.ConnectionOption{ovirtapi.WithTransport(cassette)}
+}
+
+// newTestConnection Connect to the test engine, DEBUG_TRANSPORT enables the debug output
+func newTestConnection(t *testing.T) *ovirtapi.Connection {
+	options := testTransport(t)
+	url, username, password := testCredentials(t)
+	if options != nil && os.Getenv("OVIRT_URL") == "" {
+		// Replayed requests never reach the fake engine
+		url = "http://ovirt.invalid" + ovirtapitest.APIPath
+	}
+	debug, _ := strconv.ParseBool(os.Getenv("DEBUG_TRANSPORT"))
+	con, err := ovirtapi.NewConnection(url, username, password, debug, append(options, ovirtapi.WithFilter(false))...)
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	return con
+}
@@ -0,0 +1,284 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// UploadFile Writes the file to the image, sending only the data of a sparse
+// file and zero requests for its holes, then verifies the checksum of the image
+func (client *ImageioClient) UploadFile(file *os.File) error {
+	extents, err := FileExtents(file)
+	if err != nil {
+		return err
+	}
+	return client.UploadExtents(file, extents)
+}
+
+// UploadExtents Writes the data extents read from r to the image and zeroes
+// its zero extents, concurrently over several connections. The image is then
+// flushed and its checksum compared with the checksum of the extents, an
+// UnverifiedError is returned when the daemon cannot compute it. Other tools,
+// such as a qcow2 reader, may provide the extents of their images.
+func (client *ImageioClient) UploadExtents(r io.ReaderAt, extents []ImageExtent) (err error) {
+	con, span := client.con.startSpan("ImageioClient.UploadExtents", Attribute{"ovirt.extents", strconv.Itoa(len(extents))})
+	defer func() { span.End(err) }()
+	client = client.withConnection(con)
+	options, err := client.Options()
+	if err != nil {
+		return err
+	}
+	zeroes := make([]byte, ImageioChunkSize)
+	err = client.parallel(options.MaxWriters, splitExtents(extents, !options.Supports("zero")), func(worker *ImageioClient, extent ImageExtent) error {
+		if extent.Zero && options.Supports("zero") {
+			return worker.Zero(extent.Start, extent.Length)
+		}
+		data := zeroes[:extent.Length]
+		if !extent.Zero {
+			data = make([]byte, extent.Length)
+			if _, err := r.ReadAt(data, extent.Start); err != nil && err != io.EOF {
+				return err
+			}
+		}
+		_, err := worker.WriteAt(data, extent.Start)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if options.Supports("flush") {
+		if err = client.Flush(); err != nil {
+			return err
+		}
+	}
+	return client.verify(r, extents)
+}
+
+// DownloadFile Writes the image to the file, reading only the data extents of
+// the image concurrently over several connections and leaving holes in the
+// file for its zero extents, then verifies the checksum of the file like
+// UploadExtents
+func (client *ImageioClient) DownloadFile(file *os.File) (err error) {
+	con, span := client.con.startSpan("ImageioClient.DownloadFile")
+	defer func() { span.End(err) }()
+	client = client.withConnection(con)
+	options, err := client.Options()
+	if err != nil {
+		return err
+	}
+	if err = file.Truncate(0); err != nil {
+		return err
+	}
+	if !options.Supports("extents") {
+		written, err := client.Download(file)
+		if err != nil {
+			return err
+		}
+		return client.verify(file, []ImageExtent{{Start: 0, Length: written}})
+	}
+	extents, err := client.Extents()
+	if err != nil {
+		return err
+	}
+	if len(extents) > 0 {
+		last := extents[len(extents)-1]
+		if err = file.Truncate(last.Start + last.Length); err != nil {
+			return err
+		}
+	}
+	err = client.parallel(options.MaxReaders, splitExtents(extents, false), func(worker *ImageioClient, extent ImageExtent) error {
+		if extent.Zero {
+			return nil
+		}
+		data := make([]byte, extent.Length)
+		if _, err := worker.ReadAt(data, extent.Start); err != nil {
+			return err
+		}
+		_, err := file.WriteAt(data, extent.Start)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return client.verify(file, extents)
+}
+
+// UnverifiedError The image was transferred but the daemon could not compute
+// the checksum verifying it
+type UnverifiedError struct {
+	Err error
+}
+
+func (err *UnverifiedError) Error() string {
+	return fmt.Sprintf("Transferred image could not be verified: %s", err.Err)
+}
+
+func (err *UnverifiedError) Unwrap() error {
+	return err.Err
+}
+
+// IsUnverified Whether the error is an UnverifiedError
+func IsUnverified(err error) bool {
+	var unverified *UnverifiedError
+	return errors.As(err, &unverified)
+}
+
+// ChecksumExtents The checksum of the image made of the extents read from r,
+// computed like imageio with ImageioChecksumAlgorithm: the digests of the
+// blocks of blockSize bytes of the image, the last one possibly shorter, are
+// checksummed together. Zero extents are not read.
+func ChecksumExtents(r io.ReaderAt, extents []ImageExtent, blockSize int64) (string, error) {
+	if blockSize <= 0 {
+		return "", fmt.Errorf("Invalid checksum block size %d", blockSize)
+	}
+	checksum := &blockHash{checksum: sha256.New(), block: make([]byte, blockSize)}
+	buffer := make([]byte, ImageioChunkSize)
+	zeroes := make([]byte, ImageioChunkSize)
+	for _, extent := range splitExtents(extents, true) {
+		if extent.Zero {
+			checksum.Write(zeroes[:extent.Length])
+			continue
+		}
+		if _, err := r.ReadAt(buffer[:extent.Length], extent.Start); err != nil && err != io.EOF {
+			return "", err
+		}
+		checksum.Write(buffer[:extent.Length])
+	}
+	return checksum.Sum(), nil
+}
+
+// blockHash Checksums the sha256 digests of the blocks of the data written to it
+type blockHash struct {
+	checksum hash.Hash
+	block    []byte
+	length   int
+}
+
+func (h *blockHash) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		n := copy(h.block[h.length:], p)
+		h.length += n
+		p = p[n:]
+		if h.length == len(h.block) {
+			h.sumBlock()
+		}
+	}
+	return written, nil
+}
+
+// Sum The hex checksum of the digests of the blocks written so far
+func (h *blockHash) Sum() string {
+	if h.length > 0 {
+		h.sumBlock()
+	}
+	return hex.EncodeToString(h.checksum.Sum(nil))
+}
+
+func (h *blockHash) sumBlock() {
+	digest := sha256.Sum256(h.block[:h.length])
+	h.checksum.Write(digest[:])
+	h.length = 0
+}
+
+// verify Compares the checksum of the image computed by the daemon with the
+// checksum of the extents read from r, an UnverifiedError is returned when the
+// daemon does not compute an ImageioChecksumAlgorithm checksum
+func (client *ImageioClient) verify(r io.ReaderAt, extents []ImageExtent) error {
+	remote, err := client.Checksum(ImageioChecksumAlgorithm)
+	if err != nil {
+		return &UnverifiedError{err}
+	}
+	if remote.Algorithm != ImageioChecksumAlgorithm || remote.BlockSize <= 0 {
+		return &UnverifiedError{fmt.Errorf("Daemon computed a %s checksum of blocks of %d bytes", remote.Algorithm, remote.BlockSize)}
+	}
+	local, err := ChecksumExtents(r, extents, remote.BlockSize)
+	if err != nil {
+		return err
+	}
+	if local != remote.Checksum {
+		return fmt.Errorf("Checksum of the image %s differs from the checksum of the transferred data %s", remote.Checksum, local)
+	}
+	return nil
+}
+
+// parallel Transfers the extents over the workers of the client, at most limit
+// when the daemon has one. The first error cancels the extents not transferred yet.
+func (client *ImageioClient) parallel(limit int, extents []ImageExtent, transfer func(worker *ImageioClient, extent ImageExtent) error) error {
+	workers := client.Workers
+	if workers <= 0 {
+		workers = ImageioWorkers
+	}
+	if limit > 0 && workers > limit {
+		workers = limit
+	}
+	ctx, cancel := context.WithCancel(client.con.Context())
+	defer cancel()
+	worker := client.withConnection(client.con.WithContext(ctx))
+	queue := make(chan ImageExtent)
+	errs := make(chan error, workers)
+	var wait sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for extent := range queue {
+				if err := transfer(worker, extent); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+feed:
+	for _, extent := range extents {
+		select {
+		case queue <- extent:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wait.Wait()
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return client.con.Context().Err()
+	}
+}
+
+// splitExtents Splits the data extents, and the zero extents when zeroes is
+// true, into extents of at most ImageioChunkSize bytes
+func splitExtents(extents []ImageExtent, zeroes bool) []ImageExtent {
+	split := []ImageExtent{}
+	for _, extent := range extents {
+		if extent.Zero && !zeroes {
+			split = append(split, extent)
+			continue
+		}
+		for start := extent.Start; start < extent.Start+extent.Length; start += ImageioChunkSize {
+			length := extent.Start + extent.Length - start
+			if length > ImageioChunkSize {
+				length = ImageioChunkSize
+			}
+			split = append(split, ImageExtent{Start: start, Length: length, Zero: extent.Zero, Hole: extent.Hole})
+		}
+	}
+	return split
+}
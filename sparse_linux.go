@@ -0,0 +1,63 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+//go:build linux
+
+package ovirtapi
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// The whence of lseek finding the next data and the next hole of a file
+const (
+	seekData = 3
+	seekHole = 4
+)
+
+// FileExtents The data and hole extents of the file, found with SEEK_DATA and
+// SEEK_HOLE. A file system not reporting holes gives a single data extent.
+func FileExtents(file *os.File) ([]ImageExtent, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	position, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Seek(position, io.SeekStart)
+	extents := []ImageExtent{}
+	for offset := int64(0); offset < size; {
+		data, err := file.Seek(offset, seekData)
+		if errors.Is(err, syscall.ENXIO) {
+			data = size
+		} else if errors.Is(err, syscall.EINVAL) {
+			return []ImageExtent{{Start: 0, Length: size}}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if data > offset {
+			extents = append(extents, ImageExtent{Start: offset, Length: data - offset, Zero: true, Hole: true})
+		}
+		if data >= size {
+			break
+		}
+		hole, err := file.Seek(data, seekHole)
+		if errors.Is(err, syscall.ENXIO) {
+			hole = size
+		} else if err != nil {
+			return nil, err
+		}
+		extents = append(extents, ImageExtent{Start: data, Length: hole - data})
+		offset = hole
+	}
+	return extents, nil
+}
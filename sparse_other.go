@@ -0,0 +1,24 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+//go:build !linux
+
+package ovirtapi
+
+import "os"
+
+// FileExtents The extents of the file, a single data extent where the holes
+// of files cannot be found
+func FileExtents(file *os.File) ([]ImageExtent, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return []ImageExtent{}, nil
+	}
+	return []ImageExtent{{Start: 0, Length: info.Size()}}, nil
+}
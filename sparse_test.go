@@ -0,0 +1,233 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+const (
+	// sparseSize The size of the sparse images transferred, a few imageio requests
+	sparseSize = 4 * ovirtapi.ImageioChunkSize
+	// sparseData The size of the data of the sparse images, spanning two requests
+	sparseData = ovirtapi.ImageioChunkSize + ovirtapitest.ImageioBlockSize
+	// sparseTail The offset of the last block of data of the sparse images
+	sparseTail = 3 * ovirtapi.ImageioChunkSize
+)
+
+// sparseImage An image of sparseSize bytes with random data at the start of
+// its second request and a block of data at sparseTail
+func sparseImage() []byte {
+	image := make([]byte, sparseSize)
+	random := rand.New(rand.NewSource(1))
+	random.Read(image[ovirtapi.ImageioChunkSize : ovirtapi.ImageioChunkSize+sparseData])
+	copy(image[sparseTail:], "tail")
+	return image
+}
+
+// sparseFile Writes the data of the image to a sparse file
+func sparseFile(t *testing.T, image []byte) *os.File {
+	file, err := os.Create(filepath.Join(t.TempDir(), "sparse.img"))
+	if err != nil {
+		t.Fatal("Error creating the file", err)
+	}
+	t.Cleanup(func() { file.Close() })
+	if err = file.Truncate(int64(len(image))); err != nil {
+		t.Fatal("Error truncating the file", err)
+	}
+	for start := 0; start < len(image); start += ovirtapitest.ImageioBlockSize {
+		block := image[start : start+ovirtapitest.ImageioBlockSize]
+		if !bytes.Equal(block, make([]byte, len(block))) {
+			if _, err = file.WriteAt(block, int64(start)); err != nil {
+				t.Fatal("Error writing the file", err)
+			}
+		}
+	}
+	return file
+}
+
+// requireHoles Skips the test when the file system does not report the holes of files
+func requireHoles(t *testing.T, extents []ovirtapi.ImageExtent) {
+	if len(extents) == 1 && !extents[0].Zero {
+		t.Skip("The file system does not report holes")
+	}
+}
+
+func TestFileExtents(t *testing.T) {
+	t.Parallel()
+	file := sparseFile(t, sparseImage())
+	extents, err := ovirtapi.FileExtents(file)
+	if err != nil {
+		t.Fatal("Error getting the extents", err)
+	}
+	requireHoles(t, extents)
+	expected := []ovirtapi.ImageExtent{
+		{Start: 0, Length: ovirtapi.ImageioChunkSize, Zero: true, Hole: true},
+		{Start: ovirtapi.ImageioChunkSize, Length: sparseData},
+		{Start: ovirtapi.ImageioChunkSize + sparseData, Length: sparseTail - ovirtapi.ImageioChunkSize - sparseData, Zero: true, Hole: true},
+		{Start: sparseTail, Length: ovirtapitest.ImageioBlockSize},
+		{Start: sparseTail + ovirtapitest.ImageioBlockSize, Length: sparseSize - sparseTail - ovirtapitest.ImageioBlockSize, Zero: true, Hole: true},
+	}
+	if !reflect.DeepEqual(extents, expected) {
+		t.Error("Unexpected extents", extents)
+	}
+}
+
+func TestChecksumExtents(t *testing.T) {
+	t.Parallel()
+	image := []byte("\x00\x00\x00\x00data\x00\x00")
+	extents := []ovirtapi.ImageExtent{
+		{Start: 0, Length: 4, Zero: true},
+		{Start: 4, Length: 4},
+		{Start: 8, Length: 2, Zero: true},
+	}
+	// The digests of the blocks of 4 bytes, the last one of 2 bytes, checksummed together
+	checksum := sha256.New()
+	for _, block := range [][]byte{image[:4], image[4:8], image[8:]} {
+		digest := sha256.Sum256(block)
+		checksum.Write(digest[:])
+	}
+	local, err := ovirtapi.ChecksumExtents(bytes.NewReader(image), extents, 4)
+	if err != nil || local != hex.EncodeToString(checksum.Sum(nil)) {
+		t.Error("Unexpected checksum", local, err)
+	}
+	if _, err = ovirtapi.ChecksumExtents(bytes.NewReader(image), extents, 0); err == nil {
+		t.Error("Accepted blocks of 0 bytes")
+	}
+}
+
+func TestUploadSparseFile(t *testing.T) {
+	t.Parallel()
+	engine, disk := newTransferDisk(t, sparseSize)
+	// The holes of the file overwrite the previous data of the disk
+	engine.Imageio().SetImage(disk.ID, bytes.Repeat([]byte{0xff}, sparseSize))
+	image := sparseImage()
+	file := sparseFile(t, image)
+	extents, err := ovirtapi.FileExtents(file)
+	if err != nil {
+		t.Fatal("Error getting the extents", err)
+	}
+	requireHoles(t, extents)
+	_, client := startTransfer(t, disk, ovirtapi.ImageTransferUpload)
+	client.Workers = 2
+	// Progress may ask the client for the bytes transferred
+	var reported int64
+	client.Progress = func(transferred int64) {
+		if client.Transferred() < transferred {
+			t.Error("Transferred less than reported", client.Transferred(), transferred)
+		}
+		atomic.AddInt64(&reported, 1)
+	}
+	if err = client.UploadFile(file); err != nil {
+		t.Fatal("Error uploading the file", err)
+	}
+	if !bytes.Equal(engine.Imageio().Image(disk.ID), image) {
+		t.Error("Uploaded image differs")
+	}
+	stats := engine.Imageio().Stats()
+	if stats.BytesWritten != sparseData+ovirtapitest.ImageioBlockSize || stats.Writes != 3 {
+		t.Error("Did not send only the data of the file", stats)
+	}
+	if stats.BytesZeroed != sparseSize-stats.BytesWritten || stats.Zeroes != 3 || stats.Flushes != 1 || stats.Checksums != 1 {
+		t.Error("Unexpected imageio requests", stats)
+	}
+	if stats.MaxInFlight > 2 {
+		t.Error("Used more connections than the workers", stats.MaxInFlight)
+	}
+	if client.Transferred() != sparseSize || atomic.LoadInt64(&reported) != int64(stats.Writes+stats.Zeroes) {
+		t.Error("Unexpected progress", client.Transferred(), reported)
+	}
+}
+
+func TestDownloadSparseFile(t *testing.T) {
+	t.Parallel()
+	engine, disk := newTransferDisk(t, sparseSize)
+	image := sparseImage()
+	engine.Imageio().SetImage(disk.ID, image)
+	_, client := startTransfer(t, disk, ovirtapi.ImageTransferDownload)
+	file, err := os.Create(filepath.Join(t.TempDir(), "download.img"))
+	if err != nil {
+		t.Fatal("Error creating the file", err)
+	}
+	defer file.Close()
+	if err = client.DownloadFile(file); err != nil {
+		t.Fatal("Error downloading the image", err)
+	}
+	downloaded, err := ioutil.ReadFile(file.Name())
+	if err != nil || !bytes.Equal(downloaded, image) {
+		t.Error("Downloaded image differs", err)
+	}
+	if stats := engine.Imageio().Stats(); stats.BytesRead != sparseData+ovirtapitest.ImageioBlockSize || stats.Checksums != 1 {
+		t.Error("Did not read only the data of the image", stats)
+	}
+	extents, err := ovirtapi.FileExtents(file)
+	if err != nil {
+		t.Fatal("Error getting the extents", err)
+	}
+	requireHoles(t, extents)
+	if len(extents) != 5 {
+		t.Error("Did not leave holes in the file", extents)
+	}
+}
+
+func TestUploadChecksumMismatch(t *testing.T) {
+	t.Parallel()
+	engine, disk := newTransferDisk(t, sparseSize)
+	engine.Imageio().SetImage(disk.ID, bytes.Repeat([]byte{0xff}, sparseSize))
+	_, client := startTransfer(t, disk, ovirtapi.ImageTransferUpload)
+	// The extents do not cover the end of the disk
+	extents := []ovirtapi.ImageExtent{{Start: 0, Length: ovirtapi.ImageioChunkSize}}
+	err := client.UploadExtents(bytes.NewReader(make([]byte, ovirtapi.ImageioChunkSize)), extents)
+	if err == nil || !strings.Contains(err.Error(), "Checksum") {
+		t.Error("Did not detect the data not uploaded", err)
+	}
+}
+
+func TestUploadUnverified(t *testing.T) {
+	t.Parallel()
+	engine, disk := newTransferDisk(t, sparseSize)
+	_, client := startTransfer(t, disk, ovirtapi.ImageTransferUpload)
+	// A daemon without checksums
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/checksum") {
+			http.NotFound(w, r)
+			return
+		}
+		engine.Imageio().ServeHTTP(w, r)
+	}))
+	defer daemon.Close()
+	daemonURL, err := url.Parse(daemon.URL + client.URL.Path)
+	if err != nil {
+		t.Fatal("Error parsing the daemon URL", err)
+	}
+	client.URL = daemonURL
+	image := sparseImage()
+	err = client.UploadExtents(bytes.NewReader(image), []ovirtapi.ImageExtent{{Start: 0, Length: sparseSize}})
+	if !ovirtapi.IsUnverified(err) || !isFault(errors.Unwrap(err), 404) {
+		t.Error("Did not report the image as unverified", err)
+	}
+	if !bytes.Equal(engine.Imageio().Image(disk.ID), image) {
+		t.Error("Uploaded image differs")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import "errors"
+
+// RootTagID The ID of the root tag, every tag without an explicit parent is a child of it.
+const RootTagID = "00000000-0000-0000-0000-000000000000"
+
+// Tag Represents a tag in the system.
+type Tag struct {
+	OvirtObject
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// Reference to the group where the tag is assigned.
+	Group *Link `json:"group,omitempty" xml:"group,omitempty"`
+	// Reference to the host where the tag is assigned.
+	Host *Link `json:"host,omitempty" xml:"host,omitempty"`
+	// Reference to the parent tag of this tag.
+	Parent *Link `json:"parent,omitempty" xml:"parent,omitempty"`
+	// Reference to the template where the tag is assigned.
+	Template *Link `json:"template,omitempty" xml:"template,omitempty"`
+	// Reference to the user where the tag is assigned.
+	User *Link `json:"user,omitempty" xml:"user,omitempty"`
+	// Reference to the virtual machine where the tag is assigned.
+	VM *Link `json:"vm,omitempty" xml:"vm,omitempty"`
+}
+
+// SetParent Places the tag under the parent tag, the change is sent to the server on Save
+func (tag *Tag) SetParent(parent *Tag) {
+	if parent == nil {
+		tag.Parent = nil
+		return
+	}
+	tag.Parent = &Link{ID: parent.ID}
+}
+
+// GetParent Retrieve the parent tag from the server, nil if the tag is the root tag
+func (tag *Tag) GetParent() (*Tag, error) {
+	if tag.Parent == nil || tag.Parent.ID == "" || tag.ID == RootTagID {
+		return nil, nil
+	}
+	return tag.Con.GetTag(tag.Parent.ID)
+}
+
+// GetChildren Retrieve the tags whose parent is this tag
+func (tag *Tag) GetChildren() ([]*Tag, error) {
+	if tag.ID == "" {
+		return nil, errors.New("Tag has not been saved to the server")
+	}
+	allTags, err := tag.Con.GetAllTags()
+	if err != nil {
+		return nil, err
+	}
+	children := []*Tag{}
+	for _, child := range allTags {
+		if child.Parent != nil && child.Parent.ID == tag.ID && child.ID != tag.ID {
+			children = append(children, child)
+		}
+	}
+	return children, nil
+}
+
+// GetTags Retrieve the tags assigned to a virtual machine, host, template or user
+func (ovirtObject *OvirtObject) GetTags() ([]*Tag, error) {
+	linkResp, err := ovirtObject.getLinkResponse("tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	tags := []*Tag{}
+	for i := range linkResp.Tag {
+		tag := &linkResp.Tag[i]
+		tag.Con = ovirtObject.Con
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// AssignTag Assigns an existing tag to a virtual machine, host, template or user
+func (ovirtObject *OvirtObject) AssignTag(tag *Tag) error {
+	_, err := ovirtObject.AddLinkObject("tags", Tag{
+		OvirtObject: OvirtObject{
+			Link: Link{ID: tag.ID},
+			Name: tag.Name,
+		},
+	}, nil)
+	return err
+}
+
+// UnassignTag Removes the tag from a virtual machine, host, template or user, the tag itself is not deleted
+func (ovirtObject *OvirtObject) UnassignTag(tag *Tag) error {
+	return ovirtObject.RemoveLinkObject("tags", tag.ID, nil)
+}
+
+// userObject Builds an object for the user with the given id that exposes the user sub collections
+func (con *Connection) userObject(userID string) (*OvirtObject, error) {
+	usersLink, err := con.GetLink("users")
+	if err != nil {
+		return nil, err
+	}
+	href := usersLink.Path + "/" + userID
+	return &OvirtObject{
+		Link:  Link{Href: href, ID: userID},
+		Con:   con,
+		Links: []Link{{Rel: "tags", Href: href + "/tags"}, {Rel: "sshpublickeys", Href: href + "/sshpublickeys"}},
+	}, nil
+}
+
+// GetUserTags Retrieve the tags assigned to the user with the given id
+func (con *Connection) GetUserTags(userID string) ([]*Tag, error) {
+	user, err := con.userObject(userID)
+	if err != nil {
+		return nil, err
+	}
+	return user.GetTags()
+}
+
+// AssignUserTag Assigns an existing tag to the user with the given id
+func (con *Connection) AssignUserTag(userID string, tag *Tag) error {
+	user, err := con.userObject(userID)
+	if err != nil {
+		return err
+	}
+	return user.AssignTag(tag)
+}
+
+// UnassignUserTag Removes the tag from the user with the given id
+func (con *Connection) UnassignUserTag(userID string, tag *Tag) error {
+	user, err := con.userObject(userID)
+	if err != nil {
+		return err
+	}
+	return user.UnassignTag(tag)
+}
+
+// tagSearch Builds the search query matching objects carrying the tag
+func tagSearch(tagName string) string {
+	return "tag=" + SearchValue(tagName)
+}
+
+// GetVMsByTag Retrieve the VMs the tag, or one of its children, is assigned to
+func (con *Connection) GetVMsByTag(tagName string) ([]*VM, error) {
+	return con.SearchVMs(tagSearch(tagName))
+}
+
+// GetHostsByTag Retrieve the hosts the tag, or one of its children, is assigned to
+func (con *Connection) GetHostsByTag(tagName string) ([]*Host, error) {
+	return con.SearchHosts(tagSearch(tagName))
+}
+
+// GetTemplatesByTag Retrieve the templates the tag, or one of its children, is assigned to
+func (con *Connection) GetTemplatesByTag(tagName string) ([]*Template, error) {
+	return con.SearchTemplates(tagSearch(tagName))
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+func TestTag(t *testing.T) {
+	t.Parallel()
+	con := newTestConnection(t)
+	parentTag := con.NewTag()
+	parentTag.Name = "test-parent-tag"
+	err := parentTag.Save()
+	if err != nil {
+		t.Fatal("Error creating parent tag", err)
+	}
+	defer parentTag.Delete()
+	childTag := con.NewTag()
+	// The search by tag quotes the spaces and operators of the name
+	childTag.Name = "test child and tag"
+	childTag.SetParent(parentTag)
+	err = childTag.Save()
+	if err != nil {
+		t.Fatal("Error creating child tag", err)
+	}
+	defer childTag.Delete()
+	retrievedParent, err := childTag.GetParent()
+	if err != nil {
+		t.Fatal("Error retrieving parent tag", err)
+	}
+	if retrievedParent.ID != parentTag.ID {
+		t.Error("Child tag has the wrong parent", retrievedParent.ID)
+	}
+	children, err := parentTag.GetChildren()
+	if err != nil {
+		t.Fatal("Error retrieving child tags", err)
+	}
+	if len(children) != 1 || children[0].ID != childTag.ID {
+		t.Error("Parent tag does not list the child tag", children)
+	}
+	allHosts, err := con.GetAllHosts()
+	if err != nil || len(allHosts) == 0 {
+		t.Fatal("Error finding a host to tag", err)
+	}
+	host := allHosts[0]
+	err = host.AssignTag(childTag)
+	if err != nil {
+		t.Fatal("Error assigning tag to host", err)
+	}
+	hostTags, err := host.GetTags()
+	if err != nil {
+		t.Fatal("Error retrieving host tags", err)
+	}
+	found := false
+	for _, tag := range hostTags {
+		found = found || tag.ID == childTag.ID
+	}
+	if !found {
+		t.Error("Assigned tag is not listed on the host")
+	}
+	taggedHosts, err := con.GetHostsByTag(childTag.Name)
+	if err != nil {
+		t.Fatal("Error searching hosts by tag", err)
+	}
+	if len(taggedHosts) != 1 || taggedHosts[0].ID != host.ID {
+		t.Error("Tag search did not return the tagged host", taggedHosts)
+	}
+	err = host.UnassignTag(childTag)
+	if err != nil {
+		t.Error("Error unassigning tag from host", err)
+	}
+}
+
+func TestSearchValue(t *testing.T) {
+	t.Parallel()
+	for value, expected := range map[string]string{
+		"web":        `"web"`,
+		"web and db": `"web and db"`,
+		`say "hi"`:   `"say \"hi\""`,
+		`back\slash`: `"back\\slash"`,
+	} {
+		if quoted := ovirtapi.SearchValue(value); quoted != expected {
+			t.Errorf("Quoted %s as %s instead of %s", value, quoted, expected)
+		}
+	}
+}
@@ -7,46 +7,63 @@
 package ovirtapi
 
 type TemplateVersion struct {
-	VersionName   string `json:"version_name,omitempty"`
-	VersionNumber string `json:"version_number,omitempty"`
+	VersionName   string `json:"version_name,omitempty" xml:"version_name,omitempty"`
+	VersionNumber string `json:"version_number,omitempty" xml:"version_number,omitempty"`
 }
 
 type Template struct {
 	OvirtObject
-	Comment                    string             `json:"comment,omitempty"`
-	Bios                       *Bios              `json:"bios,omitempty"`
-	CPU                        *CPU               `json:"cpu,omitempty"`
-	CPUShares                  string             `json:"cpu_shares,omitempty"`
-	CreationTime               int                `json:"creation_time,omitempty"`
-	Display                    *Display           `json:"display,omitempty"`
-	HighAvailability           *HighAvailability  `json:"high_availability,omitempty"`
-	LargeIcon                  *Link              `json:"large_icon,omitempty"`
-	Memory                     int                `json:"memory,string,omitempty"`
-	MemoryPolicy               *MemoryPolicy      `json:"memory_policy,omitempty"`
-	Migration                  *MigrationOptions  `json:"migration,omitempty"`
-	MigrationDowntime          string             `json:"migration_downtime,omitempty"`
-	Origin                     string             `json:"origin,omitempty"`
-	Os                         *OperatingSystem   `json:"os,omitempty"`
-	SmallIcon                  *Link              `json:"small_icon,omitempty"`
-	StartPaused                string             `json:"start_paused,omitempty"`
-	Stateless                  string             `json:"stateless,omitempty"`
-	TimeZone                   *TimeZone          `json:"time_zone,omitempty"`
-	Type                       string             `json:"type,omitempty"`
-	USB                        *USB               `json:"usb,omitempty"`
-	Cluster                    *Link              `json:"cluster,omitempty"`
-	CPUProfile                 *Link              `json:"cpu_profile,omitempty"`
-	Quota                      *Link              `json:"quota,omitempty"`
-	NextRunConfigurationExists string             `json:"next_run_configuration_exists,omitempty"`
-	NumaTuneMode               string             `json:"numa_tune_mode,omitempty"`
-	PlacementPolicy            *VMPlacementPolicy `json:"placement_policy,omitempty"`
-	RunOnce                    string             `json:"run_once,omitempty"`
-	StartTime                  int                `json:"start_time,omitempty"`
-	StopTime                   int                `json:"stop_time,omitempty"`
-	Status                     string             `json:"status,omitempty"`
-	Host                       *Link              `json:"host,omitempty"`
-	InstanceType               *Link              `json:"instance_type,omitempty"`
-	OriginalTemplate           *Link              `json:"original_template,omitempty"`
-	Template                   *Link              `json:"template,omitempty"`
-	Version                    *TemplateVersion   `json:"version,omitempty"`
-	VM                         *VM                `json:"vm,omitempty"`
+	Comment                    string             `json:"comment,omitempty" xml:"comment,omitempty"`
+	Bios                       *Bios              `json:"bios,omitempty" xml:"bios,omitempty"`
+	CPU                        *CPU               `json:"cpu,omitempty" xml:"cpu,omitempty"`
+	CPUShares                  string             `json:"cpu_shares,omitempty" xml:"cpu_shares,omitempty"`
+	CreationTime               Timestamp          `json:"creation_time,omitempty" xml:"creation_time,omitempty"`
+	Display                    *Display           `json:"display,omitempty" xml:"display,omitempty"`
+	HighAvailability           *HighAvailability  `json:"high_availability,omitempty" xml:"high_availability,omitempty"`
+	LargeIcon                  *Link              `json:"large_icon,omitempty" xml:"large_icon,omitempty"`
+	Memory                     int                `json:"memory,string,omitempty" xml:"memory,omitempty"`
+	MemoryPolicy               *MemoryPolicy      `json:"memory_policy,omitempty" xml:"memory_policy,omitempty"`
+	Migration                  *MigrationOptions  `json:"migration,omitempty" xml:"migration,omitempty"`
+	MigrationDowntime          string             `json:"migration_downtime,omitempty" xml:"migration_downtime,omitempty"`
+	Origin                     string             `json:"origin,omitempty" xml:"origin,omitempty"`
+	Os                         *OperatingSystem   `json:"os,omitempty" xml:"os,omitempty"`
+	SmallIcon                  *Link              `json:"small_icon,omitempty" xml:"small_icon,omitempty"`
+	StartPaused                string             `json:"start_paused,omitempty" xml:"start_paused,omitempty"`
+	Stateless                  string             `json:"stateless,omitempty" xml:"stateless,omitempty"`
+	TimeZone                   *TimeZone          `json:"time_zone,omitempty" xml:"time_zone,omitempty"`
+	Type                       string             `json:"type,omitempty" xml:"type,omitempty"`
+	USB                        *USB               `json:"usb,omitempty" xml:"usb,omitempty"`
+	Cluster                    *Link              `json:"cluster,omitempty" xml:"cluster,omitempty"`
+	CPUProfile                 *CPUProfile        `json:"cpu_profile,omitempty" xml:"cpu_profile,omitempty"`
+	Quota                      *Link              `json:"quota,omitempty" xml:"quota,omitempty"`
+	NextRunConfigurationExists string             `json:"next_run_configuration_exists,omitempty" xml:"next_run_configuration_exists,omitempty"`
+	NumaTuneMode               string             `json:"numa_tune_mode,omitempty" xml:"numa_tune_mode,omitempty"`
+	PlacementPolicy            *VMPlacementPolicy `json:"placement_policy,omitempty" xml:"placement_policy,omitempty"`
+	RunOnce                    string             `json:"run_once,omitempty" xml:"run_once,omitempty"`
+	StartTime                  Timestamp          `json:"start_time,omitempty" xml:"start_time,omitempty"`
+	StopTime                   Timestamp          `json:"stop_time,omitempty" xml:"stop_time,omitempty"`
+	Status                     string             `json:"status,omitempty" xml:"status,omitempty"`
+	Host                       *Link              `json:"host,omitempty" xml:"host,omitempty"`
+	InstanceType               *Link              `json:"instance_type,omitempty" xml:"instance_type,omitempty"`
+	OriginalTemplate           *Link              `json:"original_template,omitempty" xml:"original_template,omitempty"`
+	Template                   *Link              `json:"template,omitempty" xml:"template,omitempty"`
+	Version                    *TemplateVersion   `json:"version,omitempty" xml:"version,omitempty"`
+	VM                         *VM                `json:"vm,omitempty" xml:"vm,omitempty"`
+}
+
+// SearchTemplates Retrieve the templates matching the search query
+func (con *Connection) SearchTemplates(query string) ([]*Template, error) {
+	body, err := con.SearchLinkBody("templates", query)
+	if err != nil {
+		return nil, err
+	}
+	objects := []*Template{}
+	err = con.decodeList(body, &objects)
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		object.Con = con
+	}
+	return objects, err
 }
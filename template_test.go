@@ -7,34 +7,13 @@
 package ovirtapi_test
 
 import (
-	"os"
-	"strconv"
 	"testing"
 	"time"
-
-	"github.com/EMSL-MSC/ovirtapi"
 )
 
 func TestTemplate(t *testing.T) {
 	t.Parallel()
-	username := os.Getenv("OVIRT_USERNAME")
-	if username == "" {
-		t.Error("OVIRT_USERNAME is not set")
-	}
-	password := os.Getenv("OVIRT_PASSWORD")
-	if password == "" {
-		t.Error("OVIRT_PASSWORD is not set")
-	}
-	url := os.Getenv("OVIRT_URL")
-	if url == "" {
-		t.Error("OVIRT_URL is not set")
-	}
-	con, err := ovirtapi.NewConnection(url, username, password, false)
-	con.Debug, _ = strconv.ParseBool(os.Getenv("DEBUG_TRANSPORT"))
-	if err != nil {
-		t.Error("error creating connection", err)
-		return
-	}
+	con := newTestConnection(t)
 	newTemplate := con.NewTemplate()
 	newTemplate.Name = "test-Template"
 	allVMs, err := con.GetAllVMs()
@@ -0,0 +1,143 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Attribute A key and value describing a span
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Span An operation traced by a Tracer
+type Span interface {
+	// SetAttributes Adds attributes known after the span was started
+	SetAttributes(attributes ...Attribute)
+	// End Ends the span, err is nil when the operation succeeded
+	End(err error)
+}
+
+// Tracer Starts the spans of the requests and operations of a connection, an
+// OpenTelemetry tracer can be adapted by starting its spans from ctx
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+// WithTracer Traces the requests and operations of the connection with tracer
+func WithTracer(tracer Tracer) ConnectionOption {
+	return func(con *Connection) {
+		con.tracer = tracer
+	}
+}
+
+type correlationIDKey struct{}
+
+// ContextWithCorrelationID Returns a context sending id as the correlation_id of the engine requests
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID The correlation id carried by ctx, empty when there is none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// newCorrelationID A random correlation id, shorter than the 50 characters accepted by the engine
+func newCorrelationID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// WithContext Returns a copy of the connection making its requests within ctx.
+// Objects retrieved through the copy make their requests within ctx as well,
+// the trace and correlation id of ctx are propagated to the engine.
+func (con *Connection) WithContext(ctx context.Context) *Connection {
+	derived := *con
+	derived.ctx = ctx
+	return &derived
+}
+
+// Context The context the requests of the connection are made within
+func (con *Connection) Context() context.Context {
+	if con.ctx == nil {
+		return context.Background()
+	}
+	return con.ctx
+}
+
+// startSpan Starts a span when the connection has a tracer and returns a copy
+// of the connection making its requests within the span. The outermost span
+// creates the correlation id when the context does not carry one.
+func (con *Connection) startSpan(name string, attributes ...Attribute) (*Connection, Span) {
+	if con.tracer == nil {
+		return con, noopSpan{}
+	}
+	ctx := con.Context()
+	if CorrelationID(ctx) == "" {
+		ctx = ContextWithCorrelationID(ctx, newCorrelationID())
+	}
+	attributes = append(attributes, Attribute{"correlation_id", CorrelationID(ctx)})
+	ctx, span := con.tracer.Start(ctx, name, attributes...)
+	return con.WithContext(ctx), span
+}
+
+// objectAttributes The attributes identifying the object behind href
+func (con *Connection) objectAttributes(href string, action string) []Attribute {
+	attributes := []Attribute{}
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(href, con.endPoint.Path), "/"), "/")
+	if len(segments) >= 2 {
+		attributes = append(attributes,
+			Attribute{"ovirt.resource", segments[len(segments)-2]},
+			Attribute{"ovirt.id", segments[len(segments)-1]})
+	}
+	if action != "" {
+		attributes = append(attributes, Attribute{"ovirt.action", action})
+	}
+	return attributes
+}
+
+// correlate Returns a copy of requestURL with the correlation id of the context
+func correlate(ctx context.Context, requestURL *url.URL) *url.URL {
+	id := CorrelationID(ctx)
+	if id == "" {
+		return requestURL
+	}
+	correlated := *requestURL
+	query := correlated.Query()
+	query.Set("correlation_id", id)
+	correlated.RawQuery = query.Encode()
+	return &correlated
+}
+
+// requestAttributes The attributes of the span of a request
+func (con *Connection) requestAttributes(verb string, requestURL *url.URL) []Attribute {
+	return []Attribute{
+		{"http.method", verb},
+		{"http.url", requestURL.String()},
+		{"ovirt.resource", con.resourceName(requestURL)},
+	}
+}
+
+func statusAttribute(status int) Attribute {
+	return Attribute{"http.status_code", strconv.Itoa(status)}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attributes ...Attribute) {}
+
+func (noopSpan) End(err error) {}
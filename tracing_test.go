@@ -0,0 +1,163 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+type testSpan struct {
+	name       string
+	parent     *testSpan
+	attributes map[string]string
+	ended      bool
+	err        error
+}
+
+func (span *testSpan) SetAttributes(attributes ...ovirtapi.Attribute) {
+	for _, attribute := range attributes {
+		span.attributes[attribute.Key] = attribute.Value
+	}
+}
+
+func (span *testSpan) End(err error) {
+	span.ended = true
+	span.err = err
+}
+
+type testSpanKey struct{}
+
+type testTracer struct {
+	lock  sync.Mutex
+	spans []*testSpan
+}
+
+func (tracer *testTracer) Start(ctx context.Context, name string, attributes ...ovirtapi.Attribute) (context.Context, ovirtapi.Span) {
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	span := &testSpan{name: name, parent: parent, attributes: map[string]string{}}
+	span.SetAttributes(attributes...)
+	tracer.spans = append(tracer.spans, span)
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func (tracer *testTracer) reset() []*testSpan {
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+	spans := tracer.spans
+	tracer.spans = nil
+	return spans
+}
+
+type queryRecorder struct {
+	lock           sync.Mutex
+	correlationIDs []string
+}
+
+func (recorder *queryRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder.lock.Lock()
+	recorder.correlationIDs = append(recorder.correlationIDs, req.URL.Query().Get("correlation_id"))
+	recorder.lock.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+	url, username, password := testCredentials(t)
+	tracer := &testTracer{}
+	recorder := &queryRecorder{}
+	con, err := ovirtapi.NewConnection(url, username, password, false, ovirtapi.WithTracer(tracer), ovirtapi.WithTransport(recorder), ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	rootSpans := tracer.reset()
+	if len(rootSpans) != 1 || rootSpans[0].name != "ovirt.request" || rootSpans[0].attributes["http.status_code"] != "200" || !rootSpans[0].ended {
+		t.Error("Did not trace the API root request", rootSpans)
+	}
+
+	newVM := con.NewVM()
+	newVM.Name = "test-tracing"
+	allClusters, err := con.GetAllClusters()
+	if err != nil || len(allClusters) == 0 {
+		t.Fatal("Error retrieving clusters", err)
+	}
+	newVM.Cluster = allClusters[0]
+	blank, err := con.GetTemplate("00000000-0000-0000-0000-000000000000")
+	if err != nil {
+		t.Fatal("Error retrieving the blank template", err)
+	}
+	newVM.Template = blank
+	tracer.reset()
+	err = newVM.Save()
+	if err != nil {
+		t.Fatal("Error creating vm", err)
+	}
+	defer newVM.Delete()
+	spans := tracer.reset()
+	if len(spans) != 2 || spans[0].name != "VM.Save" || spans[0].attributes["ovirt.id"] != newVM.ID {
+		t.Fatal("Did not trace VM.Save", spans)
+	}
+	if spans[1].parent != spans[0] || spans[1].attributes["http.method"] != "POST" {
+		t.Error("Did not trace the requests within VM.Save", spans)
+	}
+	correlationID := spans[0].attributes["correlation_id"]
+	if correlationID == "" || spans[1].attributes["correlation_id"] != correlationID {
+		t.Error("Did not share the correlation id within VM.Save", spans[0].attributes, spans[1].attributes)
+	}
+	if newVM.Con != con {
+		t.Error("The saved vm does not use the connection of the vm")
+	}
+
+	err = newVM.WaitForStatus("down", 10*time.Millisecond, 5*time.Second)
+	if err != nil {
+		t.Fatal("Error waiting for vm", err)
+	}
+	tracer.reset()
+	ctx := ovirtapi.ContextWithCorrelationID(context.Background(), "test-tracing")
+	newVM.Con = con.WithContext(ctx)
+	err = newVM.Start("", "", "", "", "", nil)
+	if err != nil {
+		t.Fatal("Error starting vm", err)
+	}
+	spans = tracer.reset()
+	if len(spans) != 2 || spans[0].name != "ovirt.action" || spans[1].parent != spans[0] {
+		t.Fatal("Did not trace the start action", spans)
+	}
+	for key, value := range map[string]string{"ovirt.resource": "vms", "ovirt.id": newVM.ID, "ovirt.action": "start", "correlation_id": "test-tracing"} {
+		if spans[0].attributes[key] != value {
+			t.Errorf("Expected span attribute %s=%s, got %s", key, value, spans[0].attributes[key])
+		}
+	}
+	if recorder.correlationIDs[len(recorder.correlationIDs)-1] != "test-tracing" {
+		t.Error("Did not send the correlation id to the engine", recorder.correlationIDs)
+	}
+
+	err = newVM.WaitForStatus("up", 10*time.Millisecond, 5*time.Second)
+	if err != nil {
+		t.Fatal("Error waiting for vm", err)
+	}
+	spans = tracer.reset()
+	if spans[0].name != "VM.WaitForStatus" || spans[0].attributes["ovirt.polls"] == "" || spans[0].err != nil {
+		t.Error("Did not trace the wait", spans)
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	newVM.Con = con.WithContext(cancelled)
+	err = newVM.WaitForStatus("down", 10*time.Millisecond, 5*time.Second)
+	if !errors.Is(err, context.Canceled) {
+		t.Error("Did not stop waiting when the context was cancelled", err)
+	}
+	newVM.Con = con
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// APIVersion The major version of the API sent in the Version header of every request
+const APIVersion = "4"
+
+// ParseVersion Parses a version such as 4.4.10.7 or 4.4.10.7-1.el8, missing parts are 0
+func ParseVersion(version string) (Version, error) {
+	parsed := Version{FullVersion: version}
+	release := strings.SplitN(strings.TrimSpace(version), "-", 2)[0]
+	parts := strings.Split(release, ".")
+	if release == "" || len(parts) > 4 {
+		return parsed, fmt.Errorf("Invalid version %q", version)
+	}
+	numbers := []*int{&parsed.Major, &parsed.Minor, &parsed.Build, &parsed.Revision}
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return parsed, fmt.Errorf("Invalid version %q", version)
+		}
+		*numbers[i] = number
+	}
+	return parsed, nil
+}
+
+// Compare Returns -1, 0 or 1 when the version is older, the same or newer than other
+func (version Version) Compare(other Version) int {
+	a := []int{version.Major, version.Minor, version.Build, version.Revision}
+	b := []int{other.Major, other.Minor, other.Build, other.Revision}
+	for i := range a {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// AtLeast Whether the version is major.minor or newer
+func (version Version) AtLeast(major int, minor int) bool {
+	return version.Compare(Version{Major: major, Minor: minor}) >= 0
+}
+
+// String The version as major.minor.build.revision
+func (version Version) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", version.Major, version.Minor, version.Build, version.Revision)
+}
+
+// Version The version of the engine, from the product information of the API root
+func (con *Connection) Version() Version {
+	info := con.root.ProductInfo.Version
+	version, err := ParseVersion(strings.Join([]string{info.Major, info.Minor, info.Build, info.Revision}, "."))
+	if err != nil {
+		version, _ = ParseVersion(info.FullVersion)
+	}
+	version.FullVersion = info.FullVersion
+	return version
+}
+
+// Capability A feature of the API that is not supported by every engine
+type Capability string
+
+const (
+	// AffinityLabels Affinity labels on hosts and virtual machines
+	AffinityLabels Capability = "affinity labels"
+	// AffinityRules Separate host and virtual machine rules in affinity groups
+	AffinityRules Capability = "affinity group rules"
+	// VMLeases Virtual machine leases on a storage domain for high availability
+	VMLeases Capability = "virtual machine leases"
+	// IncrementalBackup Incremental backups of virtual machine disks
+	IncrementalBackup Capability = "incremental backup"
+)
+
+// capabilities The oldest engine version supporting each capability
+var capabilities = map[Capability]Version{
+	AffinityLabels:    {Major: 4, Minor: 1},
+	AffinityRules:     {Major: 4, Minor: 1},
+	VMLeases:          {Major: 4, Minor: 1},
+	IncrementalBackup: {Major: 4, Minor: 4},
+}
+
+// UnsupportedError The engine is too old for a capability
+type UnsupportedError struct {
+	Capability Capability
+	Required   Version
+	Engine     Version
+}
+
+func (e UnsupportedError) Error() string {
+	return fmt.Sprintf("%s requires engine version %d.%d or newer, the engine version is %d.%d",
+		e.Capability, e.Required.Major, e.Required.Minor, e.Engine.Major, e.Engine.Minor)
+}
+
+// Supports Whether the engine supports the capability, engines of unknown version are assumed to support it
+func (con *Connection) Supports(capability Capability) bool {
+	return con.Require(capability) == nil
+}
+
+// Require Returns an UnsupportedError when the engine does not support the capability
+func (con *Connection) Require(capability Capability) error {
+	required, ok := capabilities[capability]
+	if !ok {
+		return nil
+	}
+	version := con.Version()
+	if version.Major == 0 || version.Compare(required) >= 0 {
+		return nil
+	}
+	return UnsupportedError{Capability: capability, Required: required, Engine: version}
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+	version, err := ovirtapi.ParseVersion("4.4.10.7-1.el8")
+	if err != nil || version.Major != 4 || version.Minor != 4 || version.Build != 10 || version.Revision != 7 {
+		t.Error("Did not parse the version", version, err)
+	}
+	for _, invalid := range []string{"", "4.x", "1.2.3.4.5", "-1"} {
+		if _, err = ovirtapi.ParseVersion(invalid); err == nil {
+			t.Error("Did not reject invalid version", invalid)
+		}
+	}
+	older, _ := ovirtapi.ParseVersion("4.1")
+	if older.Compare(version) != -1 || version.Compare(older) != 1 || version.Compare(version) != 0 {
+		t.Error("Did not compare the versions")
+	}
+	if !version.AtLeast(4, 4) || version.AtLeast(4, 5) || older.AtLeast(4, 2) {
+		t.Error("Did not check the minimum version")
+	}
+	if older.String() != "4.1.0.0" {
+		t.Error("Unexpected version string", older.String())
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+	engine := ovirtapitest.NewEngine()
+	defer engine.Close()
+	engine.SetVersion(4, 0, 6, 3)
+	var version string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		version = req.Header.Get("Version")
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, ovirtapi.WithTransport(transport), ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	if version != ovirtapi.APIVersion {
+		t.Error("Did not send the Version header", version)
+	}
+	if con.Version().String() != "4.0.6.3" || con.Version().FullVersion != "4.0.6.3-fake" {
+		t.Error("Did not parse the engine version", con.Version())
+	}
+	if con.Supports(ovirtapi.AffinityLabels) {
+		t.Error("Affinity labels are not supported before 4.1")
+	}
+	label := con.NewAffinityLabel()
+	label.Name = "test-capabilities"
+	err = label.Save()
+	var unsupported ovirtapi.UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Capability != ovirtapi.AffinityLabels || unsupported.Required.Minor != 1 {
+		t.Error("Did not return an unsupported error", err)
+	}
+	vm := con.NewVM()
+	vm.Name = "test-capabilities"
+	vm.Lease = &ovirtapi.StorageDomainLease{StorageDomain: &ovirtapi.Link{ID: "any"}}
+	if err = vm.Save(); !errors.As(err, &unsupported) || unsupported.Capability != ovirtapi.VMLeases {
+		t.Error("Did not return an unsupported error for the lease", err)
+	}
+	if label.Href != "" || vm.Href != "" {
+		t.Error("Saved an unsupported object")
+	}
+	if err = con.Require(ovirtapi.IncrementalBackup); !errors.As(err, &unsupported) || unsupported.Required.Minor != 4 {
+		t.Error("Incremental backups are not supported before 4.4", err)
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -7,435 +7,443 @@
 package ovirtapi
 
 import (
-	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 )
 
 // DiskAttachment The underlying storage interface of disks communication with controller.
 type DiskAttachment struct {
 	Link
 	// Defines whether the disk is active in the virtual machine it's attached to.
-	Active string `json:"active,omitempty"`
+	Active string `json:"active,omitempty" xml:"active,omitempty"`
 	// Defines whether the disk is bootable.
-	Bootable string `json:"bootable,omitempty"`
+	Bootable string `json:"bootable,omitempty" xml:"bootable,omitempty"`
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
 	// The type of interface driver used to connect the disk device to the virtual machine.
-	Interface string `json:"interface,omitempty"`
+	Interface string `json:"interface,omitempty" xml:"interface,omitempty"`
 	// The logical name of the virtual machine's disk, as seen from inside the virtual machine.
-	LogicalName string `json:"logical_name,omitempty"`
+	LogicalName string `json:"logical_name,omitempty" xml:"logical_name,omitempty"`
 	// A human-readable name in plain text.
-	Name string `json:"name,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
 	// Defines whether the virtual machine passes discard commands to the storage.
-	PassDiscard string `json:"pass_discard,omitempty"`
+	PassDiscard string `json:"pass_discard,omitempty" xml:"pass_discard,omitempty"`
 	// Indicates whether the disk is connected to the virtual machine as read only.
-	ReadOnly string `json:"read_only,omitempty"`
+	ReadOnly string `json:"read_only,omitempty" xml:"read_only,omitempty"`
 	// Defines whether SCSI reservation is enabled for this disk.
-	UsesSCSIReservation string `json:"uses_scsi_reservation,omitempty"`
+	UsesSCSIReservation string `json:"uses_scsi_reservation,omitempty" xml:"uses_scsi_reservation,omitempty"`
 	// The reference to the disk.
-	Disk *Disk `json:"disk,omitempty"`
+	Disk *Disk `json:"disk,omitempty" xml:"disk,omitempty"`
 	// The reference to the template.
-	Template *Template `json:"template,omitempty"`
+	Template *Template `json:"template,omitempty" xml:"template,omitempty"`
 	// The reference to the virtual machine.
-	VM *VM `json:"vm,omitempty"`
+	VM *VM `json:"vm,omitempty" xml:"vm,omitempty"`
 }
 
 // Bios ...
 type Bios struct {
 	BootMenu struct {
-		Enabled string `json:"enabled"`
-	} `json:"boot_menu"`
+		Enabled string `json:"enabled" xml:"enabled"`
+	} `json:"boot_menu" xml:"boot_menu"`
 }
 
 // Console Representation for serial console device.
 type Console struct {
-	Enabled string `json:"enabled,omitempty"`
+	Enabled string `json:"enabled,omitempty" xml:"enabled,omitempty"`
 }
 
 // Core ...
 type Core struct {
-	Index  int `json:"index,omitempty,string"`
-	Socket int `json:"socket,omitempty,string"`
+	Index  int `json:"index,omitempty,string" xml:"index,omitempty"`
+	Socket int `json:"socket,omitempty,string" xml:"socket,omitempty"`
 }
 
 // CustomProperty Custom property representation.
 type CustomProperty struct {
-	Name   int `json:"name,omitempty"`
-	Regexp int `json:"regexp,omitempty"`
-	Value  int `json:"value,omitempty"`
+	Name   string `json:"name,omitempty" xml:"name,omitempty"`
+	Regexp string `json:"regexp,omitempty" xml:"regexp,omitempty"`
+	Value  string `json:"value,omitempty" xml:"value,omitempty"`
 }
 
 // VCPUPin ...
 type VCPUPin struct {
-	CPUSet string `json:"cpu_set,omitempty"`
-	VCPU   int    `json:"vcpu,omitempty,string"`
+	CPUSet string `json:"cpu_set,omitempty" xml:"cpu_set,omitempty"`
+	VCPU   int    `json:"vcpu,omitempty,string" xml:"vcpu,omitempty"`
 }
 
 // CPUTune ...
 type CPUTune struct {
-	VCPUPins []VCPUPin `json:"vcpu_pins,omitempty"`
+	VCPUPins []VCPUPin `json:"vcpu_pins>vcpu_pin,omitempty" xml:"vcpu_pins>vcpu_pin,omitempty"`
 }
 
 // CPUTopology ...
 type CPUTopology struct {
-	Cores   int `json:"cores,omitempty,string"`
-	Sockets int `json:"sockets,omitempty,string"`
-	Threads int `json:"threads,omitempty,string"`
+	Cores   int `json:"cores,omitempty,string" xml:"cores,omitempty"`
+	Sockets int `json:"sockets,omitempty,string" xml:"sockets,omitempty"`
+	Threads int `json:"threads,omitempty,string" xml:"threads,omitempty"`
 }
 
 // CPU ...
 type CPU struct {
-	Architecture string       `json:"architecture,omitempty"`
-	Cores        []Core       `json:"cores,omitempty"`
-	CPUTune      *CPUTune     `json:"cpu_tune,omitempty"`
-	Level        int          `json:"level,omitempty"`
-	CPUMode      string       `json:"cpu_mode,omitempty"`
-	Name         string       `json:"name,omitempty"`
-	Speed        int          `json:"speed,omitempty"`
-	Topology     *CPUTopology `json:"topology,omitempty"`
-	Type         string       `json:"type,omitempty"`
+	Architecture string       `json:"architecture,omitempty" xml:"architecture,omitempty"`
+	Cores        []Core       `json:"cores>core,omitempty" xml:"cores>core,omitempty"`
+	CPUTune      *CPUTune     `json:"cpu_tune,omitempty" xml:"cpu_tune,omitempty"`
+	Level        int          `json:"level,omitempty" xml:"level,omitempty"`
+	CPUMode      string       `json:"cpu_mode,omitempty" xml:"cpu_mode,omitempty"`
+	Name         string       `json:"name,omitempty" xml:"name,omitempty"`
+	Speed        int          `json:"speed,omitempty" xml:"speed,omitempty"`
+	Topology     *CPUTopology `json:"topology,omitempty" xml:"topology,omitempty"`
+	Type         string       `json:"type,omitempty" xml:"type,omitempty"`
 }
 
 // Certificate ...
 type Certificate struct {
-	Comment      string `json:"comment,omitempty"`
-	Content      string `json:"content,omitempty"`
-	Description  string `json:"description,omitempty"`
-	ID           string `json:"id,omitempty"`
-	Name         string `json:"name,omitempty"`
-	Organization string `json:"organization,omitempty"`
-	Subject      string `json:"subject,omitempty"`
+	Comment      string `json:"comment,omitempty" xml:"comment,omitempty"`
+	Content      string `json:"content,omitempty" xml:"content,omitempty"`
+	Description  string `json:"description,omitempty" xml:"description,omitempty"`
+	ID           string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name         string `json:"name,omitempty" xml:"name,omitempty"`
+	Organization string `json:"organization,omitempty" xml:"organization,omitempty"`
+	Subject      string `json:"subject,omitempty" xml:"subject,omitempty"`
 }
 
 // Display Represents a graphic console configuration.
 type Display struct {
 	// The IP address of the guest to connect the graphic console client to.
-	Address string `json:"address,omitempty"`
+	Address string `json:"address,omitempty" xml:"address,omitempty"`
 	// Indicates if to override the display address per host.
-	AllowOverride string `json:"allow_override,omitempty"`
+	AllowOverride string `json:"allow_override,omitempty" xml:"allow_override,omitempty"`
 	// The TLS certificate in case of a TLS connection.
-	Certificate *Certificate `json:"certificate,omitempty"`
+	Certificate *Certificate `json:"certificate,omitempty" xml:"certificate,omitempty"`
 	// Indicates whether a user is able to copy and paste content from an external host into the graphic console.
-	CopyPasteEnabled string `json:"copy_paste_enabled,omitempty"`
+	CopyPasteEnabled string `json:"copy_paste_enabled,omitempty" xml:"copy_paste_enabled,omitempty"`
 	// Returns the action that will take place when the graphic console is disconnected.
-	DisconnectAction string `json:"disconnect_action,omitempty"`
+	DisconnectAction string `json:"disconnect_action,omitempty" xml:"disconnect_action,omitempty"`
 	// Indicates if a user is able to drag and drop files from an external host into the graphic console.
-	FileTransferEnabled string `json:"file_transfer_enabled,omitempty"`
+	FileTransferEnabled string `json:"file_transfer_enabled,omitempty" xml:"file_transfer_enabled,omitempty"`
 	// The keyboard layout to use with this graphic console.
-	KeyboardLayout string `json:"keyboard_layout,omitempty"`
+	KeyboardLayout string `json:"keyboard_layout,omitempty" xml:"keyboard_layout,omitempty"`
 	// The number of monitors opened for this graphic console.
-	Monitors int `json:"monitors,omitempty,string"`
+	Monitors int `json:"monitors,omitempty,string" xml:"monitors,omitempty"`
 	// The port address on the guest to connect the graphic console client to.
-	Port int `json:"port,omitempty,string"`
+	Port int `json:"port,omitempty,string" xml:"port,omitempty"`
 	// The proxy IP which will be used by the graphic console client to connect to the guest.
-	Proxy string `json:"proxy,omitempty"`
+	Proxy string `json:"proxy,omitempty" xml:"proxy,omitempty"`
 	// The secured port address on the guest, in case of using TLS, to connect the graphic console client to.
-	SecurePort int `json:"secure_port,omitempty,string"`
+	SecurePort int `json:"secure_port,omitempty,string" xml:"secure_port,omitempty"`
 	// Indicates if to use one PCI slot for each monitor or to use a single PCI channel for all multiple monitors.
-	SingleQxlPci string `json:"single_qxl_pci,omitempty"`
+	SingleQxlPci string `json:"single_qxl_pci,omitempty" xml:"single_qxl_pci,omitempty"`
 	// Indicates if to use smart card authentication.
-	SmartcardEnabled string `json:"smartcard_enabled,omitempty"`
+	SmartcardEnabled string `json:"smartcard_enabled,omitempty" xml:"smartcard_enabled,omitempty"`
 	// The graphic console protocol type.
-	Type string `json:"type,omitempty"`
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
 }
 
 // GuestOperatingSystem Represents an operating system installed on the virtual machine.
 type GuestOperatingSystem struct {
 	// The architecture of the operating system, such as x86_64.
-	Architecture string `json:"architecture,omitempty"`
+	Architecture string `json:"architecture,omitempty" xml:"architecture,omitempty"`
 	// Code name of the operating system, such as Maipo.
-	Codename string `json:"codename,omitempty"`
+	Codename string `json:"codename,omitempty" xml:"codename,omitempty"`
 	// Full name of operating system distribution.
-	Distribution string `json:"distribution,omitempty"`
+	Distribution string `json:"distribution,omitempty" xml:"distribution,omitempty"`
 	// Family of operating system, such as Linux.
-	Family string `json:"family,omitempty"`
+	Family string `json:"family,omitempty" xml:"family,omitempty"`
 	// Kernel version of the operating system.
-	Kernel *Kernel `json:"kernel,omitempty"`
+	Kernel *Kernel `json:"kernel,omitempty" xml:"kernel,omitempty"`
 	// Version of the installed operating system.
-	Version *Version `json:"version,omitempty"`
+	Version *Version `json:"version,omitempty" xml:"version,omitempty"`
 }
 
 // HighAvailability Type representing high availability of a virtual machine.
 type HighAvailability struct {
-	Enabled  string `json:"enabled,omitempty"`
-	Priority int    `json:"priority,omitempty,string"`
+	Enabled  string `json:"enabled,omitempty" xml:"enabled,omitempty"`
+	Priority int    `json:"priority,omitempty,string" xml:"priority,omitempty"`
 }
 
 // Configuration ...
 type Configuration struct {
-	Data string `json:"data,omitempty"`
-	Type string `json:"type,omitempty"`
+	Data string `json:"data,omitempty" xml:"data,omitempty"`
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
 }
 
 // DNS Represents the DNS resolver configuration.
 type DNS struct {
 	// Array of hosts serving as search domains.
-	SearchDomains []Host `json:"search_domains,omitempty"`
+	SearchDomains []Host `json:"search_domains>host,omitempty" xml:"search_domains>host,omitempty"`
 	// Array of hosts serving as DNS servers.
-	Servers []Host `json:"servers,omitempty"`
+	Servers []Host `json:"servers>host,omitempty" xml:"servers>host,omitempty"`
 }
 
 // MAC Represents a MAC address of a virtual network interface.
 type MAC struct {
 	// MAC Address
-	Address string `json:"address,omitempty"`
+	Address string `json:"address,omitempty" xml:"address,omitempty"`
 }
 
 // NIC Represents a virtual machine NIC.
 type NIC struct {
 	// Defines how an IP address is assigned to the NIC.
-	BootProtocol string `json:"boot_protocol,omitempty"`
+	BootProtocol string `json:"boot_protocol,omitempty" xml:"boot_protocol,omitempty"`
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
 	// A unique identifier.
-	ID string `json:"id,omitempty"`
+	ID string `json:"id,omitempty" xml:"id,attr,omitempty"`
 	// The type of driver used for the NIC.
-	Interface string `json:"interface,omitempty"`
+	Interface string `json:"interface,omitempty" xml:"interface,omitempty"`
 	// Defines if the NIC is linked to the virtual machine.
-	Linked string `json:"linked,omitempty"`
+	Linked string `json:"linked,omitempty" xml:"linked,omitempty"`
 	// The MAC address of the interface.
-	MAC *MAC `json:"mac,omitempty"`
+	MAC *MAC `json:"mac,omitempty" xml:"mac,omitempty"`
 	// A human-readable name in plain text.
-	Name string `json:"name,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
 	// Defines if the network interface should be activated upon operation system startup.
-	OnBoot string `json:"on_boot,omitempty"`
+	OnBoot string `json:"on_boot,omitempty" xml:"on_boot,omitempty"`
 	// Defines if the NIC is plugged in to the virtual machine.
-	Plugged string `json:"plugged,omitempty"`
+	Plugged string `json:"plugged,omitempty" xml:"plugged,omitempty"`
 }
 
 // NetworkConfiguration ...
 type NetworkConfiguration struct {
-	DNS  DNS   `json:"dns,omitempty"`
-	NICs []NIC `json:"nics,omitempty"`
+	DNS  DNS   `json:"dns,omitempty" xml:"dns,omitempty"`
+	NICs []NIC `json:"nics>nic,omitempty" xml:"nics>nic,omitempty"`
 }
 
 // AuthorizedKey ...
 type AuthorizedKey struct {
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
 	// A unique identifier.
-	ID  string `json:"id,omitempty"`
-	Key string `json:"key,omitempty"`
+	ID  string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Key string `json:"key,omitempty" xml:"key,omitempty"`
 	// A human-readable name in plain text.
-	Name string `json:"name,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
 }
 
 // CloudInit ...
 type CloudInit struct {
-	AuthorizedKeys       []AuthorizedKey       `json:"authorized_keys,omitempty"`
-	Files                []File                `json:"files,omitempty"`
-	Host                 *Host                 `json:"host,omitempty"`
-	NetworkConfiguration *NetworkConfiguration `json:"network_configuration,omitempty"`
-	RegenerateSSHKeys    string                `json:"regenerate_ssh_keys,omitempty"`
-	Timezone             string                `json:"timezone,omitempty"`
-	Users                []User                `json:"users,omitempty"`
+	AuthorizedKeys       []AuthorizedKey       `json:"authorized_keys>authorized_key,omitempty" xml:"authorized_keys>authorized_key,omitempty"`
+	Files                []File                `json:"files>file,omitempty" xml:"files>file,omitempty"`
+	Host                 *Host                 `json:"host,omitempty" xml:"host,omitempty"`
+	NetworkConfiguration *NetworkConfiguration `json:"network_configuration,omitempty" xml:"network_configuration,omitempty"`
+	RegenerateSSHKeys    string                `json:"regenerate_ssh_keys,omitempty" xml:"regenerate_ssh_keys,omitempty"`
+	Timezone             string                `json:"timezone,omitempty" xml:"timezone,omitempty"`
+	Users                []User                `json:"users>user,omitempty" xml:"users>user,omitempty"`
 }
 
 // File ...
 type File struct {
 	// Free text containing comments about this object.
-	Comment string `json:"comment,omitempty"`
-	Content string `json:"content,omitempty"`
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	Content string `json:"content,omitempty" xml:"content,omitempty"`
 	// A human-readable description in plain text.
-	Description string `json:"description,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
 	// A unique identifier.
-	ID string `json:"id,omitempty"`
+	ID string `json:"id,omitempty" xml:"id,attr,omitempty"`
 	// A human-readable name in plain text.
-	Name string `json:"name,omitempty"`
-	Type string `json:"type,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
 }
 
 // IP Represents the IP configuration of a network interface.
 type IP struct {
 	// The text representation of the IP address.
-	Address string `json:"address,omitempty"`
+	Address string `json:"address,omitempty" xml:"address,omitempty"`
 	// The address of the default gateway.
-	Gateway string `json:"gateway,omitempty"`
+	Gateway string `json:"gateway,omitempty" xml:"gateway,omitempty"`
 	// The network mask.
-	Netmask string `json:"netmask,omitempty"`
+	Netmask string `json:"netmask,omitempty" xml:"netmask,omitempty"`
 	// The version of the IP protocol.
-	Version string `json:"version,omitempty"`
+	Version string `json:"version,omitempty" xml:"version,omitempty"`
 }
 
 // NICConfiguration ...
 type NICConfiguration struct {
-	BootProtocol string `json:"boot_protocol,omitempty"`
-	IP           *IP    `json:"ip,omitempty"`
-	Name         string `json:"name,omitempty"`
-	OnBoot       string `json:"on_boot,omitempty"`
+	BootProtocol string `json:"boot_protocol,omitempty" xml:"boot_protocol,omitempty"`
+	IP           *IP    `json:"ip,omitempty" xml:"ip,omitempty"`
+	Name         string `json:"name,omitempty" xml:"name,omitempty"`
+	OnBoot       string `json:"on_boot,omitempty" xml:"on_boot,omitempty"`
 }
 
 type NICConfigurations struct {
-	NICConfiguration []NICConfiguration `json:"nic_configuration,omitempty"`
+	NICConfiguration []NICConfiguration `json:"nic_configuration,omitempty" xml:"nic_configuration,omitempty"`
 }
 
 // Initialization ...
 type Initialization struct {
-	ActiveDirectoryOU string `json:"active_directory_ou,omitempty"`
-	AuthorizedSSHKeys string `json:"authorized_ssh_keys,omitempty"`
+	ActiveDirectoryOU string `json:"active_directory_ou,omitempty" xml:"active_directory_ou,omitempty"`
+	AuthorizedSSHKeys string `json:"authorized_ssh_keys,omitempty" xml:"authorized_ssh_keys,omitempty"`
 	//TODO Finish structures from here
-	CloudInit         *CloudInit         `json:"cloud_init,omitempty"`
-	Configuration     *Configuration     `json:"configuration,omitempty"`
-	CustomScript      string             `json:"custom_script,omitempty"`
-	DNSSearch         string             `json:"dns_search,omitempty"`
-	DNSServers        string             `json:"dns_servers,omitempty"`
-	Domain            string             `json:"domain,omitempty"`
-	HostName          string             `json:"HostName,omitempty"`
-	InputLocale       string             `json:"input_locale,omitempty"`
-	NICConfigurations *NICConfigurations `json:"nic_configurations,omitempty"`
-	OrgName           string             `json:"org_name,omitempty"`
-	RegenerateIDs     string             `json:"regenerate_ids,omitempty"`
-	RegenerateSSHKeys string             `json:"regenerate_ssh_keys,omitempty"`
-	RootPassword      string             `json:"root_password,omitempty"`
-	SystemLocale      string             `json:"system_locale,omitempty"`
-	Timezone          string             `json:"timezone,omitempty"`
-	UILanguage        string             `json:"ui_language,omitempty"`
-	UserLocale        string             `json:"user_locale,omitempty"`
-	UserName          string             `json:"user_name,omitempty"`
-	WindowsLicenseKey string             `json:"windows_license_key,omitempty"`
+	CloudInit         *CloudInit         `json:"cloud_init,omitempty" xml:"cloud_init,omitempty"`
+	Configuration     *Configuration     `json:"configuration,omitempty" xml:"configuration,omitempty"`
+	CustomScript      string             `json:"custom_script,omitempty" xml:"custom_script,omitempty"`
+	DNSSearch         string             `json:"dns_search,omitempty" xml:"dns_search,omitempty"`
+	DNSServers        string             `json:"dns_servers,omitempty" xml:"dns_servers,omitempty"`
+	Domain            string             `json:"domain,omitempty" xml:"domain,omitempty"`
+	HostName          string             `json:"HostName,omitempty" xml:"HostName,omitempty"`
+	InputLocale       string             `json:"input_locale,omitempty" xml:"input_locale,omitempty"`
+	NICConfigurations *NICConfigurations `json:"nic_configurations,omitempty" xml:"nic_configurations,omitempty"`
+	OrgName           string             `json:"org_name,omitempty" xml:"org_name,omitempty"`
+	RegenerateIDs     string             `json:"regenerate_ids,omitempty" xml:"regenerate_ids,omitempty"`
+	RegenerateSSHKeys string             `json:"regenerate_ssh_keys,omitempty" xml:"regenerate_ssh_keys,omitempty"`
+	RootPassword      string             `json:"root_password,omitempty" xml:"root_password,omitempty"`
+	SystemLocale      string             `json:"system_locale,omitempty" xml:"system_locale,omitempty"`
+	Timezone          string             `json:"timezone,omitempty" xml:"timezone,omitempty"`
+	UILanguage        string             `json:"ui_language,omitempty" xml:"ui_language,omitempty"`
+	UserLocale        string             `json:"user_locale,omitempty" xml:"user_locale,omitempty"`
+	UserName          string             `json:"user_name,omitempty" xml:"user_name,omitempty"`
+	WindowsLicenseKey string             `json:"windows_license_key,omitempty" xml:"windows_license_key,omitempty"`
 }
 
 // IO ...
 type IO struct {
-	Threads int `json:"threads,omitempty,string"`
+	Threads int `json:"threads,omitempty,string" xml:"threads,omitempty"`
 }
 
 // MemoryOverCommit ...
 type MemoryOverCommit struct {
-	Percent int `json:"percent,omitempty,string"`
+	Percent int `json:"percent,omitempty,string" xml:"percent,omitempty"`
 }
 
 // MemoryPolicy Logical grouping of memory related properties of virtual machine-like entities.
 type MemoryPolicy struct {
-	Ballooning           string                `json:"ballooning,omitempty"`
-	Guaranteed           int                   `json:"guaranteed,string,omitempty"`
-	Max                  int                   `json:"max,string,omitempty"`
-	OverCommit           *MemoryOverCommit     `json:"over_commit,omitempty"`
-	TransparentHugePages *TransparentHugePages `json:"transparent_huge_pages,omitempty"`
+	Ballooning           string                `json:"ballooning,omitempty" xml:"ballooning,omitempty"`
+	Guaranteed           int                   `json:"guaranteed,string,omitempty" xml:"guaranteed,omitempty"`
+	Max                  int                   `json:"max,string,omitempty" xml:"max,omitempty"`
+	OverCommit           *MemoryOverCommit     `json:"over_commit,omitempty" xml:"over_commit,omitempty"`
+	TransparentHugePages *TransparentHugePages `json:"transparent_huge_pages,omitempty" xml:"transparent_huge_pages,omitempty"`
 }
 
 // MigrationBandwidth Defines the bandwidth used by migration.
 type MigrationBandwidth struct {
 	// The method used to assign the bandwidth.
-	AssignmentMethod string `json:"assignment_method,omitempty"`
+	AssignmentMethod string `json:"assignment_method,omitempty" xml:"assignment_method,omitempty"`
 	// Custom bandwidth in Mbps. Will be applied only if the assignmentMethod attribute is custom.
-	CustomValue int `json:"custom_value,omitempty,string"`
+	CustomValue int `json:"custom_value,omitempty,string" xml:"custom_value,omitempty"`
 }
 
 // MigrationOptions The type for migration options.
 type MigrationOptions struct {
-	AutoConverge string `json:"auto_converge,omitempty"`
+	AutoConverge string `json:"auto_converge,omitempty" xml:"auto_converge,omitempty"`
 	// The bandwidth that is allowed to be used by the migration.
-	Bandwidth  *MigrationBandwidth `json:"bandwidth,omitempty"`
-	Compressed string              `json:"compressed,omitempty"`
+	Bandwidth  *MigrationBandwidth `json:"bandwidth,omitempty" xml:"bandwidth,omitempty"`
+	Compressed string              `json:"compressed,omitempty" xml:"compressed,omitempty"`
 }
 
 // OperatingSystem Information describing the operating system. This is used for both virtual machines and hosts.
 type OperatingSystem struct {
-	Boot                  *Boot    `json:"boot,omitempty"`
-	Cmdline               string   `json:"cmdline,omitempty"`
-	CustomKernelCmdline   string   `json:"custom_kernel_cmdline,omitempty"`
-	Initrd                string   `json:"initrd,omitempty"`
-	Kernel                string   `json:"kernel,omitempty"`
-	ReportedKernelCmdline string   `json:"reported_kernel_cmdline,omitempty"`
-	Type                  string   `json:"type,omitempty"`
-	Version               *Version `json:"version,omitempty"`
+	Boot                  *Boot    `json:"boot,omitempty" xml:"boot,omitempty"`
+	Cmdline               string   `json:"cmdline,omitempty" xml:"cmdline,omitempty"`
+	CustomKernelCmdline   string   `json:"custom_kernel_cmdline,omitempty" xml:"custom_kernel_cmdline,omitempty"`
+	Initrd                string   `json:"initrd,omitempty" xml:"initrd,omitempty"`
+	Kernel                string   `json:"kernel,omitempty" xml:"kernel,omitempty"`
+	ReportedKernelCmdline string   `json:"reported_kernel_cmdline,omitempty" xml:"reported_kernel_cmdline,omitempty"`
+	Type                  string   `json:"type,omitempty" xml:"type,omitempty"`
+	Version               *Version `json:"version,omitempty" xml:"version,omitempty"`
 }
 
 // Kernel ...
 type Kernel struct {
-	Version *Version `json:"version,omitempty"`
+	Version *Version `json:"version,omitempty" xml:"version,omitempty"`
 }
 
 // Boot Configuration of the boot sequence of a virtual machine.
 type Boot struct {
-	Devices []string `json:"devices>device,omitempty"`
+	Devices []string `json:"devices>device,omitempty" xml:"devices>device,omitempty"`
 }
 
 // Version ...
 type Version struct {
-	Build       int    `json:"build,omitempty,string"`
-	Comment     string `json:"comment,omitempty"`
-	Description string `json:"description,omitempty"`
-	FullVersion string `json:"full_version,omitempty"`
-	ID          string `json:"id,omitempty"`
-	Major       int    `json:"major,omitempty,string"`
-	Minor       int    `json:"minor,omitempty,string"`
-	Name        string `json:"name,omitempty"`
-	Revision    int    `json:"revision,omitempty,string"`
+	Build       int    `json:"build,omitempty,string" xml:"build,omitempty"`
+	Comment     string `json:"comment,omitempty" xml:"comment,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
+	FullVersion string `json:"full_version,omitempty" xml:"full_version,omitempty"`
+	ID          string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Major       int    `json:"major,omitempty,string" xml:"major,omitempty"`
+	Minor       int    `json:"minor,omitempty,string" xml:"minor,omitempty"`
+	Name        string `json:"name,omitempty" xml:"name,omitempty"`
+	Revision    int    `json:"revision,omitempty,string" xml:"revision,omitempty"`
+}
+
+// StorageDomainLease The lease a highly available virtual machine holds on a storage domain.
+type StorageDomainLease struct {
+	StorageDomain *Link `json:"storage_domain,omitempty" xml:"storage_domain,omitempty"`
 }
 
 // TimeZone Time zone representation.
 type TimeZone struct {
-	Name      string `json:"name,omitempty"`
-	UTCOffset string `json:"utc_offset,omitempty"`
+	Name      string `json:"name,omitempty" xml:"name,omitempty"`
+	UTCOffset string `json:"utc_offset,omitempty" xml:"utc_offset,omitempty"`
 }
 
 // USB Configuration of the USB device of a virtual machine.
 type USB struct {
-	Enabled string `json:"enabled,omitempty"`
-	Type    string `json:"type,omitempty"`
+	Enabled string `json:"enabled,omitempty" xml:"enabled,omitempty"`
+	Type    string `json:"type,omitempty" xml:"type,omitempty"`
 }
 
 // VMPlacementPolicy ...
 type VMPlacementPolicy struct {
-	Affinity string `json:"affinity,omitempty"`
-	Hosts    []Host `json:"hosts,omitempty"`
+	Affinity string `json:"affinity,omitempty" xml:"affinity,omitempty"`
+	Hosts    []Host `json:"hosts>host,omitempty" xml:"hosts>host,omitempty"`
 }
 
 // VM Represents a virtual machine.
 type VM struct {
 	OvirtObject
-	Comment                    string                `json:"comment,omitempty"`
-	Console                    *Console              `json:"console,omitempty"`
-	Bios                       *Bios                 `json:"bios,omitempty"`
-	CPU                        *CPU                  `json:"cpu,omitempty"`
-	CPUShares                  int                   `json:"cpu_shares,omitempty,string"`
-	CreationTime               int                   `json:"creation_time,omitempty"`
-	CustomCompatibilityVersion *Version              `json:"custom_compatibility_version,omitempty"`
-	CustomCPUModel             string                `json:"custom_cpu_model,omitempty"`
-	CustomEmulatedMachine      string                `json:"custom_emulated_machine,omitempty"`
-	CustomProperties           []CustomProperty      `json:"custom_properties,omitempty"`
-	DeleteProtected            string                `json:"delete_protected,omitempty"`
-	Display                    *Display              `json:"display,omitempty"`
-	FQDN                       string                `json:"fqdn,omitempty"`
-	GuestOperatingSystem       *GuestOperatingSystem `json:"guest_operating_system,omitempty"`
-	HighAvailability           *HighAvailability     `json:"high_availability,omitempty"`
-	Initialization             *Initialization       `json:"initialization,omitempty"`
-	Io                         *IO                   `json:"io,omitempty"`
-	LargeIcon                  *Link                 `json:"large_icon,omitempty"`
-	Memory                     int                   `json:"memory,string,omitempty"`
-	MemoryPolicy               *MemoryPolicy         `json:"memory_policy,omitempty"`
-	Migration                  *MigrationOptions     `json:"migration,omitempty"`
-	MigrationDowntime          int                   `json:"migration_downtime,omitempty,string"`
-	Origin                     string                `json:"origin,omitempty"`
-	Os                         *OperatingSystem      `json:"os,omitempty"`
-	SmallIcon                  *Link                 `json:"small_icon,omitempty"`
-	StartPaused                string                `json:"start_paused,omitempty"`
-	Stateless                  string                `json:"stateless,omitempty"`
-	TimeZone                   *TimeZone             `json:"time_zone,omitempty"`
-	Type                       string                `json:"type,omitempty"`
-	USB                        *USB                  `json:"usb,omitempty"`
-	Cluster                    *Cluster              `json:"cluster,omitempty"`
-	CPUProfile                 *Link                 `json:"cpu_profile,omitempty"`
-	Quota                      *Link                 `json:"quota,omitempty"`
-	NextRunConfigurationExists string                `json:"next_run_configuration_exists,omitempty"`
-	NumaTuneMode               string                `json:"numa_tune_mode,omitempty"`
-	PlacementPolicy            *VMPlacementPolicy    `json:"placement_policy,omitempty"`
-	Runonce                    string                `json:"run_once,omitempty"`
-	Starttime                  int                   `json:"start_time,omitempty"`
-	StopTime                   int                   `json:"stop_time,omitempty"`
-	Status                     string                `json:"status,omitempty"`
-	Host                       *Link                 `json:"host,omitempty"`
-	InstanceType               *Link                 `json:"instance_type,omitempty"`
-	OriginalTemplate           *Link                 `json:"original_template,omitempty"`
-	Template                   *Template             `json:"template,omitempty"`
+	Comment                    string                `json:"comment,omitempty" xml:"comment,omitempty"`
+	Console                    *Console              `json:"console,omitempty" xml:"console,omitempty"`
+	Bios                       *Bios                 `json:"bios,omitempty" xml:"bios,omitempty"`
+	CPU                        *CPU                  `json:"cpu,omitempty" xml:"cpu,omitempty"`
+	CPUShares                  int                   `json:"cpu_shares,omitempty,string" xml:"cpu_shares,omitempty"`
+	CreationTime               Timestamp             `json:"creation_time,omitempty" xml:"creation_time,omitempty"`
+	CustomCompatibilityVersion *Version              `json:"custom_compatibility_version,omitempty" xml:"custom_compatibility_version,omitempty"`
+	CustomCPUModel             string                `json:"custom_cpu_model,omitempty" xml:"custom_cpu_model,omitempty"`
+	CustomEmulatedMachine      string                `json:"custom_emulated_machine,omitempty" xml:"custom_emulated_machine,omitempty"`
+	CustomProperties           []CustomProperty      `json:"custom_properties>custom_property,omitempty" xml:"custom_properties>custom_property,omitempty"`
+	DeleteProtected            string                `json:"delete_protected,omitempty" xml:"delete_protected,omitempty"`
+	Display                    *Display              `json:"display,omitempty" xml:"display,omitempty"`
+	FQDN                       string                `json:"fqdn,omitempty" xml:"fqdn,omitempty"`
+	GuestOperatingSystem       *GuestOperatingSystem `json:"guest_operating_system,omitempty" xml:"guest_operating_system,omitempty"`
+	HighAvailability           *HighAvailability     `json:"high_availability,omitempty" xml:"high_availability,omitempty"`
+	Initialization             *Initialization       `json:"initialization,omitempty" xml:"initialization,omitempty"`
+	Io                         *IO                   `json:"io,omitempty" xml:"io,omitempty"`
+	LargeIcon                  *Link                 `json:"large_icon,omitempty" xml:"large_icon,omitempty"`
+	Lease                      *StorageDomainLease   `json:"lease,omitempty" xml:"lease,omitempty"`
+	Memory                     int                   `json:"memory,string,omitempty" xml:"memory,omitempty"`
+	MemoryPolicy               *MemoryPolicy         `json:"memory_policy,omitempty" xml:"memory_policy,omitempty"`
+	Migration                  *MigrationOptions     `json:"migration,omitempty" xml:"migration,omitempty"`
+	MigrationDowntime          int                   `json:"migration_downtime,omitempty,string" xml:"migration_downtime,omitempty"`
+	Origin                     string                `json:"origin,omitempty" xml:"origin,omitempty"`
+	Os                         *OperatingSystem      `json:"os,omitempty" xml:"os,omitempty"`
+	SmallIcon                  *Link                 `json:"small_icon,omitempty" xml:"small_icon,omitempty"`
+	StartPaused                string                `json:"start_paused,omitempty" xml:"start_paused,omitempty"`
+	Stateless                  string                `json:"stateless,omitempty" xml:"stateless,omitempty"`
+	TimeZone                   *TimeZone             `json:"time_zone,omitempty" xml:"time_zone,omitempty"`
+	Type                       string                `json:"type,omitempty" xml:"type,omitempty"`
+	USB                        *USB                  `json:"usb,omitempty" xml:"usb,omitempty"`
+	Cluster                    *Cluster              `json:"cluster,omitempty" xml:"cluster,omitempty"`
+	CPUProfile                 *CPUProfile           `json:"cpu_profile,omitempty" xml:"cpu_profile,omitempty"`
+	Quota                      *Link                 `json:"quota,omitempty" xml:"quota,omitempty"`
+	NextRunConfigurationExists string                `json:"next_run_configuration_exists,omitempty" xml:"next_run_configuration_exists,omitempty"`
+	NumaTuneMode               string                `json:"numa_tune_mode,omitempty" xml:"numa_tune_mode,omitempty"`
+	PlacementPolicy            *VMPlacementPolicy    `json:"placement_policy,omitempty" xml:"placement_policy,omitempty"`
+	Runonce                    string                `json:"run_once,omitempty" xml:"run_once,omitempty"`
+	Starttime                  Timestamp             `json:"start_time,omitempty" xml:"start_time,omitempty"`
+	StopTime                   Timestamp             `json:"stop_time,omitempty" xml:"stop_time,omitempty"`
+	Status                     string                `json:"status,omitempty" xml:"status,omitempty"`
+	Host                       *Link                 `json:"host,omitempty" xml:"host,omitempty"`
+	InstanceType               *Link                 `json:"instance_type,omitempty" xml:"instance_type,omitempty"`
+	OriginalTemplate           *Link                 `json:"original_template,omitempty" xml:"original_template,omitempty"`
+	Template                   *Template             `json:"template,omitempty" xml:"template,omitempty"`
+	VMPool                     *VMPool               `json:"vm_pool,omitempty" xml:"vm_pool,omitempty"`
 }
 
 // CancelMigration This operation stops any migration of a virtual machine to another physical host.
@@ -573,7 +581,7 @@ func (con *Connection) GetVM(id string) (*VM, error) {
 		return nil, err
 	}
 	object := con.NewVM()
-	err = json.Unmarshal(body, object)
+	err = con.decode(body, object)
 	if err != nil {
 		return nil, err
 	}
@@ -593,6 +601,36 @@ func (vm *VM) Update() error {
 	return nil
 }
 
+// WaitForStatus Poll the server every interval until the VM reaches the status, fails after timeout
+func (vm *VM) WaitForStatus(status string, interval time.Duration, timeout time.Duration) (err error) {
+	con, span := vm.Con.startSpan("VM.WaitForStatus", append(vm.Con.objectAttributes(vm.Href, ""), Attribute{"ovirt.status", status})...)
+	defer func() { span.End(err) }()
+	if vm.Href == "" {
+		return fmt.Errorf("VM has not been saved to the server")
+	}
+	deadline := time.Now().Add(timeout)
+	for polls := 1; ; polls++ {
+		newVM, err := con.GetVM(vm.ID)
+		if err != nil {
+			return err
+		}
+		newVM.Con = vm.Con
+		*vm = *newVM
+		span.SetAttributes(Attribute{"ovirt.polls", strconv.Itoa(polls)})
+		if vm.Status == status {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("VM %s is %s after %s, expected %s", vm.Name, vm.Status, timeout, status)
+		}
+		select {
+		case <-time.After(interval):
+		case <-con.Context().Done():
+			return con.Context().Err()
+		}
+	}
+}
+
 // GetAllVMs Retrieve all the VMs from the server
 func (con *Connection) GetAllVMs() ([]*VM, error) {
 	body, err := con.GetLinkBody("vms", "")
@@ -600,9 +638,24 @@ func (con *Connection) GetAllVMs() ([]*VM, error) {
 		return nil, err
 	}
 	objects := []*VM{}
-	err = json.Unmarshal(body, &struct {
-		VM *[]*VM
-	}{&objects})
+	err = con.decodeList(body, &objects)
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		object.Con = con
+	}
+	return objects, err
+}
+
+// SearchVMs Retrieve the VMs matching the search query
+func (con *Connection) SearchVMs(query string) ([]*VM, error) {
+	body, err := con.SearchLinkBody("vms", query)
+	if err != nil {
+		return nil, err
+	}
+	objects := []*VM{}
+	err = con.decodeList(body, &objects)
 	if err != nil {
 		return nil, err
 	}
@@ -617,33 +670,53 @@ func (con *Connection) NewVM() *VM {
 	return &VM{OvirtObject: OvirtObject{Con: con}}
 }
 
-// Save Updates the server with the local copy of the VM
-func (object *VM) Save() error {
-	body, err := json.MarshalIndent(object, "", "    ")
+// Save Updates the server with the local copy of the VM, sending only the fields
+// modified since it was loaded. With NextRun, the changes to a running VM are
+// applied on its next boot.
+func (object *VM) Save(options ...SaveOption) (err error) {
+	attributes := object.Con.objectAttributes(object.Href, "")
+	if object.Href == "" {
+		attributes = []Attribute{{"ovirt.resource", "vms"}}
+	}
+	con, span := object.Con.startSpan("VM.Save", attributes...)
+	defer func() { span.End(err) }()
+	if object.Lease != nil {
+		if err = con.Require(VMLeases); err != nil {
+			return err
+		}
+	}
+	body, err := object.Con.encodeChanges(object)
 	if err != nil {
 		return err
 	}
 	// If there is a link, it is an already saved object, we need to update it
 	if object.OvirtObject.Href != "" {
-		body, err = object.Con.Request("PUT", object.Con.ResolveLink(object.Href), body)
+		body, err = con.put(object, object.Href, body, options)
 		if err != nil {
 			return err
 		}
 	} else {
-		link, err := object.Con.GetLink("vms")
+		link, err := con.GetLink("vms")
 		if err != nil {
 			return err
 		}
-		body, err = object.Con.Request("POST", link, body)
+		body, err = con.Request("POST", link, body)
 		if err != nil {
 			return err
 		}
 	}
 	tempObject := VM{OvirtObject: OvirtObject{Con: object.Con}}
-	err = json.Unmarshal(body, &tempObject)
+	err = object.Con.decode(body, &tempObject)
 	if err != nil {
 		return err
 	}
 	*object = tempObject
+	span.SetAttributes(Attribute{"ovirt.id", object.ID})
 	return nil
 }
+
+// Patch Updates the server with the fields of the VM named by their JSON
+// path, such as "name" or "cpu.topology.cores", whether they were modified or not
+func (object *VM) Patch(fields ...string) error {
+	return object.Con.patch(object, fields)
+}
@@ -0,0 +1,183 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The types of VM pools
+const (
+	VMPoolTypeAutomatic = "automatic"
+	VMPoolTypeManual    = "manual"
+)
+
+// VMPool Represents a virtual machines pool.
+type VMPool struct {
+	OvirtObject
+	// Indicates if the pool should automatically distribute the disks of the virtual machines across the multiple storage domains where the template is copied.
+	AutoStorageSelect string `json:"auto_storage_select,omitempty" xml:"auto_storage_select,omitempty"`
+	// Free text containing comments about this object.
+	Comment string `json:"comment,omitempty" xml:"comment,omitempty"`
+	// The display settings of the virtual machines of the pool.
+	Display *Display `json:"display,omitempty" xml:"display,omitempty"`
+	// The maximum number of virtual machines in the pool that could be assigned to a particular user.
+	MaxUserVMs int `json:"max_user_vms,omitempty,string" xml:"max_user_vms,omitempty"`
+	// The number of virtual machines in the pool that are started, with no user assigned, to be ready for allocation.
+	PrestartedVMs int `json:"prestarted_vms,omitempty,string" xml:"prestarted_vms,omitempty"`
+	// The number of virtual machines in the pool.
+	Size int `json:"size,omitempty,string" xml:"size,omitempty"`
+	// Indicates if sound card should be configured for each virtual machine in the pool.
+	SoundcardEnabled string `json:"soundcard_enabled,omitempty" xml:"soundcard_enabled,omitempty"`
+	// Virtual machine pool's stateful flag, stateful virtual machines keep their state when the user returns them to the pool.
+	Stateful string `json:"stateful,omitempty" xml:"stateful,omitempty"`
+	// The deallocation policy of virtual machines in the pool, automatic or manual.
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
+	// Indicates if the latest template version should be used to create the virtual machines.
+	UseLatestTemplateVersion string `json:"use_latest_template_version,omitempty" xml:"use_latest_template_version,omitempty"`
+	// Reference to the cluster the pool resides in.
+	Cluster *Cluster `json:"cluster,omitempty" xml:"cluster,omitempty"`
+	// Reference to the instance type on which this pool is based.
+	InstanceType *Link `json:"instance_type,omitempty" xml:"instance_type,omitempty"`
+	// Reference to the template the pool is based on.
+	Template *Template `json:"template,omitempty" xml:"template,omitempty"`
+	// Reference to an arbitrary virtual machine that is part of the pool.
+	VM *VM `json:"vm,omitempty" xml:"vm,omitempty"`
+}
+
+// AllocateVM Allocates a virtual machine of the pool to the user of the connection and returns it
+func (pool *VMPool) AllocateVM(async string) (*VM, error) {
+	if pool.Actions == nil {
+		return nil, errors.New("Action not found")
+	}
+	for _, link := range pool.Actions.Links {
+		if link.Rel == "allocatevm" {
+			body, err := pool.Con.encode(Action{
+				Async: async,
+			})
+			if err != nil {
+				return nil, err
+			}
+			body, err = pool.Con.Request("POST", pool.Con.ResolveLink(link.Href), body)
+			if err != nil {
+				return nil, err
+			}
+			action := Action{}
+			err = pool.Con.decode(body, &action)
+			if err != nil {
+				return nil, err
+			}
+			if action.VM == nil {
+				return nil, errors.New("Server did not return the allocated VM")
+			}
+			action.VM.Con = pool.Con
+			return action.VM, nil
+		}
+	}
+	return nil, errors.New("Action not found")
+}
+
+// GetVMs Retrieve the virtual machines that are members of the pool
+func (pool *VMPool) GetVMs() ([]*VM, error) {
+	if pool.Name == "" {
+		return nil, errors.New("VM pool does not have a name")
+	}
+	return pool.Con.SearchVMs("pool=" + SearchValue(pool.Name))
+}
+
+// GetVMPool retrieve a VM pool from the server
+func (con *Connection) GetVMPool(id string) (*VMPool, error) {
+	body, err := con.GetLinkBody("vmpools", id)
+	if err != nil {
+		return nil, err
+	}
+	pool := con.NewVMPool()
+	err = con.decode(body, pool)
+	if err != nil {
+		return nil, err
+	}
+	return pool, err
+}
+
+// Update Synchronize the local VM pool with a copy from the server
+func (pool *VMPool) Update() error {
+	if pool.Href == "" {
+		return fmt.Errorf("VM pool has not been saved to the server")
+	}
+	body, err := pool.Con.Request("GET", pool.Con.ResolveLink(pool.Href), nil)
+	if err != nil {
+		return err
+	}
+	tempPool := VMPool{OvirtObject: OvirtObject{Con: pool.Con}}
+	err = pool.Con.decode(body, &tempPool)
+	if err != nil {
+		return err
+	}
+	*pool = tempPool
+	return nil
+}
+
+// GetAllVMPools Retrieve all the VM pools from the server
+func (con *Connection) GetAllVMPools() ([]*VMPool, error) {
+	body, err := con.GetLinkBody("vmpools", "")
+	if err != nil {
+		return nil, err
+	}
+	pools := []*VMPool{}
+	err = con.decodeList(body, &pools)
+	if err != nil {
+		return nil, err
+	}
+	for _, pool := range pools {
+		pool.Con = con
+	}
+	return pools, err
+}
+
+// NewVMPool Create a new VM pool structure
+func (con *Connection) NewVMPool() *VMPool {
+	return &VMPool{OvirtObject: OvirtObject{Con: con}}
+}
+
+// Save Updates the server with the local copy of the VM pool, sending only the fields
+// modified since it was loaded
+func (pool *VMPool) Save(options ...SaveOption) error {
+	body, err := pool.Con.encodeChanges(pool)
+	if err != nil {
+		return err
+	}
+	// If there is a link, it is an already saved VM pool, we need to update it
+	if pool.Href != "" {
+		body, err = pool.Con.put(pool, pool.Href, body, options)
+		if err != nil {
+			return err
+		}
+	} else {
+		link, err := pool.Con.GetLink("vmpools")
+		if err != nil {
+			return err
+		}
+		body, err = pool.Con.Request("POST", link, body)
+		if err != nil {
+			return err
+		}
+	}
+	tempPool := VMPool{OvirtObject: OvirtObject{Con: pool.Con}}
+	err = pool.Con.decode(body, &tempPool)
+	if err != nil {
+		return err
+	}
+	*pool = tempPool
+	return nil
+}
+
+// Patch Updates the server with the fields of the VM pool named by their JSON
+// path, such as "size" or "prestarted_vms", whether they were modified or not
+func (pool *VMPool) Patch(fields ...string) error {
+	return pool.Con.patch(pool, fields)
+}
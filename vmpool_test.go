@@ -0,0 +1,57 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"testing"
+
+	"github.com/EMSL-MSC/ovirtapi"
+)
+
+func TestVMPool(t *testing.T) {
+	t.Parallel()
+	con := newTestConnection(t)
+	allTemplates, err := con.GetAllTemplates()
+	if err != nil || len(allTemplates) == 0 {
+		t.Fatal("Error finding a Template for the pool", err)
+	}
+	allClusters, err := con.GetAllClusters()
+	if err != nil || len(allClusters) == 0 {
+		t.Fatal("Error finding a Cluster for the pool", err)
+	}
+	newPool := con.NewVMPool()
+	newPool.Name = "test-vm-pool"
+	newPool.Size = 1
+	newPool.MaxUserVMs = 1
+	newPool.Type = ovirtapi.VMPoolTypeAutomatic
+	newPool.Template = allTemplates[0]
+	newPool.Cluster = allClusters[0]
+	err = newPool.Save()
+	if err != nil {
+		t.Fatal("Error creating new vm pool", err)
+	}
+	members, err := newPool.GetVMs()
+	if err != nil {
+		t.Fatal("Error retrieving vm pool members", err)
+	}
+	if len(members) != 1 {
+		t.Error("VM pool does not have the expected number of members", len(members))
+	}
+	retrievedPool, err := con.GetVMPool(newPool.ID)
+	if err != nil {
+		t.Fatal("Error retrieving vm pool", err)
+	}
+	retrievedPool.Description = "about to delete"
+	err = retrievedPool.Save()
+	if err != nil {
+		t.Fatal("Error updating vm pool", err)
+	}
+	err = retrievedPool.Delete()
+	if err != nil {
+		t.Fatal("Error Deleting vm pool", err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"time"
+)
+
+// ProxyTicket A ticket signed by the engine allowing its websocket proxy to connect to a console.
+type ProxyTicket struct {
+	// The signed ticket.
+	Value string `json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// ProxyTicket Issues a signed ticket the websocket proxy of the engine accepts
+// to connect browser clients to the console
+func (console *GraphicsConsole) ProxyTicket() (*ProxyTicket, error) {
+	action, err := console.doActionResponse("proxyticket", Action{})
+	if err != nil {
+		return nil, err
+	}
+	if action.ProxyTicket == nil || action.ProxyTicket.Value == "" {
+		return nil, errors.New("Server did not return the proxy ticket")
+	}
+	return action.ProxyTicket, nil
+}
+
+// WebsocketConsole The connection data a browser client, such as noVNC,
+// needs to open a console through the websocket proxy of the engine
+type WebsocketConsole struct {
+	// The protocol of the console, spice or vnc.
+	Protocol string
+	// The address of the websocket proxy, host:port.
+	Proxy string
+	// The signed proxy ticket, the path of the websocket the client opens.
+	ProxyTicket string
+	// The graphics ticket, the password the client authenticates to the console with.
+	Password string
+	// The time the graphics ticket is valid for.
+	Expiry time.Duration
+	// Whether the client connects to the proxy with TLS.
+	Encrypt bool
+}
+
+// WebsocketConsole Issues the graphics ticket, valid for expiry, and the proxy
+// ticket opening the console of the VM using the protocol through the
+// websocket proxy of the engine at proxy, host:port. The engine does not
+// publish the address of its proxy, by default the port 6100 of the engine.
+func (vm *VM) WebsocketConsole(protocol string, proxy string, expiry time.Duration) (*WebsocketConsole, error) {
+	console, err := vm.GetGraphicsConsole(protocol)
+	if err != nil {
+		return nil, err
+	}
+	ticket, err := console.Ticket(expiry)
+	if err != nil {
+		return nil, err
+	}
+	proxyTicket, err := console.ProxyTicket()
+	if err != nil {
+		return nil, err
+	}
+	return &WebsocketConsole{
+		Protocol:    protocol,
+		Proxy:       proxy,
+		ProxyTicket: proxyTicket.Value,
+		Password:    ticket.Value,
+		Expiry:      time.Duration(ticket.Expiry) * time.Second,
+		Encrypt:     true,
+	}, nil
+}
+
+// URL The URL of the websocket the client opens
+func (console *WebsocketConsole) URL() string {
+	websocket := url.URL{
+		Scheme:  "ws",
+		Host:    console.Proxy,
+		Path:    "/" + console.ProxyTicket,
+		RawPath: "/" + url.PathEscape(console.ProxyTicket),
+	}
+	if console.Encrypt {
+		websocket.Scheme = "wss"
+	}
+	return websocket.String()
+}
+
+// NoVNCParameters The query parameters of the noVNC client page opening the
+// console: host, port, path, password and encrypt
+func (console *WebsocketConsole) NoVNCParameters() (url.Values, error) {
+	host, port, err := net.SplitHostPort(console.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	encrypt := "0"
+	if console.Encrypt {
+		encrypt = "1"
+	}
+	return url.Values{
+		"host":     {host},
+		"port":     {port},
+		"path":     {url.PathEscape(console.ProxyTicket)},
+		"password": {console.Password},
+		"encrypt":  {encrypt},
+	}, nil
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+package ovirtapi_test
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EMSL-MSC/ovirtapi"
+	"github.com/EMSL-MSC/ovirtapi/ovirtapitest"
+)
+
+// openWebsocket Sends the websocket handshake to the URL and returns the response
+func openWebsocket(t *testing.T, websocket string) *http.Response {
+	req, err := http.NewRequest("GET", "http"+strings.TrimPrefix(websocket, "ws"), nil)
+	if err != nil {
+		t.Fatal("Error creating the handshake", err)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal("Error sending the handshake", err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestWebsocketConsole(t *testing.T) {
+	t.Parallel()
+	engine := ovirtapitest.NewEngine()
+	defer engine.Close()
+	proxy := ovirtapitest.NewWebsocketProxy(engine)
+	defer proxy.Close()
+	con, err := ovirtapi.NewConnection(engine.URL(), engine.Username, engine.Password, false, ovirtapi.WithFilter(false))
+	if err != nil {
+		t.Fatal("error creating connection", err)
+	}
+	id := engine.Add("vms", map[string]interface{}{"name": "novnc", "display": map[string]interface{}{"type": "vnc"}})
+	vm, err := con.GetVM(id)
+	if err != nil {
+		t.Fatal("Error getting the VM", err)
+	}
+	if _, err = vm.WebsocketConsole("spice", proxy.Address(), time.Minute); err == nil {
+		t.Error("Opened a SPICE console on a VNC VM")
+	}
+	console, err := vm.WebsocketConsole("vnc", proxy.Address(), time.Minute)
+	if err != nil {
+		t.Fatal("Error getting the websocket console", err)
+	}
+	if !engine.ValidTicket(console.Password) || console.Expiry != time.Minute {
+		t.Error("Did not issue a graphics ticket", console.Password, console.Expiry)
+	}
+
+	parameters, err := console.NoVNCParameters()
+	if err != nil {
+		t.Fatal("Error composing the noVNC parameters", err)
+	}
+	host, port, _ := net.SplitHostPort(proxy.Address())
+	path, _ := url.PathUnescape(parameters.Get("path"))
+	if parameters.Get("host") != host || parameters.Get("port") != port || parameters.Get("encrypt") != "1" ||
+		parameters.Get("password") != console.Password || path != console.ProxyTicket {
+		t.Error("Unexpected noVNC parameters", parameters)
+	}
+	if !strings.HasPrefix(console.URL(), "wss://"+proxy.Address()+"/") {
+		t.Error("Unexpected websocket URL", console.URL())
+	}
+
+	console.Encrypt = false
+	resp := openWebsocket(t, console.URL())
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Error("Proxy did not accept the websocket", resp.Status, resp.Header)
+	}
+	if targets := proxy.Targets(); !reflect.DeepEqual(targets, []string{"host1.example.com:5900"}) {
+		t.Error("Proxy did not connect to the console", targets)
+	}
+
+	console.ProxyTicket = strings.Replace(console.ProxyTicket, "a", "b", 1)
+	if resp = openWebsocket(t, console.URL()); resp.StatusCode != http.StatusForbidden {
+		t.Error("Proxy accepted a tampered ticket", resp.Status)
+	}
+}
+
+func TestProxyTicketSecrets(t *testing.T) {
+	t.Parallel()
+	vm, output, cassette := newSecretsVM(t)
+	console, err := vm.GetGraphicsConsole("spice")
+	if err != nil {
+		t.Fatal("Error getting the SPICE console", err)
+	}
+	ticket, err := console.ProxyTicket()
+	if err != nil {
+		t.Fatal("Error issuing a proxy ticket", err)
+	}
+	checkSecrets(t, output, cassette, ticket.Value)
+}
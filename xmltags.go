@@ -0,0 +1,120 @@
+// Copyright (C) 2017 Battelle Memorial Institute
+// All rights reserved.
+//
+// This software may be modified and distributed under the terms
+// of the BSD-2 license.  See the LICENSE file for details.
+
+//go:build ignore
+
+// xmltags Adds an xml tag alongside the json tag of every struct field of the
+// package, so the types decode the XML representation of the engine as well.
+// Fields already having an xml tag are left alone.
+//
+//	go run xmltags.go
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// attributes The json names sent as attributes of the element in XML
+var attributes = map[string]bool{"href": true, "id": true, "rel": true}
+
+// xmlTag The xml tag of a field with the json tag, "" when it needs none
+func xmlTag(tag reflect.StructTag) string {
+	jsonTag, ok := tag.Lookup("json")
+	if !ok {
+		return ""
+	}
+	if _, ok := tag.Lookup("xml"); ok {
+		return ""
+	}
+	if jsonTag == "-" {
+		return "-"
+	}
+	parts := strings.Split(jsonTag, ",")
+	name := parts[0]
+	xmlParts := []string{name}
+	if attributes[name] {
+		xmlParts = append(xmlParts, "attr")
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			xmlParts = append(xmlParts, option)
+		}
+	}
+	return strings.Join(xmlParts, ",")
+}
+
+func main() {
+	fileNames, err := filepath.Glob("*.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, fileName := range fileNames {
+		if strings.HasSuffix(fileName, "_test.go") || fileName == "xmltags.go" {
+			continue
+		}
+		if err := tagFile(fileName); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func tagFile(fileName string) error {
+	src, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, fileName, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	type edit struct {
+		offset int
+		text   string
+	}
+	edits := []edit{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		structType, ok := node.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		for _, field := range structType.Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			value, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				continue
+			}
+			tag := xmlTag(reflect.StructTag(value))
+			if tag == "" {
+				continue
+			}
+			// Insert before the closing quote of the tag literal
+			end := fileSet.Position(field.Tag.End()).Offset - 1
+			edits = append(edits, edit{end, fmt.Sprintf(` xml:"%s"`, tag)})
+		}
+		return true
+	})
+	if len(edits) == 0 {
+		return nil
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].offset > edits[j].offset })
+	for _, e := range edits {
+		src = append(src[:e.offset], append([]byte(e.text), src[e.offset:]...)...)
+	}
+	return ioutil.WriteFile(fileName, src, 0644)
+}